@@ -0,0 +1,33 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"goproject/internal/config"
+	"os"
+)
+
+// runConfig выполняет подкоманду config: print выводит действующую
+// конфигурацию после переменных окружения, со скрытыми секретами.
+// Конфигурация выводится и тогда, когда не прошла проверку: подкоманда
+// нужна как раз для поиска ошибок, а ошибки проверки возвращаются после
+// вывода.
+func runConfig(args []string) error {
+	if len(args) != 1 || args[0] != "print" {
+		return fmt.Errorf("usage: config print")
+	}
+
+	cfg, err := config.Load(os.Getenv("CONFIG_PATH"))
+	var invalid *config.ValidationError
+	if err != nil && !errors.As(err, &invalid) {
+		return err
+	}
+
+	if err := cfg.Print(os.Stdout); err != nil {
+		return err
+	}
+	if invalid != nil {
+		return invalid
+	}
+	return nil
+}
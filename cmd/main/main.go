@@ -1,28 +1,114 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"goproject/internal/config"
-	"goproject/internal/http_server/handlers/project"
+	httpserver "goproject/internal/http_server"
+	"goproject/internal/logger"
+	"goproject/internal/metrics"
+	"goproject/internal/storage"
+	"goproject/internal/storage/memory"
 	"goproject/internal/storage/postgres"
-	"log"
+	"log/slog"
 	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
 )
 
 func main() {
+	// config print загружает конфигурацию сам, без выхода на ошибке проверки
+	if len(os.Args) > 1 && os.Args[1] == "config" {
+		if err := runConfig(os.Args[2:]); err != nil {
+			fmt.Fprintf(os.Stderr, "config: %v\n", err)
+			os.Exit(1)
+		}
+		return
+	}
+
 	cfg, msg := config.MustLoad()
 
-	storage, err := postgres.New(cfg.StoragePath)
-	if err != nil {
-		log.Fatalf("failed to connect to database: %v", err)
+	log := logger.New(cfg.Env)
+	slog.SetDefault(log)
+
+	var repo storage.Repository
+	if cfg.StoragePath == config.MemoryStorage {
+		// Миграциям нужна база: без этой проверки migrate запустил бы сервер
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			log.Error("migrate requires a postgres storage, storage_path is memory")
+			os.Exit(1)
+		}
+		repo = memory.New()
+	} else {
+		pg, err := postgres.New(cfg.StoragePath)
+		if err != nil {
+			log.Error("failed to connect to database", logger.Err(err))
+			os.Exit(1)
+		}
+
+		if len(os.Args) > 1 && os.Args[1] == "migrate" {
+			err := runMigrate(pg, os.Args[2:])
+			pg.Close()
+			if err != nil {
+				log.Error("migrate failed", logger.Err(err))
+				os.Exit(1)
+			}
+			return
+		}
+
+		applied, err := pg.MigrateUp()
+		if err != nil {
+			log.Error("failed to apply migrations", logger.Err(err))
+			os.Exit(1)
+		}
+		for _, m := range applied {
+			log.Info("applied migration", slog.Int("version", m.Version), slog.String("name", m.Name))
+		}
+
+		metrics.RegisterDBStats(metrics.Default, pg.Stats)
+		repo = pg
 	}
-	defer storage.Close()
+	metrics.RegisterActivity(metrics.Default, repo)
+	defer repo.Close()
+
+	log.Info(msg, slog.String("env", cfg.Env))
 
-	log.Println(msg)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	saver := storage
+	srv := httpserver.NewServer(cfg.HTTPServer, httpserver.NewRouter(repo, cfg, log))
 
-	http.HandleFunc("/project", project.NewProjectHandler(saver))
+	errCh := make(chan error, 1)
+	go func() {
+		log.Info("listening", slog.String("address", srv.Addr))
+		errCh <- srv.ListenAndServe()
+	}()
 
-	http.ListenAndServe(cfg.HTTPServer.Address, nil)
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, http.ErrServerClosed) {
+			// Сервер не запустился или упал: os.Exit пропускает defer, поэтому
+			// хранилище закрывается явно, а код выхода сообщает о сбое
+			log.Error("http server failed", logger.Err(err))
+			repo.Close()
+			os.Exit(1)
+		}
+		return
+	case <-ctx.Done():
+	}
+	stop()
 
+	// Новые соединения больше не принимаются, текущие запросы дорабатывают
+	// до ShutdownTimeout; хранилище закрывается после них через defer
+	log.Info("shutting down")
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.HTTPServer.ShutdownTimeout)
+	defer cancel()
+
+	if err := srv.Shutdown(shutdownCtx); err != nil {
+		log.Error("graceful shutdown failed", logger.Err(err))
+		srv.Close()
+	}
+	log.Info("server stopped")
 }
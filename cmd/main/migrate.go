@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"goproject/internal/storage/postgres"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+)
+
+// runMigrate выполняет подкоманду migrate: up, down [N] или status
+func runMigrate(storage *postgres.Storage, args []string) error {
+	if len(args) == 0 {
+		return fmt.Errorf("usage: migrate up|down [N]|status")
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := storage.MigrateUp()
+		for _, m := range applied {
+			fmt.Printf("applied %04d_%s\n", m.Version, m.Name)
+		}
+		if err != nil {
+			return err
+		}
+		if len(applied) == 0 {
+			fmt.Println("schema is up to date")
+		}
+		return nil
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			n, err := strconv.Atoi(args[1])
+			if err != nil || n < 1 {
+				return fmt.Errorf("invalid number of steps %q", args[1])
+			}
+			steps = n
+		}
+		reverted, err := storage.MigrateDown(steps)
+		for _, m := range reverted {
+			fmt.Printf("reverted %04d_%s\n", m.Version, m.Name)
+		}
+		return err
+
+	case "status":
+		migrations, err := storage.MigrationStatus()
+		if err != nil {
+			return err
+		}
+		tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(tw, "VERSION\tNAME\tAPPLIED AT")
+		for _, m := range migrations {
+			appliedAt := "pending"
+			if m.AppliedAt != nil {
+				appliedAt = m.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Fprintf(tw, "%04d\t%s\t%s\n", m.Version, m.Name, appliedAt)
+		}
+		return tw.Flush()
+	}
+
+	return fmt.Errorf("unknown migrate command %q", args[0])
+}
@@ -0,0 +1,63 @@
+// Package auth выпускает API-токены разработчиков и хранит данные
+// аутентифицированного клиента в контексте запроса
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"goproject/internal/storage/postgres/entity"
+
+	"github.com/google/uuid"
+)
+
+// tokenPrefix отличает токены сервиса от прочих секретов, например в логах и сканерах
+const tokenPrefix = "tc_"
+
+// Principal - аутентифицированный клиент API: разработчик и его роль
+// (entity.Role*). Токен из конфигурации дает роль администратора без
+// привязки к разработчику.
+type Principal struct {
+	DeveloperID uuid.UUID
+	Role        string
+}
+
+type principalKey struct{}
+
+// NewToken генерирует токен и его хэш. Клиенту токен отдается один раз,
+// в хранилище записывается только хэш.
+func NewToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("generate token: %w", err)
+	}
+
+	token = tokenPrefix + base64.RawURLEncoding.EncodeToString(buf)
+	return token, HashToken(token), nil
+}
+
+// HashToken возвращает хэш токена для поиска в хранилище. Токены случайные
+// и длинные, поэтому соль и медленный хэш не нужны.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+// FromContext возвращает клиента, аутентифицированного middleware
+func FromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok
+}
+
+// Actor возвращает автора изменения для журнала аудита
+func Actor(ctx context.Context) entity.Actor {
+	p, _ := FromContext(ctx)
+	return entity.Actor{DeveloperID: p.DeveloperID, Role: p.Role}
+}
@@ -1,6 +1,7 @@
 package config
 
 import (
+	"fmt"
 	"log"
 	"os"
 	"time"
@@ -8,37 +9,95 @@ import (
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
+// Config - настройки приложения. Значения читаются из YAML-файла CONFIG_PATH,
+// затем переопределяются переменными окружения из тегов env.
 type Config struct {
-	Env         string `yaml:"env" env-default:"development"`
-	StoragePath string `yaml:"storage_path" env-required:"true"`
+	Env string `yaml:"env" env:"ENV" env-default:"development"`
+	// StoragePath - строка подключения к PostgreSQL или "memory". Если не
+	// задана, строка собирается из секции database.
+	StoragePath string `yaml:"storage_path" env:"STORAGE_PATH"`
+	Database    `yaml:"database"`
 	HTTPServer  `yaml:"http_server"`
+	ICalImport  `yaml:"ical_import"`
+	Auth        `yaml:"auth"`
 }
 
+// Database - параметры подключения к PostgreSQL, из которых собирается
+// строка подключения. Пароль можно передать файлом (например, Docker secret),
+// а DSNFile задает всю строку подключения целиком.
+type Database struct {
+	Host         string `yaml:"host" env:"DB_HOST"`
+	Port         int    `yaml:"port" env:"DB_PORT" env-default:"5432"`
+	User         string `yaml:"user" env:"DB_USER"`
+	Password     string `yaml:"password" env:"DB_PASSWORD"`
+	PasswordFile string `yaml:"password_file" env:"DB_PASSWORD_FILE"`
+	Name         string `yaml:"name" env:"DB_NAME"`
+	SSLMode      string `yaml:"ssl_mode" env:"DB_SSL_MODE" env-default:"disable"`
+	DSNFile      string `yaml:"dsn_file" env:"DB_DSN_FILE"`
+}
+
+// HTTPServer - настройки http-сервера. Timeout ограничивает чтение запроса
+// и запись ответа, ShutdownTimeout - ожидание текущих запросов при остановке.
 type HTTPServer struct {
-	Address     string        `yaml:"address" env-default:"0.0.0.0:8080"`
-	Timeout     time.Duration `yaml:"timeout" env-default:"5s"`
-	IdleTimeout time.Duration `yaml:"idle_timeout" env-default:"60s"`
+	Address         string        `yaml:"address" env:"HTTP_ADDRESS" env-default:"0.0.0.0:8080"`
+	Timeout         time.Duration `yaml:"timeout" env:"HTTP_TIMEOUT" env-default:"5s"`
+	IdleTimeout     time.Duration `yaml:"idle_timeout" env:"HTTP_IDLE_TIMEOUT" env-default:"60s"`
+	ShutdownTimeout time.Duration `yaml:"shutdown_timeout" env:"HTTP_SHUTDOWN_TIMEOUT" env-default:"10s"`
 }
 
-func MustLoad() (*Config, string) {
-	// Получаем путь до конфиг-файла из env-переменной CONFIG_PATH
-	configPath := os.Getenv("CONFIG_PATH")
-	if configPath == "" {
-		log.Fatal("CONFIG_PATH environment variable is not set")
+// ICalImport - правила сопоставления категорий событий iCalendar с проектами при импорте
+type ICalImport struct {
+	// CategoryProjects сопоставляет категорию события (без учета регистра) с ID проекта.
+	// В переменной окружения задается как "категория:id,категория:id".
+	CategoryProjects map[string]uint `yaml:"category_projects" env:"ICAL_CATEGORY_PROJECTS"`
+	// MatchProjectNames разрешает сопоставлять категорию с проектом по его названию
+	MatchProjectNames bool `yaml:"match_project_names" env:"ICAL_MATCH_PROJECT_NAMES" env-default:"true"`
+	// DefaultProjectID используется, если ни одна категория события не сопоставлена
+	DefaultProjectID uint `yaml:"default_project_id" env:"ICAL_DEFAULT_PROJECT_ID"`
+}
+
+// Auth - настройки аутентификации API
+type Auth struct {
+	// AdminToken дает права администратора, в том числе на выпуск токенов
+	// разработчикам. Обязателен в любом окружении.
+	AdminToken string `yaml:"admin_token" env:"AUTH_ADMIN_TOKEN"`
+}
+
+// Load читает конфигурацию из файла path и переменных окружения, собирает
+// строку подключения к базе и проверяет результат. Пустой path означает,
+// что конфигурация задается только окружением. Если конфигурация прочитана,
+// но не прошла проверку, Load возвращает ее вместе с *ValidationError, чтобы
+// ее можно было показать.
+func Load(path string) (*Config, error) {
+	var cfg Config
+
+	if path != "" {
+		if _, err := os.Stat(path); err != nil {
+			return nil, fmt.Errorf("error opening config file: %w", err)
+		}
+		if err := cleanenv.ReadConfig(path, &cfg); err != nil {
+			return nil, fmt.Errorf("error reading config file: %w", err)
+		}
+	} else if err := cleanenv.ReadEnv(&cfg); err != nil {
+		return nil, fmt.Errorf("error reading environment: %w", err)
 	}
 
-	// Проверяем существование конфиг-файла
-	if _, err := os.Stat(configPath); err != nil {
-		log.Fatalf("error opening config file: %s", err)
+	problems := cfg.resolveStorage()
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return &cfg, &ValidationError{Problems: problems}
 	}
 
-	var cfg Config
+	return &cfg, nil
+}
 
-	// Читаем конфиг-файл и заполняем нашу структуру
-	err := cleanenv.ReadConfig(configPath, &cfg)
+func MustLoad() (*Config, string) {
+	// Путь до конфиг-файла берется из env-переменной CONFIG_PATH; без него
+	// все настройки читаются из окружения
+	cfg, err := Load(os.Getenv("CONFIG_PATH"))
 	if err != nil {
-		log.Fatalf("error reading config file: %s", err)
+		log.Fatal(err)
 	}
 
-	return &cfg, "app started"
+	return cfg, "app started"
 }
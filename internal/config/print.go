@@ -0,0 +1,51 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"net/url"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+)
+
+// redacted заменяет секреты при выводе конфигурации
+const redacted = "xxxxx"
+
+// dsnPassword находит пароль в строке подключения вида "key=value ..."
+var dsnPassword = regexp.MustCompile(`(password=)(?:'(?:[^'\\]|\\.)*'|\S+)`)
+
+// Redacted возвращает копию конфигурации со скрытыми секретами: паролями,
+// токеном администратора и паролем в строке подключения
+func (c Config) Redacted() Config {
+	if c.Database.Password != "" {
+		c.Database.Password = redacted
+	}
+	if c.Auth.AdminToken != "" {
+		c.Auth.AdminToken = redacted
+	}
+	c.StoragePath = redactDSN(c.StoragePath)
+
+	return c
+}
+
+func redactDSN(dsn string) string {
+	if u, err := url.Parse(dsn); err == nil && u.User != nil {
+		if _, ok := u.User.Password(); ok {
+			u.User = url.UserPassword(u.User.Username(), redacted)
+			return u.String()
+		}
+		return dsn
+	}
+	return dsnPassword.ReplaceAllString(dsn, "${1}"+redacted)
+}
+
+// Print выводит действующую конфигурацию в YAML со скрытыми секретами
+func (c Config) Print(w io.Writer) error {
+	data, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return fmt.Errorf("marshal config: %w", err)
+	}
+	_, err = w.Write(data)
+	return err
+}
@@ -0,0 +1,179 @@
+package config
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+	"os"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// MemoryStorage - значение storage_path, при котором данные хранятся в памяти процесса
+const MemoryStorage = "memory"
+
+// minProdAdminTokenLen - минимальная длина токена администратора в prod
+const minProdAdminTokenLen = 16
+
+var (
+	validEnvs     = []string{"local", "development", "dev", "prod"}
+	validSSLModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+	// knownAdminTokens - значения из примеров и документации, которые нельзя
+	// использовать как токен администратора ни в одном окружении
+	knownAdminTokens = []string{"local-admin-token", "admin", "admin-token", "changeme", "secret", "token", "password"}
+)
+
+// ValidationError перечисляет все ошибки конфигурации сразу, чтобы их можно
+// было исправить за один запуск
+type ValidationError struct {
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "invalid config:\n  - " + strings.Join(e.Problems, "\n  - ")
+}
+
+// resolveStorage заполняет StoragePath, если он не задан явно: строкой из
+// DSNFile или строкой, собранной из секции database. Возвращает ошибки
+// чтения файлов.
+func (c *Config) resolveStorage() []string {
+	if c.StoragePath != "" {
+		return nil
+	}
+
+	db := c.Database
+	if db.DSNFile != "" {
+		dsn, err := readSecret(db.DSNFile)
+		if err != nil {
+			return []string{fmt.Sprintf("database.dsn_file: %v", err)}
+		}
+		c.StoragePath = dsn
+		return nil
+	}
+
+	if db.Host == "" {
+		return nil
+	}
+
+	password := db.Password
+	if db.PasswordFile != "" {
+		secret, err := readSecret(db.PasswordFile)
+		if err != nil {
+			return []string{fmt.Sprintf("database.password_file: %v", err)}
+		}
+		password = secret
+	}
+
+	user := url.User(db.User)
+	if password != "" {
+		user = url.UserPassword(db.User, password)
+	}
+	dsn := url.URL{
+		Scheme:   "postgres",
+		User:     user,
+		Host:     net.JoinHostPort(db.Host, strconv.Itoa(db.Port)),
+		Path:     "/" + db.Name,
+		RawQuery: url.Values{"sslmode": {db.SSLMode}}.Encode(),
+	}
+	c.StoragePath = dsn.String()
+	return nil
+}
+
+// readSecret читает секрет из файла, отбрасывая завершающий перевод строки
+func readSecret(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	secret := strings.TrimRight(string(data), "\r\n")
+	if secret == "" {
+		return "", fmt.Errorf("file %s is empty", path)
+	}
+	return secret, nil
+}
+
+// validate проверяет итоговую конфигурацию и возвращает список ошибок
+func (c *Config) validate() []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if !slices.Contains(validEnvs, c.Env) {
+		add("env: must be one of %s, got %q", strings.Join(validEnvs, ", "), c.Env)
+	}
+
+	if c.StoragePath == "" {
+		add("storage_path: set storage_path, database.host (DB_HOST) or database.dsn_file (DB_DSN_FILE)")
+	} else if c.StoragePath != MemoryStorage && strings.Contains(c.StoragePath, "://") {
+		if _, err := url.Parse(c.StoragePath); err != nil {
+			add("storage_path: invalid connection URL")
+		}
+	}
+
+	if db := c.Database; db.Host != "" {
+		if db.Port < 1 || db.Port > 65535 {
+			add("database.port: must be between 1 and 65535, got %d", db.Port)
+		}
+		if db.User == "" {
+			add("database.user: required when database.host is set")
+		}
+		if db.Name == "" {
+			add("database.name: required when database.host is set")
+		}
+		if db.Password != "" && db.PasswordFile != "" {
+			add("database.password and database.password_file are mutually exclusive")
+		}
+		if !slices.Contains(validSSLModes, db.SSLMode) {
+			add("database.ssl_mode: must be one of %s, got %q", strings.Join(validSSLModes, ", "), db.SSLMode)
+		}
+	}
+
+	if _, port, err := net.SplitHostPort(c.HTTPServer.Address); err != nil {
+		add("http_server.address: must be host:port, got %q", c.HTTPServer.Address)
+	} else if n, err := strconv.Atoi(port); err != nil || n < 0 || n > 65535 {
+		add("http_server.address: invalid port %q", port)
+	}
+	if c.HTTPServer.Timeout <= 0 {
+		add("http_server.timeout: must be positive")
+	}
+	if c.HTTPServer.IdleTimeout <= 0 {
+		add("http_server.idle_timeout: must be positive")
+	}
+	if c.HTTPServer.ShutdownTimeout <= 0 {
+		add("http_server.shutdown_timeout: must be positive")
+	}
+
+	categories := make([]string, 0, len(c.ICalImport.CategoryProjects))
+	for category := range c.ICalImport.CategoryProjects {
+		categories = append(categories, category)
+	}
+	sort.Strings(categories)
+	// Категории сравниваются без учета регистра, поэтому "Dev" и "dev"
+	// сделали бы выбор проекта случайным
+	seen := make(map[string]string, len(categories))
+	for _, category := range categories {
+		if c.ICalImport.CategoryProjects[category] == 0 {
+			add("ical_import.category_projects: project ID for %q must be positive", category)
+		}
+		key := strings.ToLower(category)
+		if other, ok := seen[key]; ok {
+			add("ical_import.category_projects: %q and %q differ only in case", other, category)
+			continue
+		}
+		seen[key] = category
+	}
+
+	switch token := c.Auth.AdminToken; {
+	case token == "":
+		add("auth.admin_token: required, set AUTH_ADMIN_TOKEN")
+	case slices.Contains(knownAdminTokens, strings.ToLower(token)):
+		add("auth.admin_token: must not be a well-known value, generate a random token")
+	case c.Env == "prod" && len(token) < minProdAdminTokenLen:
+		add("auth.admin_token: must be at least %d characters in prod", minProdAdminTokenLen)
+	}
+
+	return problems
+}
@@ -0,0 +1,208 @@
+package config
+
+import (
+	"errors"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// validConfig возвращает конфигурацию, которая проходит проверку
+func validConfig() Config {
+	return Config{
+		Env:         "local",
+		StoragePath: MemoryStorage,
+		Database:    Database{Port: 5432, SSLMode: "disable"},
+		HTTPServer: HTTPServer{
+			Address:         "localhost:8080",
+			Timeout:         5 * time.Second,
+			IdleTimeout:     time.Minute,
+			ShutdownTimeout: 10 * time.Second,
+		},
+		Auth: Auth{AdminToken: "a7f3c9e1b5d24f68"},
+	}
+}
+
+func TestValidateCategoryProjects(t *testing.T) {
+	tests := []struct {
+		name       string
+		categories map[string]uint
+		want       []string
+	}{
+		{"distinct", map[string]uint{"Dev": 1, "ops": 2}, nil},
+		{"zero project", map[string]uint{"dev": 0}, []string{
+			`ical_import.category_projects: project ID for "dev" must be positive`,
+		}},
+		{"differ only in case", map[string]uint{"Dev": 1, "dev": 2, "DEV": 1, "ops": 3}, []string{
+			`ical_import.category_projects: "DEV" and "Dev" differ only in case`,
+			`ical_import.category_projects: "DEV" and "dev" differ only in case`,
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			cfg.ICalImport.CategoryProjects = tt.categories
+			if got := cfg.validate(); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResolveStorage(t *testing.T) {
+	dir := t.TempDir()
+	secret := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	passwordFile := secret("password", "p@ss:w/rd?#%\n")
+	dsnFile := secret("dsn", "postgres://app@db:5432/tasks?sslmode=require\r\n")
+	emptyFile := secret("empty", "\n")
+
+	db := Database{Host: "db", Port: 5432, User: "app", Name: "tasks", SSLMode: "disable"}
+	with := func(change func(*Database)) Database {
+		d := db
+		change(&d)
+		return d
+	}
+
+	tests := []struct {
+		name        string
+		storagePath string
+		db          Database
+		want        string
+		problem     string
+	}{
+		{"explicit storage path wins", "memory", with(func(d *Database) { d.Password = "x" }), "memory", ""},
+		{"no database", "", Database{Port: 5432}, "", ""},
+		{"without password", "", db, "postgres://app@db:5432/tasks?sslmode=disable", ""},
+		// Спецсимволы пароля экранируются и не ломают URL
+		{"password with special characters", "", with(func(d *Database) { d.Password = "p@ss:w/rd?#%" }),
+			"postgres://app:p%40ss%3Aw%2Frd%3F%23%25@db:5432/tasks?sslmode=disable", ""},
+		{"password file", "", with(func(d *Database) { d.PasswordFile = passwordFile }),
+			"postgres://app:p%40ss%3Aw%2Frd%3F%23%25@db:5432/tasks?sslmode=disable", ""},
+		{"ipv6 host", "", with(func(d *Database) { d.Host = "::1" }), "postgres://app@[::1]:5432/tasks?sslmode=disable", ""},
+		{"dsn file", "", with(func(d *Database) { d.DSNFile = dsnFile }), "postgres://app@db:5432/tasks?sslmode=require", ""},
+		{"missing password file", "", with(func(d *Database) { d.PasswordFile = filepath.Join(dir, "missing") }), "", "database.password_file: "},
+		{"empty dsn file", "", with(func(d *Database) { d.DSNFile = emptyFile }), "", "database.dsn_file: file " + emptyFile + " is empty"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := Config{StoragePath: tt.storagePath, Database: tt.db}
+			problems := cfg.resolveStorage()
+			if cfg.StoragePath != tt.want {
+				t.Fatalf("storage path %q, want %q", cfg.StoragePath, tt.want)
+			}
+			switch {
+			case tt.problem == "" && len(problems) != 0:
+				t.Fatalf("unexpected problems %q", problems)
+			case tt.problem != "" && (len(problems) != 1 || !strings.HasPrefix(problems[0], tt.problem)):
+				t.Fatalf("problems %q, want one starting with %q", problems, tt.problem)
+			}
+			if tt.want != "" && tt.want != MemoryStorage {
+				u, err := url.Parse(cfg.StoragePath)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if password, _ := u.User.Password(); tt.db.Password != "" && password != tt.db.Password {
+					t.Fatalf("password %q does not round-trip", password)
+				}
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(*Config)
+		want   []string
+	}{
+		{"valid", func(c *Config) {}, nil},
+		{"unknown env", func(c *Config) { c.Env = "staging" }, []string{
+			`env: must be one of local, development, dev, prod, got "staging"`,
+		}},
+		{"no storage", func(c *Config) { c.StoragePath = "" }, []string{
+			"storage_path: set storage_path, database.host (DB_HOST) or database.dsn_file (DB_DSN_FILE)",
+		}},
+		{"invalid connection URL", func(c *Config) { c.StoragePath = "postgres://app@db:bad port/tasks" }, []string{
+			"storage_path: invalid connection URL",
+		}},
+		{"database section", func(c *Config) {
+			c.Database = Database{Host: "db", Port: 70000, Password: "x", PasswordFile: "/run/secrets/db", SSLMode: "on"}
+		}, []string{
+			"database.port: must be between 1 and 65535, got 70000",
+			"database.user: required when database.host is set",
+			"database.name: required when database.host is set",
+			"database.password and database.password_file are mutually exclusive",
+			`database.ssl_mode: must be one of disable, allow, prefer, require, verify-ca, verify-full, got "on"`,
+		}},
+		{"http server", func(c *Config) {
+			c.HTTPServer = HTTPServer{Address: "localhost:99999"}
+		}, []string{
+			`http_server.address: invalid port "99999"`,
+			"http_server.timeout: must be positive",
+			"http_server.idle_timeout: must be positive",
+			"http_server.shutdown_timeout: must be positive",
+		}},
+		{"address without port", func(c *Config) { c.HTTPServer.Address = "localhost" }, []string{
+			`http_server.address: must be host:port, got "localhost"`,
+		}},
+		{"no admin token", func(c *Config) { c.AdminToken = "" }, []string{
+			"auth.admin_token: required, set AUTH_ADMIN_TOKEN",
+		}},
+		// Известные значения запрещены в любом окружении и без учета регистра
+		{"known admin token", func(c *Config) { c.AdminToken = "local-admin-token" }, []string{
+			"auth.admin_token: must not be a well-known value, generate a random token",
+		}},
+		{"known admin token upper case", func(c *Config) { c.AdminToken = "ChangeMe" }, []string{
+			"auth.admin_token: must not be a well-known value, generate a random token",
+		}},
+		{"short token in prod", func(c *Config) { c.Env = "prod"; c.AdminToken = "a7f3c9e1" }, []string{
+			"auth.admin_token: must be at least 16 characters in prod",
+		}},
+		{"short token outside prod", func(c *Config) { c.AdminToken = "a7f3c9e1" }, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig()
+			tt.change(&cfg)
+			if got := cfg.validate(); strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Fatalf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLoadReturnsInvalidConfig(t *testing.T) {
+	// Переменные окружения переопределили бы значения из файла;
+	// t.Setenv восстановит их после теста
+	for _, key := range []string{"ENV", "STORAGE_PATH", "AUTH_ADMIN_TOKEN", "DB_HOST", "DB_DSN_FILE"} {
+		t.Setenv(key, "")
+		os.Unsetenv(key)
+	}
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("env: staging\nstorage_path: memory\nauth:\n  admin_token: admin\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Непрошедшая проверку конфигурация возвращается вместе с ошибкой, чтобы
+	// config print мог ее показать
+	cfg, err := Load(path)
+	var verr *ValidationError
+	if !errors.As(err, &verr) || cfg == nil {
+		t.Fatalf("Load = %v, %v; want config and *ValidationError", cfg, err)
+	}
+	if cfg.Env != "staging" || len(verr.Problems) != 2 {
+		t.Fatalf("env %q, problems %q", cfg.Env, verr.Problems)
+	}
+}
@@ -0,0 +1,338 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"goproject/internal/http_server/handlers/analytics"
+	"goproject/internal/http_server/handlers/audit"
+	"goproject/internal/http_server/handlers/calendar"
+	developers "goproject/internal/http_server/handlers/developers"
+	"goproject/internal/http_server/handlers/export"
+	"goproject/internal/http_server/handlers/health"
+	"goproject/internal/http_server/handlers/project"
+	"goproject/internal/http_server/handlers/report"
+	"goproject/internal/http_server/handlers/task"
+	"goproject/internal/http_server/handlers/tokens"
+	"goproject/internal/http_server/mergepatch"
+	"goproject/internal/http_server/router"
+	"goproject/internal/openapi"
+	"net/http"
+)
+
+const (
+	apiTitle   = "Task Calendar API"
+	apiVersion = "1.0.0"
+)
+
+// Параметры постраничных списков, см. listparams.Parse
+var (
+	limitParam   = openapi.Query("limit", "integer", "page size")
+	cursorParam  = openapi.Query("cursor", "string", "next_cursor from the previous page")
+	devParam     = openapi.Query("developer", "uuid", "filter by developer")
+	projectParam = openapi.Query("project", "integer", "filter by project")
+	fromParam    = openapi.Query("from", "string", "start of the interval, 2006-01-02 or RFC3339")
+	toParam      = openapi.Query("to", "string", "end of the interval, 2006-01-02 or RFC3339")
+	deletedParam = openapi.Query("include_deleted", "boolean", "include soft-deleted records")
+	// ETag отдают GET и PUT проекта, разработчика и задачи
+	ifMatchParam = openapi.Param{
+		Name: "If-Match", In: "header", Required: true,
+		Description: "ETag of the version being updated, as returned by GET",
+		Schema:      &openapi.Schema{Type: "string"},
+	}
+)
+
+func sortParam(fields string) openapi.Param {
+	return openapi.Query("sort", "string", "sort field: "+fields+"; prefix with - for descending order")
+}
+
+// apiErrors - ошибки, общие для защищенных маршрутов: отказ политики доступа
+// и ошибка хранилища
+func apiErrors(codes ...int) []int {
+	return append(codes, http.StatusForbidden, http.StatusInternalServerError)
+}
+
+// operations описывает все маршруты NewRouter для документа OpenAPI. Тест
+// сверяет этот список с зарегистрированными маршрутами, а документ - с
+// опубликованным handlers/docs/openapi.json.
+var operations = []openapi.Operation{
+	{
+		Method: http.MethodGet, Path: "/projects", Tag: "projects", Summary: "List projects",
+		Params: []openapi.Param{limitParam, cursorParam, sortParam("created_at, name, id"), devParam, fromParam, toParam, deletedParam},
+		Status: http.StatusOK, Response: project.ProjectResponseGetAll{}, Errors: apiErrors(http.StatusBadRequest),
+	},
+	{
+		Method: http.MethodPost, Path: "/projects", Tag: "projects", Summary: "Create project",
+		Request: project.ProjectRequestPost{},
+		Status:  http.StatusCreated, Response: project.ProjectResponsePost{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity),
+	},
+	{
+		Method: http.MethodGet, Path: "/projects/{id}", Tag: "projects", Summary: "Get project",
+		Params: []openapi.Param{openapi.PathInt("id")},
+		Status: http.StatusOK, Response: project.ProjectResponseGet{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+	{
+		Method: http.MethodPut, Path: "/projects/{id}", Tag: "projects", Summary: "Update project",
+		Params:  []openapi.Param{openapi.PathInt("id"), ifMatchParam},
+		Request: project.ProjectUpdateRequest{},
+		Status:  http.StatusOK, Response: project.ProjectResponse{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusPreconditionFailed, http.StatusPreconditionRequired),
+	},
+	{
+		Method: http.MethodPatch, Path: "/projects/{id}", Tag: "projects", Summary: "Partially update project with JSON Merge Patch",
+		Params:         []openapi.Param{openapi.PathInt("id"), ifMatchParam},
+		Request:        project.ProjectUpdateRequest{},
+		RequestContent: mergepatch.ContentType,
+		Status:         http.StatusOK, Response: project.ProjectResponse{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity, http.StatusPreconditionFailed, http.StatusPreconditionRequired),
+	},
+	{
+		Method: http.MethodDelete, Path: "/projects/{id}", Tag: "projects", Summary: "Soft-delete project",
+		Params: []openapi.Param{openapi.PathInt("id")},
+		Status: http.StatusOK, Response: project.ProjectResponse{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+	{
+		Method: http.MethodPost, Path: "/projects/{id}/archive", Tag: "projects", Summary: "Archive project",
+		Params: []openapi.Param{openapi.PathInt("id")},
+		Status: http.StatusOK, Response: project.ProjectResponse{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+	{
+		Method: http.MethodPost, Path: "/projects/{id}/restore", Tag: "projects", Summary: "Restore archived or deleted project",
+		Params: []openapi.Param{openapi.PathInt("id")},
+		Status: http.StatusOK, Response: project.ProjectResponse{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+
+	{
+		Method: http.MethodGet, Path: "/developers", Tag: "developers", Summary: "List developers",
+		Params: []openapi.Param{limitParam, cursorParam, sortParam("created_at, name, last_name"), projectParam, fromParam, toParam, deletedParam},
+		Status: http.StatusOK, Response: developers.DeveloperResponseGetAll{}, Errors: apiErrors(http.StatusBadRequest),
+	},
+	{
+		Method: http.MethodPost, Path: "/developers", Tag: "developers", Summary: "Create developer",
+		Request: developers.DeveloperRequest{},
+		Status:  http.StatusCreated, Response: developers.DeveloperResponse{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusConflict, http.StatusUnprocessableEntity),
+	},
+	{
+		Method: http.MethodGet, Path: "/developers/{id}", Tag: "developers", Summary: "Get developer",
+		Params: []openapi.Param{openapi.PathUUID("id"), deletedParam},
+		Status: http.StatusOK, Response: developers.DeveloperResponseGet{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+	{
+		Method: http.MethodPut, Path: "/developers/{id}", Tag: "developers", Summary: "Update developer",
+		Params:  []openapi.Param{openapi.PathUUID("id"), ifMatchParam},
+		Request: developers.DeveloperUpdateRequest{},
+		Status:  http.StatusOK, Response: developers.DeveloperResponseGet{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnprocessableEntity, http.StatusPreconditionFailed, http.StatusPreconditionRequired),
+	},
+	{
+		Method: http.MethodPatch, Path: "/developers/{id}", Tag: "developers", Summary: "Partially update developer with JSON Merge Patch",
+		Params:         []openapi.Param{openapi.PathUUID("id"), ifMatchParam},
+		Request:        developers.DeveloperUpdateRequest{},
+		RequestContent: mergepatch.ContentType,
+		Status:         http.StatusOK, Response: developers.DeveloperResponseGet{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound, http.StatusConflict, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity, http.StatusPreconditionFailed, http.StatusPreconditionRequired),
+	},
+	{
+		Method: http.MethodDelete, Path: "/developers/{id}", Tag: "developers", Summary: "Soft-delete developer",
+		Params: []openapi.Param{openapi.PathUUID("id")},
+		Status: http.StatusOK, Response: developers.DeveloperResponse{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+	{
+		Method: http.MethodPost, Path: "/developers/{id}/restore", Tag: "developers", Summary: "Restore deleted developer",
+		Params: []openapi.Param{openapi.PathUUID("id")},
+		Status: http.StatusOK, Response: developers.DeveloperResponse{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+	{
+		Method: http.MethodGet, Path: "/developers/{id}/tokens", Tag: "tokens", Summary: "List developer API tokens",
+		Params: []openapi.Param{openapi.PathUUID("id")},
+		Status: http.StatusOK, Response: tokens.TokenResponseGetAll{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+	{
+		Method: http.MethodPost, Path: "/developers/{id}/tokens", Tag: "tokens", Summary: "Issue developer API token",
+		Params:  []openapi.Param{openapi.PathUUID("id")},
+		Request: tokens.TokenRequestPost{},
+		Status:  http.StatusCreated, Response: tokens.TokenResponsePost{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity),
+	},
+	{
+		Method: http.MethodDelete, Path: "/developers/{id}/tokens/{token_id}", Tag: "tokens", Summary: "Revoke developer API token",
+		Params: []openapi.Param{openapi.PathUUID("id"), openapi.PathInt("token_id")},
+		Status: http.StatusOK, Response: tokens.TokenResponseDelete{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+	{
+		Method: http.MethodGet, Path: "/developers/{id}/reports", Tag: "reports", Summary: "List developer reports",
+		Params: []openapi.Param{openapi.PathUUID("id"), limitParam, cursorParam, sortParam("created_at, id"), projectParam, fromParam, toParam},
+		Status: http.StatusOK, Response: report.DevReportResponseGet{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+	{
+		Method: http.MethodGet, Path: "/developers/{id}/calendar", Tag: "calendar", Summary: "Developer calendar",
+		Params: []openapi.Param{
+			openapi.PathUUID("id"),
+			openapi.QueryEnum("view", "calendar range, week by default", calendar.ViewDay, calendar.ViewWeek, calendar.ViewMonth),
+			openapi.Query("from", "date", "day inside the range, today by default"),
+		},
+		Status: http.StatusOK, Response: calendar.CalendarResponse{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+	{
+		Method: http.MethodGet, Path: "/developers/{id}/calendar.ics", Tag: "calendar", Summary: "Developer tasks as iCalendar",
+		Params: []openapi.Param{openapi.PathUUID("id")},
+		Status: http.StatusOK, ResponseContent: openapi.ContentCalendar, ErrorResponse: calendar.CalendarResponse{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+
+	{
+		Method: http.MethodGet, Path: "/reports", Tag: "reports", Summary: "List reports",
+		Params: []openapi.Param{limitParam, cursorParam, sortParam("created_at, id"), devParam, projectParam, fromParam, toParam},
+		Status: http.StatusOK, Response: report.ReportResponseGetAll{}, Errors: apiErrors(http.StatusBadRequest),
+	},
+	{
+		Method: http.MethodPost, Path: "/reports", Tag: "reports", Summary: "Submit report with tasks",
+		Request: report.ReportRequestPost{},
+		Status:  http.StatusCreated, Response: report.ReportResponsePost{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusUnprocessableEntity),
+	},
+	{
+		Method: http.MethodGet, Path: "/reports/{id}", Tag: "reports", Summary: "Get report",
+		Params: []openapi.Param{openapi.PathInt("id")},
+		Status: http.StatusOK, Response: report.ReportResponseGet{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+	{
+		Method: http.MethodPost, Path: "/reports/{id}/approve", Tag: "reports", Summary: "Approve report",
+		Params: []openapi.Param{openapi.PathInt("id")},
+		Status: http.StatusOK, Response: report.ReportResponseGet{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+	{
+		Method: http.MethodPost, Path: "/reports/{id}/import", Tag: "reports", Summary: "Import tasks from an iCalendar file",
+		Params:         []openapi.Param{openapi.PathInt("id")},
+		RequestContent: openapi.ContentCalendar,
+		Status:         http.StatusCreated, Response: report.ReportImportResponse{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity),
+	},
+
+	{
+		Method: http.MethodGet, Path: "/tasks", Tag: "tasks", Summary: "List tasks",
+		Params: []openapi.Param{limitParam, cursorParam, sortParam("start_timestamp, created_at, id"), devParam, projectParam, fromParam, toParam},
+		Status: http.StatusOK, Response: task.TaskResponseGetAll{}, Errors: apiErrors(http.StatusBadRequest),
+	},
+	{
+		Method: http.MethodPost, Path: "/tasks", Tag: "tasks", Summary: "Create task",
+		Request: task.TaskRequest{},
+		Status:  http.StatusCreated, Response: task.TaskResponsePost{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusUnprocessableEntity),
+	},
+	{
+		Method: http.MethodGet, Path: "/tasks/{id}", Tag: "tasks", Summary: "Get task",
+		Params: []openapi.Param{openapi.PathInt("id")},
+		Status: http.StatusOK, Response: task.TaskResponseGet{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+	{
+		Method: http.MethodPut, Path: "/tasks/{id}", Tag: "tasks", Summary: "Update task",
+		Params:  []openapi.Param{openapi.PathInt("id"), ifMatchParam},
+		Request: task.TaskRequest{},
+		Status:  http.StatusOK, Response: task.TaskResponse{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound, http.StatusUnprocessableEntity, http.StatusPreconditionFailed, http.StatusPreconditionRequired),
+	},
+	{
+		Method: http.MethodPatch, Path: "/tasks/{id}", Tag: "tasks", Summary: "Partially update task with JSON Merge Patch",
+		Params:         []openapi.Param{openapi.PathInt("id"), ifMatchParam},
+		Request:        task.TaskRequest{},
+		RequestContent: mergepatch.ContentType,
+		Status:         http.StatusOK, Response: task.TaskResponse{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound, http.StatusUnsupportedMediaType, http.StatusUnprocessableEntity, http.StatusPreconditionFailed, http.StatusPreconditionRequired),
+	},
+	{
+		Method: http.MethodDelete, Path: "/tasks/{id}", Tag: "tasks", Summary: "Delete task",
+		Params: []openapi.Param{openapi.PathInt("id")},
+		Status: http.StatusOK, Response: task.TaskResponseDelete{},
+		Errors: apiErrors(http.StatusBadRequest, http.StatusNotFound),
+	},
+
+	{
+		Method: http.MethodGet, Path: "/analytics", Tag: "analytics", Summary: "Planned vs logged hours",
+		Params: []openapi.Param{
+			openapi.Query("from", "date", "start of the interval, first day of the month by default"),
+			openapi.Query("to", "date", "end of the interval, exclusive"),
+			openapi.QueryEnum("group_by", "return only one breakdown", "project", "developer"),
+		},
+		Status: http.StatusOK, Response: analytics.AnalyticsResponse{}, Errors: apiErrors(http.StatusBadRequest),
+	},
+	{
+		Method: http.MethodGet, Path: "/audit", Tag: "audit", Summary: "Audit log of changes",
+		Params: []openapi.Param{
+			openapi.QueryEnum("entity", "entity type", "project", "developer", "report", "task", "token"),
+			openapi.Query("id", "string", "entity ID, requires entity"),
+			openapi.Query("developer", "uuid", "author of the change"),
+			limitParam, cursorParam, sortParam("created_at, id"), fromParam, toParam,
+		},
+		Status: http.StatusOK, Response: audit.AuditResponseGetAll{}, Errors: apiErrors(http.StatusBadRequest),
+	},
+	{
+		Method: http.MethodGet, Path: "/exports/tasks", Tag: "exports", Summary: "Stream tasks with developer and project names as CSV or NDJSON",
+		Params: []openapi.Param{
+			openapi.QueryEnum("format", "csv by default; ndjson is served as application/x-ndjson", "csv", "ndjson"),
+			devParam, projectParam, fromParam, toParam,
+		},
+		Status: http.StatusOK, ResponseContent: openapi.ContentCSV, ErrorResponse: export.ExportResponse{},
+		Errors: apiErrors(http.StatusBadRequest),
+	},
+	{
+		Method: http.MethodGet, Path: "/exports/reports", Tag: "exports", Summary: "Stream reports with task totals as CSV or NDJSON",
+		Params: []openapi.Param{
+			openapi.QueryEnum("format", "csv by default; ndjson is served as application/x-ndjson", "csv", "ndjson"),
+			devParam, projectParam, fromParam, toParam,
+		},
+		Status: http.StatusOK, ResponseContent: openapi.ContentCSV, ErrorResponse: export.ExportResponse{},
+		Errors: apiErrors(http.StatusBadRequest),
+	},
+
+	{
+		Method: http.MethodGet, Path: "/healthz", Tag: "service", Summary: "Liveness probe", Public: true,
+		Status: http.StatusOK, Response: health.HealthResponse{},
+	},
+	{
+		Method: http.MethodGet, Path: "/readyz", Tag: "service", Summary: "Readiness probe, pings the storage", Public: true,
+		Status: http.StatusOK, Response: health.HealthResponse{}, Errors: []int{http.StatusServiceUnavailable},
+	},
+	{
+		Method: http.MethodGet, Path: "/metrics", Tag: "service", Summary: "Prometheus metrics", Public: true,
+		Status: http.StatusOK, ResponseContent: openapi.ContentText,
+	},
+	{
+		Method: http.MethodGet, Path: "/openapi.json", Tag: "service", Summary: "This document", Public: true,
+		Status: http.StatusOK,
+	},
+	{
+		Method: http.MethodGet, Path: "/docs", Tag: "service", Summary: "API documentation page", Public: true,
+		Status: http.StatusOK, ResponseContent: openapi.ContentHTML,
+	},
+}
+
+// OpenAPISpec строит документ OpenAPI по operations в том виде, в котором
+// он публикуется на /openapi.json
+func OpenAPISpec() ([]byte, error) {
+	doc, err := openapi.Build(apiTitle, apiVersion, operations, router.ErrorResponse{})
+	if err != nil {
+		return nil, fmt.Errorf("build openapi: %w", err)
+	}
+
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("marshal openapi: %w", err)
+	}
+	return append(data, '\n'), nil
+}
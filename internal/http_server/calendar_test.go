@@ -0,0 +1,61 @@
+package httpserver
+
+import (
+	"goproject/internal/ical"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// icsEvent выгружает календарь разработчика и возвращает его единственное
+// событие
+func icsEvent(t *testing.T, srv *httptest.Server, developer string) ical.Event {
+	t.Helper()
+	resp := call(t, srv, http.MethodGet, developer+"/calendar.ics", "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("calendar.ics: status %d", resp.StatusCode)
+	}
+	defer resp.Body.Close()
+	events, eventErrs, err := ical.Decode(resp.Body)
+	if err != nil || len(eventErrs) != 0 {
+		t.Fatalf("decode calendar: %v, %v", err, eventErrs)
+	}
+	if len(events) != 1 {
+		t.Fatalf("got %d events, want 1", len(events))
+	}
+	return events[0]
+}
+
+func TestCalendarICSSequence(t *testing.T) {
+	srv := newTestServer(t)
+	developer, _, task := createTask(t, srv)
+
+	event := icsEvent(t, srv, developer)
+	if event.Sequence != 0 {
+		t.Fatalf("new task SEQUENCE = %d, want 0", event.Sequence)
+	}
+	if len(event.Categories) != 1 || event.Categories[0] != "Alpha" {
+		t.Fatalf("categories %v, want [Alpha]", event.Categories)
+	}
+
+	// Правки подряд укладываются в одну секунду, но SEQUENCE растет с каждой
+	for i, name := range []string{"first", "second"} {
+		etag, _ := version(t, srv, task, "task")
+		if resp := patch(t, srv, task, `{"name":"`+name+`"}`, etag); resp.StatusCode != http.StatusOK {
+			t.Fatalf("patch %s: status %d", name, resp.StatusCode)
+		}
+		event := icsEvent(t, srv, developer)
+		if event.Summary != name || event.Sequence != i+1 {
+			t.Fatalf("after edit %d: SUMMARY %q, SEQUENCE %d", i+1, event.Summary, event.Sequence)
+		}
+	}
+
+	// Пустой патч задачу не меняет
+	etag, _ := version(t, srv, task, "task")
+	if resp := patch(t, srv, task, `{}`, etag); resp.StatusCode != http.StatusOK {
+		t.Fatalf("empty patch: status %d", resp.StatusCode)
+	}
+	if event := icsEvent(t, srv, developer); event.Sequence != 2 {
+		t.Fatalf("after empty patch SEQUENCE = %d, want 2", event.Sequence)
+	}
+}
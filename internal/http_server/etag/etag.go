@@ -0,0 +1,35 @@
+// Package etag выводит версии сущностей для заголовков ETag и If-Match.
+// Версия - время последнего изменения (ModifiedAt), поэтому ETag меняется
+// при каждом изменении записи и не требует отдельного счетчика.
+package etag
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format возвращает сильный ETag для версии modified
+func Format(modified time.Time) string {
+	return `"` + strconv.FormatInt(modified.UnixNano(), 36) + `"`
+}
+
+// Set записывает ETag версии modified в заголовки ответа
+func Set(w http.ResponseWriter, modified time.Time) {
+	w.Header().Set("ETag", Format(modified))
+}
+
+// Match сообщает, соответствует ли значение заголовка If-Match версии
+// modified. "*" соответствует любой версии; слабые ETag (W/) не
+// соответствуют никогда, как требует строгое сравнение RFC 9110.
+func Match(ifMatch string, modified time.Time) bool {
+	current := Format(modified)
+	for _, tag := range strings.Split(ifMatch, ",") {
+		tag = strings.TrimSpace(tag)
+		if tag == "*" || tag == current {
+			return true
+		}
+	}
+	return false
+}
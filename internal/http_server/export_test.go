@@ -0,0 +1,139 @@
+package httpserver
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+func TestExportCSVEscapesFormulas(t *testing.T) {
+	srv := newTestServer(t)
+
+	// Имена и заметка выглядят как формулы электронной таблицы
+	resp := call(t, srv, http.MethodPost, "/developers", `{"name":"=cmd|' /C calc'!A0","last_name":"@Lee"}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create developer: status %d", resp.StatusCode)
+	}
+	var dev struct {
+		DeveloperID uuid.UUID `json:"developer_id"`
+	}
+	decode(t, resp, &dev)
+	projectID := createProject(t, srv, "+Alpha")
+
+	report := fmt.Sprintf(`{"developer_id":%q,"tasks":[
+		{"project_id":%d,"name":"-2+3","developer_note":"=HYPERLINK(\"http://example.com\")","estimate_planed":2,"estimate_progress":1,
+		 "start_timestamp":"2026-10-01T09:00:00Z","end_timestamp":"2026-10-01T11:00:00Z"},
+		{"project_id":%d,"name":"plain","developer_note":"a=b","estimate_planed":3,
+		 "start_timestamp":"2026-10-02T09:00:00Z","end_timestamp":"2026-10-02T12:00:00Z"}]}`, dev.DeveloperID, projectID, projectID)
+	if resp := call(t, srv, http.MethodPost, "/reports", report); resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create report: status %d", resp.StatusCode)
+	}
+
+	t.Run("tasks csv", func(t *testing.T) {
+		records := exportCSV(t, call(t, srv, http.MethodGet, "/exports/tasks", ""), "tasks.csv")
+		if len(records) != 3 {
+			t.Fatalf("got %d records, want header and 2 rows", len(records))
+		}
+		row := columns(records[0], records[1])
+		want := map[string]string{
+			"developer_name":      "'=cmd|' /C calc'!A0",
+			"developer_last_name": "'@Lee",
+			"project_name":        "'+Alpha",
+			"name":                "'-2+3",
+			"developer_note":      `'=HYPERLINK("http://example.com")`,
+			"estimate_planed":     "2",
+		}
+		for column, value := range want {
+			if row[column] != value {
+				t.Errorf("%s = %q, want %q", column, row[column], value)
+			}
+		}
+		// Значения, не начинающиеся с символа формулы, не меняются
+		if row := columns(records[0], records[2]); row["name"] != "plain" || row["developer_note"] != "a=b" {
+			t.Errorf("plain values changed: %q, %q", row["name"], row["developer_note"])
+		}
+	})
+
+	t.Run("tasks ndjson keeps values", func(t *testing.T) {
+		resp := call(t, srv, http.MethodGet, "/exports/tasks?format=ndjson", "")
+		if resp.StatusCode != http.StatusOK || resp.Header.Get("Content-Type") != "application/x-ndjson" {
+			t.Fatalf("status %d, Content-Type %q", resp.StatusCode, resp.Header.Get("Content-Type"))
+		}
+		var row struct {
+			Name          string `json:"name"`
+			DeveloperNote string `json:"developer_note"`
+		}
+		if err := json.NewDecoder(resp.Body).Decode(&row); err != nil {
+			t.Fatal(err)
+		}
+		if row.Name != "-2+3" || row.DeveloperNote != `=HYPERLINK("http://example.com")` {
+			t.Fatalf("ndjson row changed: %+v", row)
+		}
+	})
+
+	t.Run("reports csv", func(t *testing.T) {
+		records := exportCSV(t, call(t, srv, http.MethodGet, "/exports/reports", ""), "reports.csv")
+		if len(records) != 2 {
+			t.Fatalf("got %d records, want header and 1 row", len(records))
+		}
+		row := columns(records[0], records[1])
+		want := map[string]string{
+			"developer_id":        dev.DeveloperID.String(),
+			"developer_name":      "'=cmd|' /C calc'!A0",
+			"developer_last_name": "'@Lee",
+			"task_count":          "2",
+			"estimate_planed":     "5",
+			"estimate_progress":   "1",
+			"first_start":         "2026-10-01T09:00:00Z",
+			"last_end":            "2026-10-02T12:00:00Z",
+			"approved_at":         "",
+		}
+		for column, value := range want {
+			if row[column] != value {
+				t.Errorf("%s = %q, want %q", column, row[column], value)
+			}
+		}
+	})
+
+	t.Run("reports filtered out", func(t *testing.T) {
+		records := exportCSV(t, call(t, srv, http.MethodGet, "/exports/reports?project=999", ""), "reports.csv")
+		if len(records) != 1 {
+			t.Fatalf("got %d records, want only the header", len(records))
+		}
+	})
+
+	t.Run("invalid format", func(t *testing.T) {
+		if resp := call(t, srv, http.MethodGet, "/exports/reports?format=xlsx", ""); resp.StatusCode != http.StatusBadRequest {
+			t.Fatalf("status %d, want 400", resp.StatusCode)
+		}
+	})
+}
+
+// exportCSV проверяет заголовки ответа выгрузки и читает CSV
+func exportCSV(t *testing.T, resp *http.Response, filename string) [][]string {
+	t.Helper()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status %d", resp.StatusCode)
+	}
+	if want := `attachment; filename="` + filename + `"`; resp.Header.Get("Content-Disposition") != want {
+		t.Fatalf("Content-Disposition %q, want %q", resp.Header.Get("Content-Disposition"), want)
+	}
+	records, err := csv.NewReader(resp.Body).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return records
+}
+
+// columns сопоставляет значения строки с заголовками колонок
+func columns(header, record []string) map[string]string {
+	row := make(map[string]string, len(header))
+	for i, name := range header {
+		row[name] = record[i]
+	}
+	return row
+}
@@ -0,0 +1,127 @@
+package analytics
+
+import (
+	"encoding/json"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+	"time"
+)
+
+// AnalyticsResponse - плановые и фактические трудозатраты за период
+type AnalyticsResponse struct {
+	Status     string                  `json:"status"`
+	Error      string                  `json:"error,omitempty"`
+	From       time.Time               `json:"from,omitempty"`
+	To         time.Time               `json:"to,omitempty"`
+	Projects   []entity.ProjectStats   `json:"projects,omitempty"`
+	Developers []entity.DeveloperStats `json:"developers,omitempty"`
+}
+
+type StatsGetter interface {
+	GetProjectStats(from, to time.Time) ([]entity.ProjectStats, error)
+	GetDeveloperStats(from, to time.Time) ([]entity.DeveloperStats, error)
+}
+
+// NewGetAnalyticsHandler создает обработчик аналитики план/факт по проектам
+// и разработчикам. Параметры from и to (2006-01-02) задают период [from, to),
+// по умолчанию - текущий месяц. Параметр group_by (project, developer)
+// ограничивает ответ одним разрезом.
+func NewGetAnalyticsHandler(getter StatsGetter, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := pol.ReadAnalytics(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(AnalyticsResponse{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		query := r.URL.Query()
+
+		now := time.Now().UTC()
+		from := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(0, 1, 0)
+
+		var err error
+		if v := query.Get("from"); v != "" {
+			if from, err = time.Parse("2006-01-02", v); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(AnalyticsResponse{
+					Status: "error",
+					Error:  "invalid from format",
+				})
+				return
+			}
+		}
+		if v := query.Get("to"); v != "" {
+			if to, err = time.Parse("2006-01-02", v); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(AnalyticsResponse{
+					Status: "error",
+					Error:  "invalid to format",
+				})
+				return
+			}
+		}
+
+		if !to.After(from) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(AnalyticsResponse{
+				Status: "error",
+				Error:  "to must be after from",
+			})
+			return
+		}
+
+		groupBy := query.Get("group_by")
+		if groupBy != "" && groupBy != "project" && groupBy != "developer" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(AnalyticsResponse{
+				Status: "error",
+				Error:  "group_by must be project or developer",
+			})
+			return
+		}
+
+		resp := AnalyticsResponse{
+			Status: "ok",
+			From:   from,
+			To:     to,
+		}
+
+		if groupBy != "developer" {
+			resp.Projects, err = getter.GetProjectStats(from, to)
+			if err != nil {
+				logger.FromContext(r.Context()).Error("failed to get project analytics", logger.Err(err))
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(AnalyticsResponse{
+					Status: "error",
+					Error:  "failed to get project analytics",
+				})
+				return
+			}
+		}
+
+		if groupBy != "project" {
+			resp.Developers, err = getter.GetDeveloperStats(from, to)
+			if err != nil {
+				logger.FromContext(r.Context()).Error("failed to get developer analytics", logger.Err(err))
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(AnalyticsResponse{
+					Status: "error",
+					Error:  "failed to get developer analytics",
+				})
+				return
+			}
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
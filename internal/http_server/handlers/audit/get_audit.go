@@ -0,0 +1,86 @@
+package audit
+
+import (
+	"encoding/json"
+	"goproject/internal/http_server/listparams"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+)
+
+type AuditResponseGetAll struct {
+	Status     string              `json:"status"`
+	Error      string              `json:"error,omitempty"`
+	Entries    []entity.AuditEntry `json:"entries,omitempty"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+}
+
+type AuditGetter interface {
+	GetAudit(params er.ListParams) ([]entity.AuditEntry, string, error)
+}
+
+// NewGetAuditHandler отдает журнал аудита постранично. Параметры: entity
+// (project, developer, report, task, token), id, developer - автор изменения,
+// from, to, limit, cursor, sort (created_at, id; по умолчанию -created_at).
+func NewGetAuditHandler(getter AuditGetter, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := pol.ReadAudit(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(AuditResponseGetAll{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		params, err := listparams.Parse(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(AuditResponseGetAll{
+				Status: "error",
+				Error:  err.Error(),
+			})
+			return
+		}
+
+		if params.EntityID != "" && params.Entity == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(AuditResponseGetAll{
+				Status: "error",
+				Error:  "entity is required when id is set",
+			})
+			return
+		}
+
+		entries, next, err := getter.GetAudit(params)
+		if err != nil {
+			if listparams.IsBadRequest(err) {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(AuditResponseGetAll{
+					Status: "error",
+					Error:  "invalid cursor or sort",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get audit log", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(AuditResponseGetAll{
+				Status: "error",
+				Error:  "failed to get audit log",
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(AuditResponseGetAll{
+			Status:     "ok",
+			Entries:    entries,
+			NextCursor: next,
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/http_server/router"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// CalendarResponse - структура ответа с календарем задач разработчика
+type CalendarResponse struct {
+	Status       string        `json:"status"`
+	Error        string        `json:"error,omitempty"`
+	View         string        `json:"view,omitempty"`
+	From         time.Time     `json:"from,omitempty"`
+	To           time.Time     `json:"to,omitempty"`
+	Days         []CalendarDay `json:"days,omitempty"`
+	TotalMinutes int           `json:"total_minutes"`
+}
+
+// CalendarGetter - интерфейс для получения задач разработчика за период
+type CalendarGetter interface {
+	GetDeveloperByID(uid uuid.UUID) (entity.Developer, error)
+	GetTasksByDeveloperInRange(developerID uuid.UUID, from, to time.Time) ([]entity.Task, error)
+}
+
+// NewGetCalendarHandler создает обработчик календаря задач разработчика.
+// Параметры запроса: view (day, week, month; по умолчанию week) и
+// from (дата 2006-01-02 или RFC 3339; по умолчанию текущий день).
+func NewGetCalendarHandler(getter CalendarGetter, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		developerID, err := uuid.Parse(router.Param(r, "id"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(CalendarResponse{
+				Status: "error",
+				Error:  "invalid developer ID format",
+			})
+			return
+		}
+
+		if err := pol.ReadDeveloper(r.Context(), developerID); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(CalendarResponse{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		query := r.URL.Query()
+
+		view := query.Get("view")
+		if view == "" {
+			view = ViewWeek
+		}
+
+		day := time.Now().UTC()
+		if fromStr := query.Get("from"); fromStr != "" {
+			day, err = parseDay(fromStr)
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(CalendarResponse{
+					Status: "error",
+					Error:  "invalid from format",
+				})
+				return
+			}
+		}
+
+		from, to, ok := viewRange(view, day)
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(CalendarResponse{
+				Status: "error",
+				Error:  "view must be one of day, week, month",
+			})
+			return
+		}
+
+		if _, err := getter.GetDeveloperByID(developerID); err != nil {
+			if errors.Is(err, er.ErrDeveloperNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(CalendarResponse{
+					Status: "error",
+					Error:  "developer not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get developer", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(CalendarResponse{
+				Status: "error",
+				Error:  "failed to get developer",
+			})
+			return
+		}
+
+		tasks, err := getter.GetTasksByDeveloperInRange(developerID, from, to)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("failed to get tasks", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(CalendarResponse{
+				Status: "error",
+				Error:  "failed to get tasks",
+			})
+			return
+		}
+
+		days := layoutDays(tasks, from, to)
+
+		total := 0
+		for _, d := range days {
+			total += d.TotalMinutes
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(CalendarResponse{
+			Status:       "success",
+			View:         view,
+			From:         from,
+			To:           to,
+			Days:         days,
+			TotalMinutes: total,
+		})
+	}
+}
+
+func parseDay(value string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
@@ -0,0 +1,145 @@
+package calendar
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"goproject/internal/http_server/router"
+	"goproject/internal/ical"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// ICSGetter - интерфейс для выгрузки задач разработчика в iCalendar
+type ICSGetter interface {
+	GetDeveloperByID(uid uuid.UUID) (entity.Developer, error)
+	GetTasksByDeveloperID(developerID uuid.UUID) ([]entity.Task, error)
+	GetProjectsByIDs(IDs []uint) ([]entity.Project, error)
+}
+
+// TaskUID возвращает постоянный UID события для задачи, чтобы повторный
+// импорт календаря обновлял события, а не дублировал их
+func TaskUID(taskID uint) string {
+	return fmt.Sprintf("task-%d@task-calendar", taskID)
+}
+
+// NewGetCalendarICSHandler создает обработчик выгрузки задач разработчика в формате iCalendar
+func NewGetCalendarICSHandler(getter ICSGetter, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		developerID, err := uuid.Parse(router.Param(r, "id"))
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(CalendarResponse{
+				Status: "error",
+				Error:  "invalid developer ID format",
+			})
+			return
+		}
+
+		if err := pol.ReadDeveloper(r.Context(), developerID); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(CalendarResponse{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		developer, err := getter.GetDeveloperByID(developerID)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			if errors.Is(err, er.ErrDeveloperNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(CalendarResponse{
+					Status: "error",
+					Error:  "developer not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get developer", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(CalendarResponse{
+				Status: "error",
+				Error:  "failed to get developer",
+			})
+			return
+		}
+
+		tasks, err := getter.GetTasksByDeveloperID(developerID)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			logger.FromContext(r.Context()).Error("failed to get tasks", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(CalendarResponse{
+				Status: "error",
+				Error:  "failed to get tasks",
+			})
+			return
+		}
+
+		// Названия проектов загружаются одним запросом для всех задач
+		var projectIDs []uint
+		seen := make(map[uint]bool)
+		for _, task := range tasks {
+			if !seen[task.ProjectID] {
+				seen[task.ProjectID] = true
+				projectIDs = append(projectIDs, task.ProjectID)
+			}
+		}
+		projects, err := getter.GetProjectsByIDs(projectIDs)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			logger.FromContext(r.Context()).Error("failed to get projects", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(CalendarResponse{
+				Status: "error",
+				Error:  "failed to get projects",
+			})
+			return
+		}
+		projectNames := make(map[uint]string, len(projects))
+		for _, project := range projects {
+			projectNames[project.ID] = project.Name
+		}
+
+		cal := ical.Calendar{
+			ProdID: "-//task-calendar//API//RU",
+			Name:   developer.Name + " " + developer.LastName,
+			Events: make([]ical.Event, 0, len(tasks)),
+		}
+		for _, task := range tasks {
+			event := ical.Event{
+				UID:         TaskUID(task.ID),
+				Summary:     task.Name,
+				Description: task.DeveloperNote,
+				Start:       task.StartTimestamp,
+				End:         task.EndTimestamp,
+				Created:     task.CreatedAt,
+				// LAST-MODIFIED и SEQUENCE сообщают клиенту, что задача
+				// изменилась и сохраненное событие нужно обновить
+				LastModified: task.ModifiedAt,
+				Sequence:     task.Sequence,
+			}
+			if name := projectNames[task.ProjectID]; name != "" {
+				event.Categories = []string{name}
+			}
+			cal.Events = append(cal.Events, event)
+		}
+
+		w.Header().Set("Content-Type", "text/calendar; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="calendar.ics"`)
+		w.WriteHeader(http.StatusOK)
+		// Заголовки уже отправлены, поэтому ошибку можно только записать в лог
+		if err := cal.Encode(w); err != nil {
+			logger.FromContext(r.Context()).Error("failed to encode calendar", logger.Err(err))
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package calendar
+
+import (
+	"goproject/internal/storage/postgres/entity"
+	"time"
+)
+
+const (
+	ViewDay   = "day"
+	ViewWeek  = "week"
+	ViewMonth = "month"
+)
+
+// CalendarEntry - часть задачи, попадающая в один календарный день
+type CalendarEntry struct {
+	TaskID    uint      `json:"task_id"`
+	ReportID  uint      `json:"report_id"`
+	ProjectID uint      `json:"project_id"`
+	Name      string    `json:"name"`
+	Start     time.Time `json:"start"`
+	End       time.Time `json:"end"`
+	Minutes   int       `json:"minutes"`
+	// Continued - задача началась в один из предыдущих дней
+	Continued bool `json:"continued"`
+	// Continues - задача продолжается в следующем дне
+	Continues bool `json:"continues"`
+}
+
+// CalendarDay - задачи и суммарное время за один день
+type CalendarDay struct {
+	Date         string          `json:"date"`
+	Entries      []CalendarEntry `json:"entries"`
+	TotalMinutes int             `json:"total_minutes"`
+}
+
+// viewRange возвращает границы периода [from, to) для вида календаря.
+// Неделя начинается с понедельника, месяц - с первого числа.
+func viewRange(view string, day time.Time) (time.Time, time.Time, bool) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+
+	switch view {
+	case ViewDay:
+		return start, start.AddDate(0, 0, 1), true
+	case ViewWeek:
+		offset := (int(start.Weekday()) + 6) % 7
+		start = start.AddDate(0, 0, -offset)
+		return start, start.AddDate(0, 0, 7), true
+	case ViewMonth:
+		start = time.Date(start.Year(), start.Month(), 1, 0, 0, 0, 0, start.Location())
+		return start, start.AddDate(0, 1, 0), true
+	}
+
+	return time.Time{}, time.Time{}, false
+}
+
+// layoutDays раскладывает задачи по дням периода [from, to).
+// Задачи, переходящие через полночь, разбиваются на части по дням.
+func layoutDays(tasks []entity.Task, from, to time.Time) []CalendarDay {
+	loc := from.Location()
+
+	var days []CalendarDay
+	for dayStart := from; dayStart.Before(to); dayStart = dayStart.AddDate(0, 0, 1) {
+		dayEnd := dayStart.AddDate(0, 0, 1)
+		day := CalendarDay{
+			Date:    dayStart.Format("2006-01-02"),
+			Entries: []CalendarEntry{},
+		}
+
+		var total time.Duration
+		for _, task := range tasks {
+			start := task.StartTimestamp.In(loc)
+			end := task.EndTimestamp.In(loc)
+			if !start.Before(dayEnd) || !end.After(dayStart) {
+				continue
+			}
+
+			entry := CalendarEntry{
+				TaskID:    task.ID,
+				ReportID:  task.ReportID,
+				ProjectID: task.ProjectID,
+				Name:      task.Name,
+				Start:     start,
+				End:       end,
+			}
+			if start.Before(dayStart) {
+				entry.Start = dayStart
+				entry.Continued = true
+			}
+			if end.After(dayEnd) {
+				entry.End = dayEnd
+				entry.Continues = true
+			}
+
+			duration := entry.End.Sub(entry.Start)
+			entry.Minutes = int(duration.Minutes())
+			total += duration
+
+			day.Entries = append(day.Entries, entry)
+		}
+
+		day.TotalMinutes = int(total.Minutes())
+		days = append(days, day)
+	}
+
+	return days
+}
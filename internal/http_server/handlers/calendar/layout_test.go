@@ -0,0 +1,156 @@
+package calendar
+
+import (
+	"goproject/internal/storage/postgres/entity"
+	"testing"
+	"time"
+)
+
+var msk = time.FixedZone("MSK", 3*60*60)
+
+func at(day, clock string) time.Time {
+	t, err := time.ParseInLocation("2006-01-02 15:04", day+" "+clock, msk)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestViewRange(t *testing.T) {
+	tests := []struct {
+		name     string
+		view     string
+		day      time.Time
+		from, to time.Time
+	}{
+		{"day", ViewDay, at("2026-10-14", "15:30"), at("2026-10-14", "00:00"), at("2026-10-15", "00:00")},
+		// 14 октября 2026 - среда, неделя начинается с понедельника 12-го
+		{"week from wednesday", ViewWeek, at("2026-10-14", "15:30"), at("2026-10-12", "00:00"), at("2026-10-19", "00:00")},
+		{"week from monday", ViewWeek, at("2026-10-12", "00:00"), at("2026-10-12", "00:00"), at("2026-10-19", "00:00")},
+		{"week from sunday", ViewWeek, at("2026-10-18", "23:59"), at("2026-10-12", "00:00"), at("2026-10-19", "00:00")},
+		{"week across months", ViewWeek, at("2026-11-01", "12:00"), at("2026-10-26", "00:00"), at("2026-11-02", "00:00")},
+		{"month", ViewMonth, at("2026-02-17", "08:00"), at("2026-02-01", "00:00"), at("2026-03-01", "00:00")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			from, to, ok := viewRange(tt.view, tt.day)
+			if !ok || !from.Equal(tt.from) || !to.Equal(tt.to) {
+				t.Fatalf("got [%s, %s) %v, want [%s, %s)", from, to, ok, tt.from, tt.to)
+			}
+			if from.Location() != msk {
+				t.Fatalf("location %s, want the location of day", from.Location())
+			}
+		})
+	}
+
+	if _, _, ok := viewRange("year", at("2026-10-14", "00:00")); ok {
+		t.Fatal("unknown view accepted")
+	}
+}
+
+func TestLayoutDays(t *testing.T) {
+	task := func(id uint, start, end time.Time) entity.Task {
+		return entity.Task{ID: id, Name: "task", StartTimestamp: start, EndTimestamp: end}
+	}
+
+	// entry - ожидаемая часть задачи в дне: ID, минуты и флаги переноса
+	type entry struct {
+		id                   uint
+		minutes              int
+		continued, continues bool
+	}
+	type day struct {
+		date    string
+		entries []entry
+		total   int
+	}
+
+	tests := []struct {
+		name  string
+		tasks []entity.Task
+		from  time.Time
+		days  int
+		want  []day
+	}{
+		{
+			name: "across midnight",
+			// Время в UTC раскладывается по дням в часовом поясе периода
+			tasks: []entity.Task{task(1, at("2026-10-12", "22:00").UTC(), at("2026-10-13", "02:30").UTC())},
+			from:  at("2026-10-12", "00:00"),
+			days:  3,
+			want: []day{
+				{"2026-10-12", []entry{{1, 120, false, true}}, 120},
+				{"2026-10-13", []entry{{1, 150, true, false}}, 150},
+				{"2026-10-14", nil, 0},
+			},
+		},
+		{
+			name:  "whole period",
+			tasks: []entity.Task{task(1, at("2026-10-01", "09:00"), at("2026-10-20", "18:00"))},
+			from:  at("2026-10-12", "00:00"),
+			days:  2,
+			want: []day{
+				{"2026-10-12", []entry{{1, 1440, true, true}}, 1440},
+				{"2026-10-13", []entry{{1, 1440, true, true}}, 1440},
+			},
+		},
+		{
+			name: "total minutes",
+			tasks: []entity.Task{
+				task(1, at("2026-10-12", "09:00"), at("2026-10-12", "10:15")),
+				task(2, at("2026-10-12", "11:00"), at("2026-10-12", "11:45")),
+				task(3, at("2026-10-11", "23:00"), at("2026-10-12", "01:00")),
+			},
+			from: at("2026-10-12", "00:00"),
+			days: 1,
+			want: []day{
+				{"2026-10-12", []entry{{1, 75, false, false}, {2, 45, false, false}, {3, 60, true, false}}, 180},
+			},
+		},
+		{
+			name: "boundaries",
+			tasks: []entity.Task{
+				// Заканчивается ровно в полночь: в следующий день не попадает
+				task(1, at("2026-10-12", "23:00"), at("2026-10-13", "00:00")),
+				// Вне периода
+				task(2, at("2026-10-14", "09:00"), at("2026-10-14", "10:00")),
+			},
+			from: at("2026-10-12", "00:00"),
+			days: 2,
+			want: []day{
+				{"2026-10-12", []entry{{1, 60, false, false}}, 60},
+				{"2026-10-13", nil, 0},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			days := layoutDays(tt.tasks, tt.from, tt.from.AddDate(0, 0, tt.days))
+			if len(days) != len(tt.want) {
+				t.Fatalf("got %d days, want %d", len(days), len(tt.want))
+			}
+			for i, want := range tt.want {
+				got := days[i]
+				if got.Date != want.date || got.TotalMinutes != want.total || len(got.Entries) != len(want.entries) {
+					t.Fatalf("day %d: %s, %d minutes, %d entries; want %s, %d, %d",
+						i, got.Date, got.TotalMinutes, len(got.Entries), want.date, want.total, len(want.entries))
+				}
+				for j, w := range want.entries {
+					e := got.Entries[j]
+					if e.TaskID != w.id || e.Minutes != w.minutes || e.Continued != w.continued || e.Continues != w.continues {
+						t.Errorf("%s entry %d: %+v, want %+v", want.date, j, e, w)
+					}
+					if e.Start.Location() != msk || e.End.Sub(e.Start) != time.Duration(w.minutes)*time.Minute {
+						t.Errorf("%s entry %d: [%s, %s)", want.date, j, e.Start, e.End)
+					}
+				}
+				// Пустой день отдается как [], а не null
+				if got.Entries == nil {
+					t.Errorf("%s: nil entries", got.Date)
+				}
+			}
+		})
+	}
+}
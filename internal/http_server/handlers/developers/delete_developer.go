@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/router"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type DeveloperDeleter interface {
+	SoftDeleteDeveloper(actor entity.Actor, uid uuid.UUID) error
+}
+
+// NewDeleteDeveloperHandler помечает разработчика удаленным. Его отчеты и
+// задачи сохраняются; восстановить можно через POST /developers/{id}/restore.
+func NewDeleteDeveloperHandler(deleter DeveloperDeleter, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := pol.ManageDevelopers(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(DeveloperResponse{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		developerID, err := uuid.Parse(router.Param(r, "id"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(DeveloperResponse{
+				Status: "error",
+				Error:  "invalid developer ID format",
+			})
+			return
+		}
+
+		if err := deleter.SoftDeleteDeveloper(auth.Actor(r.Context()), developerID); err != nil {
+			if errors.Is(err, er.ErrDeveloperNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(DeveloperResponse{
+					Status: "error",
+					Error:  "developer not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to delete developer", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(DeveloperResponse{
+				Status: "error",
+				Error:  "failed to delete developer",
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(DeveloperResponse{
+			Status:      "ok",
+			DeveloperID: developerID,
+		})
+	}
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/http_server/etag"
+	"goproject/internal/http_server/router"
+	"goproject/internal/http_server/validate"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+type DeveloperResponseGet struct {
+	Status    string                `json:"status"`
+	Error     string                `json:"error,omitempty"`
+	Fields    []validate.FieldError `json:"fields,omitempty"`
+	Developer *entity.Developer     `json:"developer,omitempty"`
+}
+
+type DeveloperGetter interface {
+	GetDeveloperByID(uid uuid.UUID) (entity.Developer, error)
+}
+
+// NewGetDeveloperByIdHandler отдает разработчика по ID. Удаленный разработчик
+// отдается только с include_deleted=true.
+func NewGetDeveloperByIdHandler(getter DeveloperGetter, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		developerID, err := uuid.Parse(router.Param(r, "id"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  "invalid developer ID format",
+			})
+			return
+		}
+
+		if err := pol.ReadDeveloper(r.Context(), developerID); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		includeDeleted := false
+		if v := r.URL.Query().Get("include_deleted"); v != "" {
+			if includeDeleted, err = strconv.ParseBool(v); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(DeveloperResponseGet{
+					Status: "error",
+					Error:  "include_deleted must be true or false",
+				})
+				return
+			}
+		}
+
+		developer, err := getter.GetDeveloperByID(developerID)
+		if err == nil && developer.DeletedAt != nil && !includeDeleted {
+			err = er.ErrDeveloperNotFound
+		}
+		if err != nil {
+			if errors.Is(err, er.ErrDeveloperNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(DeveloperResponseGet{
+					Status: "error",
+					Error:  "developer not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get developer", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  "failed to get developer",
+			})
+			return
+		}
+
+		etag.Set(w, developer.ModifiedAt)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(DeveloperResponseGet{
+			Status:    "ok",
+			Developer: &developer,
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package handlers
+
+import (
+	"encoding/json"
+	"goproject/internal/http_server/listparams"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+)
+
+type DeveloperResponseGetAll struct {
+	Status     string             `json:"status"`
+	Error      string             `json:"error,omitempty"`
+	Developers []entity.Developer `json:"developers,omitempty"`
+	NextCursor string             `json:"next_cursor,omitempty"`
+}
+
+type DeveloperGetterGetAll interface {
+	GetDevelopers(params er.ListParams) ([]entity.Developer, string, error)
+}
+
+// NewGetAllDeveloperHandler отдает разработчиков постранично. Параметры: limit,
+// cursor, sort (created_at, name, last_name; "-" - по убыванию), project,
+// from, to, include_deleted.
+func NewGetAllDeveloperHandler(getter DeveloperGetterGetAll, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		params, err := listparams.Parse(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(DeveloperResponseGetAll{
+				Status: "error",
+				Error:  err.Error(),
+			})
+			return
+		}
+
+		if err := pol.ListDevelopers(r.Context(), &params); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(DeveloperResponseGetAll{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		developers, next, err := getter.GetDevelopers(params)
+		if err != nil {
+			if listparams.IsBadRequest(err) {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(DeveloperResponseGetAll{
+					Status: "error",
+					Error:  "invalid cursor or sort",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get developers", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(DeveloperResponseGetAll{
+				Status: "error",
+				Error:  "failed to get developers",
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(DeveloperResponseGetAll{
+			Status:     "ok",
+			Developers: developers,
+			NextCursor: next,
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/etag"
+	"goproject/internal/http_server/mergepatch"
+	"goproject/internal/http_server/router"
+	"goproject/internal/http_server/validate"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeveloperPatcher interface {
+	GetDeveloperByID(uid uuid.UUID) (entity.Developer, error)
+	PatchDeveloper(actor entity.Actor, uid uuid.UUID, patch entity.DeveloperPatch, version time.Time) error
+}
+
+// NewPatchDeveloperHandler частично обновляет разработчика по JSON Merge
+// Patch. Роль, сброшенная в null, остается прежней, как пустая роль в PUT.
+func NewPatchDeveloperHandler(patcher DeveloperPatcher, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := pol.ManageDevelopers(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		developerID, err := uuid.Parse(router.Param(r, "id"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  "invalid developer ID format",
+			})
+			return
+		}
+
+		if err := mergepatch.CheckContentType(r); err != nil {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  err.Error(),
+			})
+			return
+		}
+
+		ifMatch := r.Header.Get("If-Match")
+		if ifMatch == "" {
+			w.WriteHeader(http.StatusPreconditionRequired)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  "If-Match header is required",
+			})
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  "invalid request body",
+			})
+			return
+		}
+
+		existing, err := patcher.GetDeveloperByID(developerID)
+		if err == nil && existing.DeletedAt != nil {
+			err = er.ErrDeveloperNotFound
+		}
+		if err != nil {
+			if errors.Is(err, er.ErrDeveloperNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(DeveloperResponseGet{
+					Status: "error",
+					Error:  "developer not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get developer", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  "failed to get developer",
+			})
+			return
+		}
+		if !etag.Match(ifMatch, existing.ModifiedAt) {
+			writeVersionConflict(w, existing)
+			return
+		}
+
+		req := DeveloperUpdateRequest{
+			Name:     existing.Name,
+			LastName: existing.LastName,
+			Role:     existing.Role,
+		}
+		if err := mergepatch.Apply(&req, body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  err.Error(),
+			})
+			return
+		}
+
+		if fields := validate.Struct(req); fields != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  validate.Message,
+				Fields: fields,
+			})
+			return
+		}
+
+		var patch entity.DeveloperPatch
+		if req.Name != existing.Name {
+			patch.Name = entity.Change(req.Name)
+		}
+		if req.LastName != existing.LastName {
+			patch.LastName = entity.Change(req.LastName)
+		}
+		if req.Role != "" && req.Role != existing.Role {
+			patch.Role = entity.Change(req.Role)
+		}
+
+		if err := patcher.PatchDeveloper(auth.Actor(r.Context()), developerID, patch, existing.ModifiedAt); err != nil {
+			if errors.Is(err, er.ErrVersionMismatch) {
+				if current, err := patcher.GetDeveloperByID(developerID); err == nil {
+					writeVersionConflict(w, current)
+					return
+				}
+			}
+			if errors.Is(err, er.ErrDeveloperNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(DeveloperResponseGet{
+					Status: "error",
+					Error:  "developer not found",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrInvalidDeveloperData) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(DeveloperResponseGet{
+					Status: "error",
+					Error:  "invalid developer data",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrUniqueViolation) {
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(DeveloperResponseGet{
+					Status: "error",
+					Error:  "developer already exists",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to patch developer", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  "failed to patch developer",
+			})
+			return
+		}
+
+		updated, err := patcher.GetDeveloperByID(developerID)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("failed to get developer", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  "failed to get developer",
+			})
+			return
+		}
+
+		etag.Set(w, updated.ModifiedAt)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(DeveloperResponseGet{
+			Status:    "ok",
+			Developer: &updated,
+		})
+	}
+}
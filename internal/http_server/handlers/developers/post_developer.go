@@ -3,6 +3,10 @@ package handlers
 import (
 	"encoding/json"
 	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/validate"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
 	er "goproject/internal/storage"
 	"goproject/internal/storage/postgres/entity"
 	"net/http"
@@ -12,30 +16,33 @@ import (
 )
 
 type DeveloperRequest struct {
-	Name      string     `json:"name"`
-	LastName  string     `json:"last_name"`
+	Name      string     `json:"name" validate:"required,max=255"`
+	LastName  string     `json:"last_name" validate:"required,max=255"`
+	Role      string     `json:"role,omitempty" validate:"omitempty,oneof=developer manager admin"`
 	DeletedAt *time.Time `json:"deleted_at,omitempty"`
 }
 
 type DeveloperResponse struct {
-	Status      string    `json:"status"`
-	Error       string    `json:"error,omitempty"`
-	DeveloperID uuid.UUID `json:"developer_id,omitempty"`
+	Status      string                `json:"status"`
+	Error       string                `json:"error,omitempty"`
+	Fields      []validate.FieldError `json:"fields,omitempty"`
+	DeveloperID uuid.UUID             `json:"developer_id,omitempty"`
 }
 
 type DeveloperSaver interface {
-	SaveDeveloper(developer entity.Developer) (uuid.UUID, error)
+	SaveDeveloper(actor entity.Actor, developer entity.Developer) (uuid.UUID, error)
 }
 
-func NewDeveloperHandler(saver DeveloperSaver) http.HandlerFunc {
+func NewDeveloperHandler(saver DeveloperSaver, pol *policy.Policy) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+		if err := pol.ManageDevelopers(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
 			json.NewEncoder(w).Encode(DeveloperResponse{
 				Status: "error",
-				Error:  "method not allowed",
+				Error:  msg,
 			})
 			return
 		}
@@ -50,11 +57,12 @@ func NewDeveloperHandler(saver DeveloperSaver) http.HandlerFunc {
 			return
 		}
 
-		if req.Name == "" || req.LastName == "" {
-			w.WriteHeader(http.StatusBadRequest)
+		if fields := validate.Struct(req); fields != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
 			json.NewEncoder(w).Encode(DeveloperResponse{
 				Status: "error",
-				Error:  "firstname and last_name are required",
+				Error:  validate.Message,
+				Fields: fields,
 			})
 			return
 		}
@@ -62,20 +70,30 @@ func NewDeveloperHandler(saver DeveloperSaver) http.HandlerFunc {
 		developer := entity.Developer{
 			Name:      req.Name,
 			LastName:  req.LastName,
+			Role:      req.Role,
 			DeletedAt: req.DeletedAt,
 		}
 
-		developerID, err := saver.SaveDeveloper(developer)
+		developerID, err := saver.SaveDeveloper(auth.Actor(r.Context()), developer)
 		if err != nil {
 			if errors.Is(err, er.ErrInvalidDeveloperData) {
-				w.WriteHeader(http.StatusBadRequest)
+				w.WriteHeader(http.StatusUnprocessableEntity)
 				json.NewEncoder(w).Encode(DeveloperResponse{
 					Status: "error",
 					Error:  "invalid developer data",
 				})
 				return
 			}
+			if errors.Is(err, er.ErrUniqueViolation) {
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(DeveloperResponse{
+					Status: "error",
+					Error:  "developer already exists",
+				})
+				return
+			}
 
+			logger.FromContext(r.Context()).Error("failed to save developer", logger.Err(err))
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(DeveloperResponse{
 				Status: "error",
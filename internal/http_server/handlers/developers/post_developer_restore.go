@@ -0,0 +1,70 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/router"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type DeveloperRestorer interface {
+	RestoreDeveloper(actor entity.Actor, uid uuid.UUID) error
+}
+
+// NewRestoreDeveloperHandler снимает с разработчика пометку об удалении
+func NewRestoreDeveloperHandler(restorer DeveloperRestorer, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := pol.ManageDevelopers(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(DeveloperResponse{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		developerID, err := uuid.Parse(router.Param(r, "id"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(DeveloperResponse{
+				Status: "error",
+				Error:  "invalid developer ID format",
+			})
+			return
+		}
+
+		if err := restorer.RestoreDeveloper(auth.Actor(r.Context()), developerID); err != nil {
+			if errors.Is(err, er.ErrDeveloperNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(DeveloperResponse{
+					Status: "error",
+					Error:  "developer not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to restore developer", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(DeveloperResponse{
+				Status: "error",
+				Error:  "failed to restore developer",
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(DeveloperResponse{
+			Status:      "ok",
+			DeveloperID: developerID,
+		})
+	}
+}
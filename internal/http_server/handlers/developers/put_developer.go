@@ -0,0 +1,191 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/etag"
+	"goproject/internal/http_server/router"
+	"goproject/internal/http_server/validate"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type DeveloperUpdateRequest struct {
+	Name     string `json:"name" validate:"required,max=255"`
+	LastName string `json:"last_name" validate:"required,max=255"`
+	Role     string `json:"role,omitempty" validate:"omitempty,oneof=developer manager admin"`
+}
+
+type DeveloperUpdater interface {
+	GetDeveloperByID(uid uuid.UUID) (entity.Developer, error)
+	UpdateDeveloper(actor entity.Actor, uid uuid.UUID, developer entity.Developer, version time.Time) error
+}
+
+// NewUpdateDeveloperHandler обновляет имя, фамилию и роль разработчика.
+// Пустая роль оставляет текущую. Удаленного разработчика нужно сначала
+// восстановить. Запрос должен содержать If-Match с ETag разработчика из GET,
+// иначе изменение другого администратора может быть перезаписано.
+func NewUpdateDeveloperHandler(updater DeveloperUpdater, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := pol.ManageDevelopers(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		developerID, err := uuid.Parse(router.Param(r, "id"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  "invalid developer ID format",
+			})
+			return
+		}
+
+		ifMatch := r.Header.Get("If-Match")
+		if ifMatch == "" {
+			w.WriteHeader(http.StatusPreconditionRequired)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  "If-Match header is required",
+			})
+			return
+		}
+
+		var req DeveloperUpdateRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  "invalid request body",
+			})
+			return
+		}
+
+		if fields := validate.Struct(req); fields != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  validate.Message,
+				Fields: fields,
+			})
+			return
+		}
+
+		existing, err := updater.GetDeveloperByID(developerID)
+		if err == nil && existing.DeletedAt != nil {
+			err = er.ErrDeveloperNotFound
+		}
+		if err != nil {
+			if errors.Is(err, er.ErrDeveloperNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(DeveloperResponseGet{
+					Status: "error",
+					Error:  "developer not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get developer", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  "failed to get developer",
+			})
+			return
+		}
+		if !etag.Match(ifMatch, existing.ModifiedAt) {
+			writeVersionConflict(w, existing)
+			return
+		}
+
+		developer := entity.Developer{
+			Name:     req.Name,
+			LastName: req.LastName,
+			Role:     req.Role,
+		}
+		if err := updater.UpdateDeveloper(auth.Actor(r.Context()), developerID, developer, existing.ModifiedAt); err != nil {
+			if errors.Is(err, er.ErrVersionMismatch) {
+				// Разработчика изменили между чтением и записью
+				if current, err := updater.GetDeveloperByID(developerID); err == nil {
+					writeVersionConflict(w, current)
+					return
+				}
+			}
+			if errors.Is(err, er.ErrDeveloperNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(DeveloperResponseGet{
+					Status: "error",
+					Error:  "developer not found",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrInvalidDeveloperData) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(DeveloperResponseGet{
+					Status: "error",
+					Error:  "invalid developer data",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrUniqueViolation) {
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(DeveloperResponseGet{
+					Status: "error",
+					Error:  "developer already exists",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to update developer", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  "failed to update developer",
+			})
+			return
+		}
+
+		updated, err := updater.GetDeveloperByID(developerID)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("failed to get developer", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(DeveloperResponseGet{
+				Status: "error",
+				Error:  "failed to get developer",
+			})
+			return
+		}
+
+		etag.Set(w, updated.ModifiedAt)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(DeveloperResponseGet{
+			Status:    "ok",
+			Developer: &updated,
+		})
+	}
+}
+
+// writeVersionConflict отвечает 412 с текущей версией разработчика, чтобы
+// клиент мог повторить изменение поверх нее
+func writeVersionConflict(w http.ResponseWriter, current entity.Developer) {
+	etag.Set(w, current.ModifiedAt)
+	w.WriteHeader(http.StatusPreconditionFailed)
+	json.NewEncoder(w).Encode(DeveloperResponseGet{
+		Status:    "error",
+		Error:     "developer was modified, retry with the current version",
+		Developer: &current,
+	})
+}
@@ -0,0 +1,36 @@
+// Package docs публикует документ OpenAPI и страницу документации API;
+// эндпоинты доступны без аутентификации.
+package docs
+
+import (
+	_ "embed"
+	"net/http"
+)
+
+// Spec - опубликованный документ OpenAPI. Файл генерируется тестом пакета
+// httpserver (go test ./internal/http_server -update) и не правится вручную.
+//
+//go:embed openapi.json
+var Spec []byte
+
+//go:embed index.html
+var page []byte
+
+// NewSpecHandler отдает документ OpenAPI
+func NewSpecHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		w.Write(Spec)
+	}
+}
+
+// NewPageHandler отдает страницу, которая загружает /openapi.json и
+// показывает маршруты и схемы. Страница не зависит от внешних ресурсов.
+func NewPageHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write(page)
+	}
+}
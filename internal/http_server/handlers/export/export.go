@@ -0,0 +1,185 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"goproject/internal/http_server/listparams"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	// flushEvery - сколько строк копится в буфере перед отправкой клиенту
+	flushEvery = 500
+	// writeTimeout - срок на отправку очередной порции строк. WriteTimeout
+	// сервера рассчитан на обычные ответы и оборвал бы длинную выгрузку,
+	// поэтому срок продлевается после каждой порции.
+	writeTimeout = 30 * time.Second
+)
+
+// ExportResponse - тело ошибки; сама выгрузка отдается как CSV или NDJSON
+type ExportResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// exportRow - строка выгрузки: в NDJSON кодируется как JSON, в CSV
+// записывается значениями колонок
+type exportRow interface {
+	record() []string
+}
+
+// exportFunc читает записи из хранилища и передает их в emit по одной
+type exportFunc func(ctx context.Context, params er.ListParams, emit func(exportRow) error) error
+
+// csvCell защищает текст, который вводят пользователи, от выполнения как
+// формулы в Excel и LibreOffice: значение, начинающееся с =, +, -, @,
+// табуляции или перевода строки, получает префикс '
+func csvCell(s string) string {
+	if s != "" && strings.ContainsRune("=+-@\t\r", rune(s[0])) {
+		return "'" + s
+	}
+	return s
+}
+
+// newExportHandler отдает выгрузку name в CSV или NDJSON (format=csv|ndjson)
+// с фильтрами listparams и видимостью списков ScopeList. Строки пишутся
+// клиенту по мере чтения из хранилища, так что выгрузка любого размера
+// занимает постоянную память. Если хранилище отказало до первой строки,
+// ответ - JSON с ошибкой; после начала выгрузки соединение обрывается,
+// чтобы клиент не принял неполный файл за целый.
+func newExportHandler(name string, header []string, export exportFunc, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		params, err := listparams.Parse(r)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ExportResponse{
+				Status: "error",
+				Error:  err.Error(),
+			})
+			return
+		}
+
+		format := r.URL.Query().Get("format")
+		if format == "" {
+			format = "csv"
+		}
+		if format != "csv" && format != "ndjson" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ExportResponse{
+				Status: "error",
+				Error:  "format must be csv or ndjson",
+			})
+			return
+		}
+
+		if err := pol.ScopeList(r.Context(), &params); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(ExportResponse{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		rc := http.NewResponseController(w)
+		// ErrNotSupported возможен только вне http.Server, где срока записи нет
+		extendDeadline := func() { _ = rc.SetWriteDeadline(time.Now().Add(writeTimeout)) }
+
+		bw := bufio.NewWriterSize(w, 64<<10)
+		// csv.Writer пишет прямо в bw: bufio.NewWriter не оборачивает
+		// буфер достаточного размера повторно
+		cw := csv.NewWriter(bw)
+		var encode func(exportRow) error
+		switch format {
+		case "csv":
+			encode = func(row exportRow) error {
+				cw.Write(row.record())
+				return cw.Error()
+			}
+		case "ndjson":
+			enc := json.NewEncoder(bw)
+			encode = func(row exportRow) error {
+				return enc.Encode(row)
+			}
+		}
+
+		started := false
+		start := func() error {
+			started = true
+			extendDeadline()
+			if format == "csv" {
+				w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+				w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.csv"`)
+				w.WriteHeader(http.StatusOK)
+				return cw.Write(header)
+			}
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.ndjson"`)
+			w.WriteHeader(http.StatusOK)
+			return nil
+		}
+		flush := func() error {
+			if err := bw.Flush(); err != nil {
+				return err
+			}
+			if err := rc.Flush(); err != nil {
+				return err
+			}
+			extendDeadline()
+			return nil
+		}
+
+		count := 0
+		err = export(r.Context(), params, func(row exportRow) error {
+			if !started {
+				if err := start(); err != nil {
+					return err
+				}
+			}
+			if err := encode(row); err != nil {
+				return err
+			}
+			count++
+			if count%flushEvery == 0 {
+				return flush()
+			}
+			return nil
+		})
+		if err == nil && !started {
+			err = start()
+		}
+		if err == nil {
+			err = flush()
+		}
+		if err == nil {
+			return
+		}
+
+		if !started {
+			logger.FromContext(r.Context()).Error("failed to export "+name, logger.Err(err))
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ExportResponse{
+				Status: "error",
+				Error:  "failed to export " + name,
+			})
+			return
+		}
+		// Клиент мог сам закрыть соединение, тогда ошибку не пишем
+		if r.Context().Err() == nil {
+			logger.FromContext(r.Context()).Error(name+" export interrupted", logger.Err(err))
+		}
+		panic(http.ErrAbortHandler)
+	}
+}
@@ -0,0 +1,91 @@
+package export
+
+import (
+	"context"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ReportExporter interface {
+	ExportReports(ctx context.Context, params er.ListParams, fn func(entity.ReportExport) error) error
+}
+
+// ReportExportRow - строка выгрузки отчетов с итогами по задачам. Ключи
+// NDJSON совпадают с заголовками колонок CSV.
+type ReportExportRow struct {
+	ReportID          uint       `json:"report_id"`
+	DeveloperID       uuid.UUID  `json:"developer_id"`
+	DeveloperName     string     `json:"developer_name"`
+	DeveloperLastName string     `json:"developer_last_name"`
+	CreatedAt         time.Time  `json:"created_at"`
+	ApprovedAt        *time.Time `json:"approved_at"`
+	ApprovedBy        *uuid.UUID `json:"approved_by"`
+	TaskCount         int        `json:"task_count"`
+	EstimatePlaned    int        `json:"estimate_planed"`
+	EstimateProgress  int        `json:"estimate_progress"`
+	FirstStart        *time.Time `json:"first_start"`
+	LastEnd           *time.Time `json:"last_end"`
+}
+
+var reportCSVHeader = []string{
+	"report_id", "developer_id", "developer_name", "developer_last_name",
+	"created_at", "approved_at", "approved_by",
+	"task_count", "estimate_planed", "estimate_progress", "first_start", "last_end",
+}
+
+func newReportExportRow(r entity.ReportExport) ReportExportRow {
+	return ReportExportRow{
+		ReportID:          r.ID,
+		DeveloperID:       r.DeveloperID,
+		DeveloperName:     r.DeveloperName,
+		DeveloperLastName: r.DeveloperLastName,
+		CreatedAt:         r.CreatedAt,
+		ApprovedAt:        r.ApprovedAt,
+		ApprovedBy:        r.ApprovedBy,
+		TaskCount:         r.TaskCount,
+		EstimatePlaned:    r.EstimatePlaned,
+		EstimateProgress:  r.EstimateProgress,
+		FirstStart:        r.FirstStart,
+		LastEnd:           r.LastEnd,
+	}
+}
+
+// record - значения колонок в порядке reportCSVHeader
+func (row ReportExportRow) record() []string {
+	var approvedBy string
+	if row.ApprovedBy != nil {
+		approvedBy = row.ApprovedBy.String()
+	}
+	return []string{
+		strconv.FormatUint(uint64(row.ReportID), 10),
+		row.DeveloperID.String(),
+		csvCell(row.DeveloperName),
+		csvCell(row.DeveloperLastName),
+		row.CreatedAt.Format(time.RFC3339),
+		formatOptionalTime(row.ApprovedAt),
+		approvedBy,
+		strconv.Itoa(row.TaskCount),
+		strconv.Itoa(row.EstimatePlaned),
+		strconv.Itoa(row.EstimateProgress),
+		formatOptionalTime(row.FirstStart),
+		formatOptionalTime(row.LastEnd),
+	}
+}
+
+// NewExportReportsHandler выгружает отчеты с именами разработчиков, числом
+// задач и суммами оценок. Фильтры: from, to (по времени создания отчета),
+// project, developer; видимость отчетов та же, что в GET /reports.
+func NewExportReportsHandler(exporter ReportExporter, pol *policy.Policy) http.HandlerFunc {
+	export := func(ctx context.Context, params er.ListParams, emit func(exportRow) error) error {
+		return exporter.ExportReports(ctx, params, func(r entity.ReportExport) error {
+			return emit(newReportExportRow(r))
+		})
+	}
+	return newExportHandler("reports", reportCSVHeader, export, pol)
+}
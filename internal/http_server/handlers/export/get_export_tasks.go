@@ -0,0 +1,100 @@
+package export
+
+import (
+	"context"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type TaskExporter interface {
+	ExportTasks(ctx context.Context, params er.ListParams, fn func(entity.TaskExport) error) error
+}
+
+// TaskExportRow - строка выгрузки. Ключи NDJSON совпадают с заголовками
+// колонок CSV.
+type TaskExportRow struct {
+	TaskID            uint       `json:"task_id"`
+	ReportID          uint       `json:"report_id"`
+	DeveloperID       uuid.UUID  `json:"developer_id"`
+	DeveloperName     string     `json:"developer_name"`
+	DeveloperLastName string     `json:"developer_last_name"`
+	ProjectID         uint       `json:"project_id"`
+	ProjectName       string     `json:"project_name"`
+	Name              string     `json:"name"`
+	DeveloperNote     string     `json:"developer_note"`
+	EstimatePlaned    int        `json:"estimate_planed"`
+	EstimateProgress  int        `json:"estimate_progress"`
+	StartTimestamp    time.Time  `json:"start_timestamp"`
+	EndTimestamp      time.Time  `json:"end_timestamp"`
+	ApprovedAt        *time.Time `json:"approved_at"`
+}
+
+var taskCSVHeader = []string{
+	"task_id", "report_id", "developer_id", "developer_name", "developer_last_name",
+	"project_id", "project_name", "name", "developer_note",
+	"estimate_planed", "estimate_progress", "start_timestamp", "end_timestamp", "approved_at",
+}
+
+func newTaskExportRow(t entity.TaskExport) TaskExportRow {
+	return TaskExportRow{
+		TaskID:            t.ID,
+		ReportID:          t.ReportID,
+		DeveloperID:       t.DeveloperID,
+		DeveloperName:     t.DeveloperName,
+		DeveloperLastName: t.DeveloperLastName,
+		ProjectID:         t.ProjectID,
+		ProjectName:       t.ProjectName,
+		Name:              t.Name,
+		DeveloperNote:     t.DeveloperNote,
+		EstimatePlaned:    t.EstimatePlaned,
+		EstimateProgress:  t.EstimateProgress,
+		StartTimestamp:    t.StartTimestamp,
+		EndTimestamp:      t.EndTimestamp,
+		ApprovedAt:        t.ApprovedAt,
+	}
+}
+
+// record - значения колонок в порядке taskCSVHeader
+func (row TaskExportRow) record() []string {
+	return []string{
+		strconv.FormatUint(uint64(row.TaskID), 10),
+		strconv.FormatUint(uint64(row.ReportID), 10),
+		row.DeveloperID.String(),
+		csvCell(row.DeveloperName),
+		csvCell(row.DeveloperLastName),
+		strconv.FormatUint(uint64(row.ProjectID), 10),
+		csvCell(row.ProjectName),
+		csvCell(row.Name),
+		csvCell(row.DeveloperNote),
+		strconv.Itoa(row.EstimatePlaned),
+		strconv.Itoa(row.EstimateProgress),
+		row.StartTimestamp.Format(time.RFC3339),
+		row.EndTimestamp.Format(time.RFC3339),
+		formatOptionalTime(row.ApprovedAt),
+	}
+}
+
+// NewExportTasksHandler выгружает задачи с именами разработчиков и проектов.
+// Фильтры: from, to (по времени начала), project, developer; видимость
+// задач та же, что в GET /tasks.
+func NewExportTasksHandler(exporter TaskExporter, pol *policy.Policy) http.HandlerFunc {
+	export := func(ctx context.Context, params er.ListParams, emit func(exportRow) error) error {
+		return exporter.ExportTasks(ctx, params, func(t entity.TaskExport) error {
+			return emit(newTaskExportRow(t))
+		})
+	}
+	return newExportHandler("tasks", taskCSVHeader, export, pol)
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+	return t.Format(time.RFC3339)
+}
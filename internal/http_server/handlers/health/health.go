@@ -0,0 +1,55 @@
+// Package health отдает проверки живости и готовности сервиса для
+// оркестратора; эндпоинты доступны без аутентификации.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+)
+
+// pingTimeout ограничивает проверку хранилища, чтобы /readyz не зависал
+// вместе с базой данных
+const pingTimeout = 2 * time.Second
+
+type HealthResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// NewHealthzHandler сообщает, что процесс жив и обрабатывает запросы
+func NewHealthzHandler() http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(HealthResponse{Status: "ok"})
+	}
+}
+
+// NewReadyzHandler сообщает, что сервис готов принимать трафик: хранилище
+// отвечает на ping
+func NewReadyzHandler(pinger Pinger) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		ctx, cancel := context.WithTimeout(r.Context(), pingTimeout)
+		defer cancel()
+
+		if err := pinger.Ping(ctx); err != nil {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			json.NewEncoder(w).Encode(HealthResponse{
+				Status: "error",
+				Error:  "storage is unavailable",
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(HealthResponse{Status: "ok"})
+	}
+}
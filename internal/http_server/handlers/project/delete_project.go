@@ -0,0 +1,71 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/router"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ProjectDeleter interface {
+	DeleteProject(actor entity.Actor, ID uint) error
+}
+
+// NewDeleteProjectHandler помечает проект удаленным. Задачи проекта сохраняются;
+// восстановить проект можно через POST /projects/{id}/restore.
+func NewDeleteProjectHandler(deleter ProjectDeleter, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := pol.ManageProjects(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		projectID, err := strconv.ParseUint(router.Param(r, "id"), 10, 32)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  "invalid project ID format",
+			})
+			return
+		}
+
+		if err := deleter.DeleteProject(auth.Actor(r.Context()), uint(projectID)); err != nil {
+			if errors.Is(err, er.ErrProjectNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(ProjectResponse{
+					Status: "error",
+					Error:  "project not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to delete project", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  "failed to delete project",
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(ProjectResponse{
+			Status:    "success",
+			Timestamp: time.Now(),
+		})
+	}
+}
@@ -2,60 +2,74 @@ package project
 
 import (
 	"encoding/json"
-	"errors"
+	"goproject/internal/http_server/listparams"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
 	er "goproject/internal/storage"
 	"goproject/internal/storage/postgres/entity"
 	"net/http"
 )
 
-type ProjectRequestGetAll struct {
-	ID uint `json:"id"`
-}
 type ProjectResponseGetAll struct {
-	Status  string         `json:"status"`
-	Error   string         `json:"error,omitempty"`
-	Project entity.Project `json:"project,omitempty"`
+	Status     string           `json:"status"`
+	Error      string           `json:"error,omitempty"`
+	Projects   []entity.Project `json:"projects,omitempty"`
+	NextCursor string           `json:"next_cursor,omitempty"`
 }
 
 type ProjectGetterGetAll interface {
-	GetProject() (entity.Project, error)
+	GetProject(params er.ListParams) ([]entity.Project, string, error)
 }
 
-func NewGetAllReportHandler(getter ProjectGetterGetAll) http.HandlerFunc {
+// NewGetAllProjectHandler отдает проекты постранично. Параметры: limit, cursor,
+// sort (created_at, name, id; "-" - по убыванию), developer, from, to.
+func NewGetAllProjectHandler(getter ProjectGetterGetAll, pol *policy.Policy) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+		if err := pol.ReadProjects(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(ProjectResponseGetAll{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		params, err := listparams.Parse(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(ProjectResponseGetAll{
 				Status: "error",
-				Error:  "method not allowed",
+				Error:  err.Error(),
 			})
 			return
 		}
 
-		project, err := getter.GetProject()
+		projects, next, err := getter.GetProject(params)
 		if err != nil {
-			if errors.Is(err, er.ErrReportNotFound) {
-				w.WriteHeader(http.StatusNotFound)
+			if listparams.IsBadRequest(err) {
 				w.WriteHeader(http.StatusBadRequest)
 				json.NewEncoder(w).Encode(ProjectResponseGetAll{
 					Status: "error",
-					Error:  "invalid request_id format",
+					Error:  "invalid cursor or sort",
 				})
 				return
 			}
+			logger.FromContext(r.Context()).Error("failed to get projects", logger.Err(err))
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(ProjectResponseGetAll{
 				Status: "error",
-				Error:  "failed to get report",
+				Error:  "failed to get projects",
 			})
 			return
 		}
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(ProjectResponseGetAll{
-			Status:  "ok",
-			Project: project,
+			Status:     "ok",
+			Projects:   projects,
+			NextCursor: next,
 		})
 	}
 }
@@ -3,53 +3,45 @@ package project
 import (
 	"encoding/json"
 	"errors"
+	"goproject/internal/http_server/etag"
+	"goproject/internal/http_server/router"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
 	er "goproject/internal/storage"
 	"goproject/internal/storage/postgres/entity"
 	"net/http"
 	"strconv"
-	"strings"
 )
 
-// ReportResponseGet - структура ответа для получения отчета
+// ProjectResponseGet - структура ответа для получения проекта
 type ProjectResponseGet struct {
 	Status  string         `json:"status"`
 	Error   string         `json:"error,omitempty"`
 	Project entity.Project `json:"project,omitempty"`
 }
 
-// ReportGetter - интерфейс для получения отчета
+// ProjectGetter - интерфейс для получения проекта
 type ProjectGetter interface {
-	GetProjectById(id uint) (entity.Project, error)
+	GetProjectByID(ID uint) (entity.Project, error)
 }
 
-// NewGetReportByIdHandler создает обработчик для получения отчета по ID
-func NewGetProjectByIdHandler(getter ProjectGetter) http.HandlerFunc {
+// NewGetProjectByIdHandler создает обработчик для получения проекта по ID
+func NewGetProjectByIdHandler(getter ProjectGetter, pol *policy.Policy) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		// Проверяем метод запроса
-		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+		if err := pol.ReadProjects(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
 			json.NewEncoder(w).Encode(ProjectResponseGet{
 				Status: "error",
-				Error:  "method not allowed",
+				Error:  msg,
 			})
 			return
 		}
 
-		// Извлекаем ID отчета из URL
-		projectIDStr := strings.Split(r.URL.Path, "/")
-		if len(projectIDStr) < 3 {
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(ProjectResponseGet{
-				Status: "error",
-				Error:  "project ID is required",
-			})
-			return
-		}
-
-		// Парсим UUID
-		projectID, err := strconv.ParseUint(projectIDStr[2], 10, 32)
+		// Извлекаем ID проекта из URL
+		projectID, err := strconv.ParseUint(router.Param(r, "id"), 10, 32)
 		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(ProjectResponseGet{
@@ -59,10 +51,10 @@ func NewGetProjectByIdHandler(getter ProjectGetter) http.HandlerFunc {
 			return
 		}
 
-		// Получаем отчет из хранилища
-		project, err := getter.GetProjectById(uint(projectID))
+		// Получаем проект из хранилища
+		project, err := getter.GetProjectByID(uint(projectID))
 		if err != nil {
-			if errors.Is(err, er.ErrReportNotFound) {
+			if errors.Is(err, er.ErrProjectNotFound) {
 				w.WriteHeader(http.StatusNotFound)
 				json.NewEncoder(w).Encode(ProjectResponseGet{
 					Status: "error",
@@ -70,6 +62,7 @@ func NewGetProjectByIdHandler(getter ProjectGetter) http.HandlerFunc {
 				})
 				return
 			}
+			logger.FromContext(r.Context()).Error("failed to get project", logger.Err(err))
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(ProjectResponseGet{
 				Status: "error",
@@ -79,6 +72,7 @@ func NewGetProjectByIdHandler(getter ProjectGetter) http.HandlerFunc {
 		}
 
 		// Возвращаем успешный ответ
+		etag.Set(w, project.ModifiedAt)
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(ProjectResponseGet{
 			Status:  "success",
@@ -0,0 +1,219 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/etag"
+	"goproject/internal/http_server/mergepatch"
+	"goproject/internal/http_server/router"
+	"goproject/internal/http_server/validate"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type ProjectPatcher interface {
+	GetProjectByID(ID uint) (entity.Project, error)
+	PatchProject(actor entity.Actor, ID uint, patch entity.ProjectPatch, version time.Time) error
+}
+
+// NewPatchProjectHandler частично обновляет проект по JSON Merge Patch:
+// отсутствующие поля не меняются, null сбрасывает поле. Как и PUT, требует
+// If-Match.
+func NewPatchProjectHandler(patcher ProjectPatcher, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := pol.ManageProjects(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		projectID, err := strconv.ParseUint(router.Param(r, "id"), 10, 32)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  "invalid project ID format",
+			})
+			return
+		}
+
+		if err := mergepatch.CheckContentType(r); err != nil {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  err.Error(),
+			})
+			return
+		}
+
+		ifMatch := r.Header.Get("If-Match")
+		if ifMatch == "" {
+			w.WriteHeader(http.StatusPreconditionRequired)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  "If-Match header is required",
+			})
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  "invalid request body",
+			})
+			return
+		}
+
+		existingProject, err := patcher.GetProjectByID(uint(projectID))
+		if err != nil {
+			if errors.Is(err, er.ErrProjectNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(ProjectResponse{
+					Status: "error",
+					Error:  "project not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get project", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  "failed to get project",
+			})
+			return
+		}
+		if !etag.Match(ifMatch, existingProject.ModifiedAt) {
+			writeVersionConflict(w, existingProject)
+			return
+		}
+
+		// Патч применяется к текущему состоянию проекта в форме запроса PUT,
+		// дальше проверки те же
+		req := ProjectUpdateRequest{
+			Name:        existingProject.Name,
+			Description: existingProject.Description,
+			ManagerID:   existingProject.ManagerID,
+		}
+		if err := mergepatch.Apply(&req, body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  err.Error(),
+			})
+			return
+		}
+
+		if fields := validate.Struct(req); fields != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  validate.Message,
+				Fields: fields,
+			})
+			return
+		}
+
+		// В хранилище уходят только измененные поля
+		var patch entity.ProjectPatch
+		if req.Name != existingProject.Name {
+			patch.Name = entity.Change(req.Name)
+		}
+		if req.Description != existingProject.Description {
+			patch.Description = entity.Change(req.Description)
+		}
+		if !sameManager(req.ManagerID, existingProject.ManagerID) {
+			patch.ManagerID = entity.Change(req.ManagerID)
+		}
+
+		if err := patcher.PatchProject(auth.Actor(r.Context()), uint(projectID), patch, existingProject.ModifiedAt); err != nil {
+			if errors.Is(err, er.ErrVersionMismatch) {
+				if current, err := patcher.GetProjectByID(uint(projectID)); err == nil {
+					writeVersionConflict(w, current)
+					return
+				}
+			}
+			if errors.Is(err, er.ErrProjectNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(ProjectResponse{
+					Status: "error",
+					Error:  "project not found",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrInvalidProjectData) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(ProjectResponse{
+					Status: "error",
+					Error:  "invalid project data",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrForeignKeyViolation) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(ProjectResponse{
+					Status: "error",
+					Error:  "manager not found",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrUniqueViolation) {
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(ProjectResponse{
+					Status: "error",
+					Error:  "project already exists",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to patch project", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  "failed to patch project",
+			})
+			return
+		}
+
+		updatedProject, err := patcher.GetProjectByID(uint(projectID))
+		if err != nil {
+			logger.FromContext(r.Context()).Error("failed to get project", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  "failed to get project",
+			})
+			return
+		}
+
+		etag.Set(w, updatedProject.ModifiedAt)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(ProjectResponse{
+			Status:    "success",
+			Project:   updatedProject,
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+func sameManager(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
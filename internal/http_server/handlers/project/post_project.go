@@ -3,33 +3,43 @@ package project
 import (
 	"encoding/json"
 	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/validate"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
 	er "goproject/internal/storage"
 	"goproject/internal/storage/postgres/entity"
 	"net/http"
+
+	"github.com/google/uuid"
 )
 
 type ProjectRequestPost struct {
-	ID uint `json:"id"`
+	Name        string     `json:"name" validate:"required,min=2,max=100"`
+	Description string     `json:"description,omitempty" validate:"max=500"`
+	ManagerID   *uuid.UUID `json:"manager_id,omitempty"`
 }
 type ProjectResponsePost struct {
-	Status  string         `json:"status"`
-	Error   string         `json:"error,omitempty"`
-	Project entity.Project `json:"project,omitempty"`
+	Status  string                `json:"status"`
+	Error   string                `json:"error,omitempty"`
+	Fields  []validate.FieldError `json:"fields,omitempty"`
+	Project entity.Project        `json:"project,omitempty"`
 }
 
 type ProjectSaverPost interface {
-	SaveProject(project entity.Project) error
+	SaveProject(actor entity.Actor, project entity.Project) (entity.Project, error)
 }
 
-func NewProjectHandler(saver ProjectSaverPost) http.HandlerFunc {
+func NewProjectHandler(saver ProjectSaverPost, pol *policy.Policy) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+		if err := pol.ManageProjects(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
 			json.NewEncoder(w).Encode(ProjectResponsePost{
 				Status: "error",
-				Error:  "method not allowed",
+				Error:  msg,
 			})
 			return
 		}
@@ -44,30 +54,48 @@ func NewProjectHandler(saver ProjectSaverPost) http.HandlerFunc {
 			return
 		}
 
-		if req.ID == 0 {
-			w.WriteHeader(http.StatusBadRequest)
+		if fields := validate.Struct(req); fields != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
 			json.NewEncoder(w).Encode(ProjectResponsePost{
 				Status: "error",
-				Error:  "id is required",
+				Error:  validate.Message,
+				Fields: fields,
 			})
 			return
 		}
 
-		project := entity.Project{
-			ID: req.ID,
-		}
-
-		err := saver.SaveProject(project)
+		project, err := saver.SaveProject(auth.Actor(r.Context()), entity.Project{
+			Name:        req.Name,
+			Description: req.Description,
+			ManagerID:   req.ManagerID,
+		})
 		if err != nil {
-			if errors.Is(err, er.ErrInvalidDeveloperData) {
-				w.WriteHeader(http.StatusBadRequest)
+			if errors.Is(err, er.ErrInvalidProjectData) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
 				json.NewEncoder(w).Encode(ProjectResponsePost{
 					Status: "error",
 					Error:  "invalid project data",
 				})
 				return
 			}
+			if errors.Is(err, er.ErrForeignKeyViolation) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(ProjectResponsePost{
+					Status: "error",
+					Error:  "manager not found",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrUniqueViolation) {
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(ProjectResponsePost{
+					Status: "error",
+					Error:  "project already exists",
+				})
+				return
+			}
 
+			logger.FromContext(r.Context()).Error("failed to save project", logger.Err(err))
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(ProjectResponsePost{
 				Status: "error",
@@ -76,7 +104,7 @@ func NewProjectHandler(saver ProjectSaverPost) http.HandlerFunc {
 			return
 		}
 
-		w.WriteHeader(http.StatusOK)
+		w.WriteHeader(http.StatusCreated)
 		json.NewEncoder(w).Encode(ProjectResponsePost{
 			Status:  "ok",
 			Project: project,
@@ -0,0 +1,86 @@
+package project
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/etag"
+	"goproject/internal/http_server/router"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type ProjectArchiver interface {
+	ArchiveProject(actor entity.Actor, ID uint) error
+	GetProjectByID(ID uint) (entity.Project, error)
+}
+
+// NewArchiveProjectHandler переводит проект в архив. Задачи проекта остаются
+// в истории и аналитике, новые задачи в нем создать нельзя.
+func NewArchiveProjectHandler(archiver ProjectArchiver, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := pol.ManageProjects(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		projectID, err := strconv.ParseUint(router.Param(r, "id"), 10, 32)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  "invalid project ID format",
+			})
+			return
+		}
+
+		if err := archiver.ArchiveProject(auth.Actor(r.Context()), uint(projectID)); err != nil {
+			if errors.Is(err, er.ErrProjectNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(ProjectResponse{
+					Status: "error",
+					Error:  "project not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to archive project", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  "failed to archive project",
+			})
+			return
+		}
+
+		project, err := archiver.GetProjectByID(uint(projectID))
+		if err != nil {
+			logger.FromContext(r.Context()).Error("failed to get project", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  "failed to get project",
+			})
+			return
+		}
+
+		etag.Set(w, project.ModifiedAt)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(ProjectResponse{
+			Status:    "success",
+			Project:   project,
+			Timestamp: time.Now(),
+		})
+	}
+}
@@ -3,63 +3,75 @@ package project
 import (
 	"encoding/json"
 	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/etag"
+	"goproject/internal/http_server/router"
+	"goproject/internal/http_server/validate"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
 	er "goproject/internal/storage"
 	"goproject/internal/storage/postgres/entity"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
+
+	"github.com/google/uuid"
 )
 
 type ProjectUpdateRequest struct {
-	Name        string `json:"name" validate:"required,min=2,max=100"`
-	Description string `json:"description,omitempty" validate:"max=500"`
+	Name        string     `json:"name" validate:"required,min=2,max=100"`
+	Description string     `json:"description,omitempty" validate:"max=500"`
+	ManagerID   *uuid.UUID `json:"manager_id,omitempty"`
 }
 
 // ProjectResponse - структура ответа для проектов
 type ProjectResponse struct {
-	Status    string         `json:"status"`
-	Error     string         `json:"error,omitempty"`
-	Project   entity.Project `json:"project,omitempty"`
-	Timestamp time.Time      `json:"timestamp"`
+	Status    string                `json:"status"`
+	Error     string                `json:"error,omitempty"`
+	Fields    []validate.FieldError `json:"fields,omitempty"`
+	Project   entity.Project        `json:"project,omitempty"`
+	Timestamp time.Time             `json:"timestamp"`
 }
 
 type ProjectUpdater interface {
 	GetProjectByID(ID uint) (entity.Project, error)
-	UpdateProject(ID uint, project entity.Project) error
+	UpdateProject(actor entity.Actor, ID uint, project entity.Project, version time.Time) error
 }
 
-func NewUpdateProjectHandler(updater ProjectUpdater) http.HandlerFunc {
+// NewUpdateProjectHandler заменяет поля проекта. Запрос должен содержать
+// If-Match с ETag проекта из GET; если проект с тех пор изменился, ответ 412
+// содержит его текущую версию.
+func NewUpdateProjectHandler(updater ProjectUpdater, pol *policy.Policy) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		// Проверяем метод запроса
-		if r.Method != http.MethodPut {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+		if err := pol.ManageProjects(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
 			json.NewEncoder(w).Encode(ProjectResponse{
 				Status: "error",
-				Error:  "method not allowed",
+				Error:  msg,
 			})
 			return
 		}
 
 		// Извлекаем ID проекта из URL
-		pathParts := strings.Split(r.URL.Path, "/")
-		if len(pathParts) < 3 {
+		projectID, err := strconv.ParseUint(router.Param(r, "id"), 10, 32)
+		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(ProjectResponse{
 				Status: "error",
-				Error:  "invalid URL format",
+				Error:  "invalid project ID format",
 			})
 			return
 		}
 
-		projectID, err := strconv.ParseUint(pathParts[2], 10, 32)
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+		ifMatch := r.Header.Get("If-Match")
+		if ifMatch == "" {
+			w.WriteHeader(http.StatusPreconditionRequired)
 			json.NewEncoder(w).Encode(ProjectResponse{
 				Status: "error",
-				Error:  "invalid project ID format",
+				Error:  "If-Match header is required",
 			})
 			return
 		}
@@ -76,20 +88,12 @@ func NewUpdateProjectHandler(updater ProjectUpdater) http.HandlerFunc {
 		}
 
 		// Валидация входных данных
-		if len(req.Name) < 2 || len(req.Name) > 100 {
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(ProjectResponse{
-				Status: "error",
-				Error:  "name must be between 2 and 100 characters",
-			})
-			return
-		}
-
-		if len(req.Description) > 500 {
-			w.WriteHeader(http.StatusBadRequest)
+		if fields := validate.Struct(req); fields != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
 			json.NewEncoder(w).Encode(ProjectResponse{
 				Status: "error",
-				Error:  "description must not exceed 500 characters",
+				Error:  validate.Message,
+				Fields: fields,
 			})
 			return
 		}
@@ -105,6 +109,7 @@ func NewUpdateProjectHandler(updater ProjectUpdater) http.HandlerFunc {
 				})
 				return
 			}
+			logger.FromContext(r.Context()).Error("failed to get project", logger.Err(err))
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(ProjectResponse{
 				Status: "error",
@@ -112,16 +117,61 @@ func NewUpdateProjectHandler(updater ProjectUpdater) http.HandlerFunc {
 			})
 			return
 		}
+		if !etag.Match(ifMatch, existingProject.ModifiedAt) {
+			writeVersionConflict(w, existingProject)
+			return
+		}
 
 		// Обновляем проект
 		updatedProject := entity.Project{
 			ID:          uint(projectID),
 			Name:        req.Name,
 			Description: req.Description,
+			ManagerID:   req.ManagerID,
 			CreatedAt:   existingProject.CreatedAt,
 		}
 
-		if err := updater.UpdateProject(uint(projectID), updatedProject); err != nil {
+		if err := updater.UpdateProject(auth.Actor(r.Context()), uint(projectID), updatedProject, existingProject.ModifiedAt); err != nil {
+			if errors.Is(err, er.ErrVersionMismatch) {
+				// Проект изменили между чтением и записью
+				if current, err := updater.GetProjectByID(uint(projectID)); err == nil {
+					writeVersionConflict(w, current)
+					return
+				}
+			}
+			if errors.Is(err, er.ErrProjectNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(ProjectResponse{
+					Status: "error",
+					Error:  "project not found",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrInvalidProjectData) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(ProjectResponse{
+					Status: "error",
+					Error:  "invalid project data",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrForeignKeyViolation) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(ProjectResponse{
+					Status: "error",
+					Error:  "manager not found",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrUniqueViolation) {
+				w.WriteHeader(http.StatusConflict)
+				json.NewEncoder(w).Encode(ProjectResponse{
+					Status: "error",
+					Error:  "project already exists",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to update project", logger.Err(err))
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(ProjectResponse{
 				Status: "error",
@@ -130,7 +180,19 @@ func NewUpdateProjectHandler(updater ProjectUpdater) http.HandlerFunc {
 			return
 		}
 
+		updatedProject, err = updater.GetProjectByID(uint(projectID))
+		if err != nil {
+			logger.FromContext(r.Context()).Error("failed to get project", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ProjectResponse{
+				Status: "error",
+				Error:  "failed to get project",
+			})
+			return
+		}
+
 		// Возвращаем успешный ответ
+		etag.Set(w, updatedProject.ModifiedAt)
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(ProjectResponse{
 			Status:    "success",
@@ -139,3 +201,16 @@ func NewUpdateProjectHandler(updater ProjectUpdater) http.HandlerFunc {
 		})
 	}
 }
+
+// writeVersionConflict отвечает 412 с текущей версией проекта, чтобы клиент
+// мог повторить изменение поверх нее
+func writeVersionConflict(w http.ResponseWriter, current entity.Project) {
+	etag.Set(w, current.ModifiedAt)
+	w.WriteHeader(http.StatusPreconditionFailed)
+	json.NewEncoder(w).Encode(ProjectResponse{
+		Status:    "error",
+		Error:     "project was modified, retry with the current version",
+		Project:   current,
+		Timestamp: time.Now(),
+	})
+}
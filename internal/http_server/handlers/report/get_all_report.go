@@ -2,45 +2,62 @@ package report
 
 import (
 	"encoding/json"
-	"errors"
+	"goproject/internal/http_server/listparams"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
 	er "goproject/internal/storage"
 	"goproject/internal/storage/postgres/entity"
 	"net/http"
 )
 
 type ReportResponseGetAll struct {
-	Status  string          `json:"status"`
-	Error   string          `json:"error,omitempty"`
-	Reports []entity.Report `json:"reports,omitempty"`
+	Status     string          `json:"status"`
+	Error      string          `json:"error,omitempty"`
+	Reports    []entity.Report `json:"reports,omitempty"`
+	NextCursor string          `json:"next_cursor,omitempty"`
 }
 
 type ReportGetterGetAll interface {
-	GetReport() ([]entity.Report, error)
+	GetReport(params er.ListParams) ([]entity.Report, string, error)
 }
 
-func NewGetAllReportHandler(getter ReportGetterGetAll) http.HandlerFunc {
+// NewGetAllReportHandler отдает отчеты постранично. Параметры: limit, cursor,
+// sort (created_at, id; "-" - по убыванию), developer, project, from, to.
+func NewGetAllReportHandler(getter ReportGetterGetAll, pol *policy.Policy) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+		params, err := listparams.Parse(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ReportResponseGetAll{
+				Status: "error",
+				Error:  err.Error(),
+			})
+			return
+		}
+
+		if err := pol.ScopeList(r.Context(), &params); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
 			json.NewEncoder(w).Encode(ReportResponseGetAll{
 				Status: "error",
-				Error:  "method not allowed",
+				Error:  msg,
 			})
 			return
 		}
 
-		reports, err := getter.GetReport()
+		reports, next, err := getter.GetReport(params)
 		if err != nil {
-			if errors.Is(err, er.ErrReportNotFound) {
-				w.WriteHeader(http.StatusNotFound)
+			if listparams.IsBadRequest(err) {
+				w.WriteHeader(http.StatusBadRequest)
 				json.NewEncoder(w).Encode(ReportResponseGetAll{
 					Status: "error",
-					Error:  "reports not found",
+					Error:  "invalid cursor or sort",
 				})
 				return
 			}
+			logger.FromContext(r.Context()).Error("failed to get reports", logger.Err(err))
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(ReportResponseGetAll{
 				Status: "error",
@@ -51,8 +68,9 @@ func NewGetAllReportHandler(getter ReportGetterGetAll) http.HandlerFunc {
 
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(ReportResponseGetAll{
-			Status:  "ok",
-			Reports: reports,
+			Status:     "ok",
+			Reports:    reports,
+			NextCursor: next,
 		})
 	}
 }
@@ -3,11 +3,13 @@ package report
 import (
 	"encoding/json"
 	"errors"
+	"goproject/internal/http_server/router"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
 	er "goproject/internal/storage"
 	"goproject/internal/storage/postgres/entity"
 	"net/http"
 	"strconv"
-	"strings"
 )
 
 // ReportResponseGet - структура ответа для получения отчета
@@ -23,33 +25,12 @@ type ReportGetter interface {
 }
 
 // NewGetReportByIdHandler создает обработчик для получения отчета по ID
-func NewGetReportByIdHandler(getter ReportGetter) http.HandlerFunc {
+func NewGetReportByIdHandler(getter ReportGetter, pol *policy.Policy) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		// Проверяем метод запроса
-		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
-			json.NewEncoder(w).Encode(ReportResponseGet{
-				Status: "error",
-				Error:  "method not allowed",
-			})
-			return
-		}
-
 		// Извлекаем ID отчета из URL
-		parts := strings.Split(r.URL.Path, "/")
-		if len(parts) < 3 {
-			w.WriteHeader(http.StatusBadRequest)
-			json.NewEncoder(w).Encode(ReportResponseGet{
-				Status: "error",
-				Error:  "report ID is required",
-			})
-			return
-		}
-
-		// Парсим uint
-		reportID, err := strconv.ParseUint(parts[2], 10, 32)
+		reportID, err := strconv.ParseUint(router.Param(r, "id"), 10, 32)
 		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(ReportResponseGet{
@@ -70,6 +51,7 @@ func NewGetReportByIdHandler(getter ReportGetter) http.HandlerFunc {
 				})
 				return
 			}
+			logger.FromContext(r.Context()).Error("failed to get report", logger.Err(err))
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(ReportResponseGet{
 				Status: "error",
@@ -78,6 +60,16 @@ func NewGetReportByIdHandler(getter ReportGetter) http.HandlerFunc {
 			return
 		}
 
+		if err := pol.ReadReport(r.Context(), report); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(ReportResponseGet{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
 		// Возвращаем успешный ответ
 		w.WriteHeader(http.StatusOK)
 		json.NewEncoder(w).Encode(ReportResponseGet{
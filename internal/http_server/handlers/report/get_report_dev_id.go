@@ -3,10 +3,13 @@ package report
 import (
 	"encoding/json"
 	"errors"
+	"goproject/internal/http_server/listparams"
+	"goproject/internal/http_server/router"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
 	er "goproject/internal/storage"
 	"goproject/internal/storage/postgres/entity"
 	"net/http"
-	"strings"
 
 	"github.com/google/uuid"
 )
@@ -16,46 +19,53 @@ type DevReportResponseGet struct {
 	Error   string          `json:"error"`
 	Reports []entity.Report `json:"reports"`
 	Count   int             `json:"count"`
+
+	NextCursor string `json:"next_cursor,omitempty"`
 }
 
 type DevReportsGetter interface {
-	GetDeveloperById(uid uuid.UUID) (entity.Developer, error)
-	GetReportsByDeveloperID(developerID uuid.UUID) ([]entity.Report, error)
+	GetDeveloperByID(uid uuid.UUID) (entity.Developer, error)
+	GetReport(params er.ListParams) ([]entity.Report, string, error)
 }
 
-func NewGetDeveloperReportsHandler(getter DevReportsGetter) http.HandlerFunc {
+// NewGetDeveloperReportsHandler отдает отчеты разработчика постранично; параметры
+// те же, что у списка отчетов, кроме developer
+func NewGetDeveloperReportsHandler(getter DevReportsGetter, pol *policy.Policy) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
-		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+
+		developerID, err := uuid.Parse(router.Param(r, "id"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(DevReportResponseGet{
 				Status: "error",
-				Error:  "method not allowed",
+				Error:  "invalid developer ID format",
 			})
 			return
 		}
-		pathParts := strings.Split(r.URL.Path, "/")
-		if len(pathParts) < 4 {
+
+		params, err := listparams.Parse(r)
+		if err != nil {
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(DevReportResponseGet{
 				Status: "error",
-				Error:  "invalid URL path",
+				Error:  err.Error(),
 			})
 			return
 		}
+		params.DeveloperID = developerID
 
-		developerIDStr := pathParts[2]
-		developerID, err := uuid.Parse(developerIDStr)
-		if err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+		if err := pol.ScopeList(r.Context(), &params); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
 			json.NewEncoder(w).Encode(DevReportResponseGet{
 				Status: "error",
-				Error:  "invalid URL path",
+				Error:  msg,
 			})
 			return
 		}
 
-		_, err = getter.GetDeveloperById(developerID)
+		_, err = getter.GetDeveloperByID(developerID)
 		if err != nil {
 			if errors.Is(err, er.ErrDeveloperNotFound) {
 				w.WriteHeader(http.StatusNotFound)
@@ -65,6 +75,7 @@ func NewGetDeveloperReportsHandler(getter DevReportsGetter) http.HandlerFunc {
 				})
 				return
 			}
+			logger.FromContext(r.Context()).Error("failed to get developer", logger.Err(err))
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(DevReportResponseGet{
 				Status: "error",
@@ -73,8 +84,17 @@ func NewGetDeveloperReportsHandler(getter DevReportsGetter) http.HandlerFunc {
 			return
 		}
 
-		reports, err := getter.GetReportsByDeveloperID(developerID)
+		reports, next, err := getter.GetReport(params)
 		if err != nil {
+			if listparams.IsBadRequest(err) {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(DevReportResponseGet{
+					Status: "error",
+					Error:  "invalid cursor or sort",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get reports", logger.Err(err))
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(DevReportResponseGet{
 				Status: "error",
@@ -88,6 +108,8 @@ func NewGetDeveloperReportsHandler(getter DevReportsGetter) http.HandlerFunc {
 			Status:  "success",
 			Reports: reports,
 			Count:   len(reports),
+
+			NextCursor: next,
 		})
 
 	}
@@ -3,49 +3,91 @@ package report
 import (
 	"encoding/json"
 	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/validate"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
 	er "goproject/internal/storage"
 	"goproject/internal/storage/postgres/entity"
 	"net/http"
+	"time"
+
+	"github.com/google/uuid"
 )
 
+// ReportTaskRequest - задача, передаваемая вместе с отчетом
+type ReportTaskRequest struct {
+	ProjectID        uint      `json:"project_id" validate:"required"`
+	Name             string    `json:"name" validate:"required,max=255"`
+	DeveloperNote    string    `json:"developer_note,omitempty"`
+	EstimatePlaned   int       `json:"estimate_planed" validate:"required,min=1"`
+	EstimateProgress int       `json:"estimate_progress" validate:"min=0"`
+	StartTimestamp   time.Time `json:"start_timestamp" validate:"required"`
+	EndTimestamp     time.Time `json:"end_timestamp" validate:"required,gtfield=StartTimestamp"`
+}
+
+// ReportRequestPost - тело отчета. Если developer_id не указан, отчет
+// создается от имени аутентифицированного разработчика; за другого
+// разработчика отчет может отправить только администратор.
 type ReportRequestPost struct {
-	ID uint `json:"id" validate:"required"`
+	DeveloperID uuid.UUID           `json:"developer_id,omitempty"`
+	Tasks       []ReportTaskRequest `json:"tasks" validate:"required,min=1"`
+}
+
+// ReportTaskError - ошибка проверки задачи из тела отчета
+type ReportTaskError struct {
+	Index int    `json:"index"`
+	Error string `json:"error"`
 }
 
 type ReportResponsePost struct {
-	Status   string `json:"status"`
-	Error    string `json:"error,omitempty"`
-	ReportID uint   `json:"report_id,omitempty"`
+	Status     string                `json:"status"`
+	Error      string                `json:"error,omitempty"`
+	Fields     []validate.FieldError `json:"fields,omitempty"`
+	ReportID   uint                  `json:"report_id,omitempty"`
+	Report     *entity.Report        `json:"report,omitempty"`
+	Tasks      []entity.Task         `json:"tasks,omitempty"`
+	TaskErrors []ReportTaskError     `json:"task_errors,omitempty"`
 }
 
 type ReportSaverPost interface {
-	SaveReport(report entity.Report) error
+	GetDeveloperByID(uid uuid.UUID) (entity.Developer, error)
+	GetProjectByID(ID uint) (entity.Project, error)
+	SaveReportWithTasks(actor entity.Actor, report entity.Report, tasks []entity.Task) (entity.Report, []entity.Task, error)
 }
 
-func NewReportHandler(saver ReportSaverPost) http.HandlerFunc {
+// NewReportHandler создает обработчик отправки отчета. Отчет и все его
+// задачи сохраняются атомарно: при ошибке любой задачи ничего не записывается.
+func NewReportHandler(saver ReportSaverPost, pol *policy.Policy) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "application/json")
 
-		if r.Method != http.MethodPost {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+		var req ReportRequestPost
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(ReportResponsePost{
 				Status: "error",
-				Error:  "method not allowed",
+				Error:  "failed to decode request",
 			})
 			return
 		}
 
-		var req ReportRequestPost
-		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-			w.WriteHeader(http.StatusBadRequest)
+		if fields := validate.Struct(req); fields != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
 			json.NewEncoder(w).Encode(ReportResponsePost{
 				Status: "error",
-				Error:  "failed to decode request",
+				Error:  validate.Message,
+				Fields: fields,
 			})
 			return
 		}
 
-		if req.ID == 0 {
+		principal, _ := auth.FromContext(r.Context())
+		if req.DeveloperID == uuid.Nil {
+			req.DeveloperID = principal.DeveloperID
+		}
+
+		if req.DeveloperID == uuid.Nil {
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(ReportResponsePost{
 				Status: "error",
@@ -54,21 +96,106 @@ func NewReportHandler(saver ReportSaverPost) http.HandlerFunc {
 			return
 		}
 
-		report := entity.Report{
-			ID: req.ID,
+		if err := pol.CreateReport(r.Context(), req.DeveloperID); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(ReportResponsePost{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		developer, err := saver.GetDeveloperByID(req.DeveloperID)
+		if err == nil && developer.DeletedAt != nil {
+			err = er.ErrDeveloperNotFound
+		}
+		if err != nil {
+			if errors.Is(err, er.ErrDeveloperNotFound) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(ReportResponsePost{
+					Status: "error",
+					Error:  "developer not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get developer", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ReportResponsePost{
+				Status: "error",
+				Error:  "failed to get developer",
+			})
+			return
+		}
+
+		tasks := make([]entity.Task, 0, len(req.Tasks))
+		var taskErrors []ReportTaskError
+		for i, t := range req.Tasks {
+			msg, err := validateReportTask(t, saver)
+			if err != nil {
+				logger.FromContext(r.Context()).Error("failed to get project", logger.Err(err))
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(ReportResponsePost{
+					Status: "error",
+					Error:  "failed to get project",
+				})
+				return
+			}
+			if msg != "" {
+				taskErrors = append(taskErrors, ReportTaskError{Index: i, Error: msg})
+				continue
+			}
+
+			tasks = append(tasks, entity.Task{
+				ProjectID:        t.ProjectID,
+				Name:             t.Name,
+				DeveloperNote:    t.DeveloperNote,
+				EstimatePlaned:   t.EstimatePlaned,
+				EstimateProgress: t.EstimateProgress,
+				StartTimestamp:   t.StartTimestamp,
+				EndTimestamp:     t.EndTimestamp,
+			})
+		}
+
+		if len(taskErrors) > 0 {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(ReportResponsePost{
+				Status:     "error",
+				Error:      "invalid tasks",
+				TaskErrors: taskErrors,
+			})
+			return
 		}
 
-		err := saver.SaveReport(report)
+		report, saved, err := saver.SaveReportWithTasks(auth.Actor(r.Context()), entity.Report{DeveloperID: req.DeveloperID}, tasks)
 		if err != nil {
-			if errors.Is(err, er.ErrInvalidDeveloperData) {
-				w.WriteHeader(http.StatusBadRequest)
+			if errors.Is(err, er.ErrInvalidReportData) || errors.Is(err, er.ErrInvalidTaskData) ||
+				errors.Is(err, er.ErrCheckViolation) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
 				json.NewEncoder(w).Encode(ReportResponsePost{
 					Status: "error",
 					Error:  "invalid report data",
 				})
 				return
 			}
+			if errors.Is(err, er.ErrForeignKeyViolation) || errors.Is(err, er.ErrProjectNotFound) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(ReportResponsePost{
+					Status: "error",
+					Error:  "developer or project not found",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrProjectArchived) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(ReportResponsePost{
+					Status: "error",
+					Error:  "project is archived",
+				})
+				return
+			}
 
+			logger.FromContext(r.Context()).Error("failed to save report", logger.Err(err))
 			w.WriteHeader(http.StatusInternalServerError)
 			json.NewEncoder(w).Encode(ReportResponsePost{
 				Status: "error",
@@ -81,6 +208,26 @@ func NewReportHandler(saver ReportSaverPost) http.HandlerFunc {
 		json.NewEncoder(w).Encode(ReportResponsePost{
 			Status:   "ok",
 			ReportID: report.ID,
+			Report:   &report,
+			Tasks:    saved,
 		})
 	}
 }
+
+// validateReportTask проверяет проект задачи, поля уже проверены по тегам
+// validate. Возвращает текст ошибки для некорректной задачи; err означает,
+// что проверку не удалось выполнить.
+func validateReportTask(t ReportTaskRequest, saver ReportSaverPost) (string, error) {
+	project, err := saver.GetProjectByID(t.ProjectID)
+	if err != nil {
+		if errors.Is(err, er.ErrProjectNotFound) {
+			return "project not found", nil
+		}
+		return "", err
+	}
+	if project.ArchivedAt != nil {
+		return "project is archived", nil
+	}
+
+	return "", nil
+}
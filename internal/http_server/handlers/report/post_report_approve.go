@@ -0,0 +1,101 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/router"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+	"strconv"
+)
+
+type ReportApprover interface {
+	GetReportById(id uint) (entity.Report, error)
+	ApproveReport(actor entity.Actor, ID uint) error
+}
+
+// NewApproveReportHandler утверждает отчет. Повторное утверждение не меняет
+// первого утвердившего.
+func NewApproveReportHandler(approver ReportApprover, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		reportID, err := strconv.ParseUint(router.Param(r, "id"), 10, 32)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ReportResponseGet{
+				Status: "error",
+				Error:  "invalid report ID format",
+			})
+			return
+		}
+
+		report, err := approver.GetReportById(uint(reportID))
+		if err != nil {
+			if errors.Is(err, er.ErrReportNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(ReportResponseGet{
+					Status: "error",
+					Error:  "report not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get report", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ReportResponseGet{
+				Status: "error",
+				Error:  "failed to get report",
+			})
+			return
+		}
+
+		if err := pol.ApproveReport(r.Context(), report); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(ReportResponseGet{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		if err := approver.ApproveReport(auth.Actor(r.Context()), uint(reportID)); err != nil {
+			if errors.Is(err, er.ErrReportNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(ReportResponseGet{
+					Status: "error",
+					Error:  "report not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to approve report", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ReportResponseGet{
+				Status: "error",
+				Error:  "failed to approve report",
+			})
+			return
+		}
+
+		report, err = approver.GetReportById(uint(reportID))
+		if err != nil {
+			logger.FromContext(r.Context()).Error("failed to get report", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ReportResponseGet{
+				Status: "error",
+				Error:  "failed to get report",
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(ReportResponseGet{
+			Status: "ok",
+			Report: report,
+		})
+	}
+}
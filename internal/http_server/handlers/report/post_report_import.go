@@ -0,0 +1,370 @@
+package report
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/config"
+	"goproject/internal/http_server/router"
+	"goproject/internal/http_server/validate"
+	"goproject/internal/ical"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// maxImportSize - максимальный размер загружаемого файла календаря
+const maxImportSize = 10 << 20
+
+// ImportedTask - задача, созданная из события календаря
+type ImportedTask struct {
+	Index  int    `json:"index"`
+	UID    string `json:"uid,omitempty"`
+	TaskID uint   `json:"task_id"`
+}
+
+// ImportError - ошибка импорта отдельного события
+type ImportError struct {
+	Index int    `json:"index"`
+	UID   string `json:"uid,omitempty"`
+	Error string `json:"error"`
+}
+
+type ReportImportResponse struct {
+	Status   string         `json:"status"`
+	Error    string         `json:"error,omitempty"`
+	ReportID uint           `json:"report_id,omitempty"`
+	Imported []ImportedTask `json:"imported,omitempty"`
+	Errors   []ImportError  `json:"errors,omitempty"`
+}
+
+type ReportImporter interface {
+	GetReportById(id uint) (entity.Report, error)
+	GetProjectByID(ID uint) (entity.Project, error)
+	GetProjectByName(name string) (entity.Project, error)
+	SaveTasks(actor entity.Actor, tasks []entity.Task) ([]int, error)
+}
+
+// NewImportReportHandler создает обработчик импорта задач отчета из файла iCalendar.
+// Файл передается телом запроса или полем file формы multipart/form-data.
+// События с ошибками пропускаются и перечисляются в ответе, остальные
+// сохраняются одной транзакцией.
+func NewImportReportHandler(importer ReportImporter, rules config.ICalImport, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		reportID, err := strconv.ParseUint(router.Param(r, "id"), 10, 32)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ReportImportResponse{
+				Status: "error",
+				Error:  "invalid report ID format",
+			})
+			return
+		}
+
+		report, err := importer.GetReportById(uint(reportID))
+		if err != nil {
+			if errors.Is(err, er.ErrReportNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(ReportImportResponse{
+					Status: "error",
+					Error:  "report not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get report", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ReportImportResponse{
+				Status: "error",
+				Error:  "failed to get report",
+			})
+			return
+		}
+
+		if err := pol.EditReport(r.Context(), report); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(ReportImportResponse{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		r.Body = http.MaxBytesReader(w, r.Body, maxImportSize)
+
+		var body io.Reader = r.Body
+		if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+			file, _, err := r.FormFile("file")
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(ReportImportResponse{
+					Status: "error",
+					Error:  "file is required",
+				})
+				return
+			}
+			defer file.Close()
+			body = file
+		}
+
+		events, eventErrs, err := ical.Decode(body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ReportImportResponse{
+				Status: "error",
+				Error:  "failed to parse calendar",
+			})
+			return
+		}
+
+		projects := newProjectResolver(importer, rules)
+
+		resp := ReportImportResponse{ReportID: uint(reportID)}
+		for _, e := range eventErrs {
+			resp.Errors = append(resp.Errors, ImportError{
+				Index: e.Index,
+				UID:   e.UID,
+				Error: e.Err.Error(),
+			})
+		}
+
+		var (
+			tasks   []entity.Task
+			sources []ImportedTask
+		)
+		for i, event := range events {
+			index := eventIndex(i, eventErrs)
+
+			task, msg, err := eventToTask(event, uint(reportID), projects)
+			if err != nil {
+				logger.FromContext(r.Context()).Error("failed to get projects", logger.Err(err))
+				w.WriteHeader(http.StatusInternalServerError)
+				json.NewEncoder(w).Encode(ReportImportResponse{
+					Status: "error",
+					Error:  "failed to get projects",
+				})
+				return
+			}
+			if msg != "" {
+				resp.Errors = append(resp.Errors, ImportError{
+					Index: index,
+					UID:   event.UID,
+					Error: msg,
+				})
+				continue
+			}
+
+			tasks = append(tasks, task)
+			sources = append(sources, ImportedTask{Index: index, UID: event.UID})
+		}
+
+		if len(tasks) == 0 {
+			resp.Status = "error"
+			resp.Error = "no events to import"
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(resp)
+			return
+		}
+
+		ids, err := importer.SaveTasks(auth.Actor(r.Context()), tasks)
+		if err != nil {
+			if errors.Is(err, er.ErrInvalidTaskData) || errors.Is(err, er.ErrCheckViolation) ||
+				errors.Is(err, er.ErrForeignKeyViolation) || errors.Is(err, er.ErrProjectNotFound) ||
+				errors.Is(err, er.ErrProjectArchived) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(ReportImportResponse{
+					Status: "error",
+					Error:  "invalid task data",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to save tasks", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ReportImportResponse{
+				Status: "error",
+				Error:  "failed to save tasks",
+			})
+			return
+		}
+
+		for i, id := range ids {
+			sources[i].TaskID = uint(id)
+		}
+		resp.Imported = sources
+
+		resp.Status = "ok"
+		if len(resp.Errors) > 0 {
+			resp.Status = "partial"
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(resp)
+	}
+}
+
+// eventIndex восстанавливает номер события в файле по номеру среди
+// успешно разобранных, учитывая события, отброшенные при разборе
+func eventIndex(parsed int, eventErrs []ical.EventError) int {
+	index := parsed
+	for _, e := range eventErrs {
+		if e.Index <= index {
+			index++
+		}
+	}
+	return index
+}
+
+// eventToTask преобразует событие в задачу отчета. Возвращает текст ошибки,
+// если событие нельзя импортировать.
+func eventToTask(event ical.Event, reportID uint, projects *projectResolver) (entity.Task, string, error) {
+	if event.Summary == "" {
+		return entity.Task{}, "SUMMARY is required", nil
+	}
+	if event.End.IsZero() {
+		return entity.Task{}, "DTEND or DURATION is required", nil
+	}
+	if !event.End.After(event.Start) {
+		return entity.Task{}, "event must end after it starts", nil
+	}
+
+	projectID, ok, err := projects.resolve(event.Categories)
+	if err != nil {
+		return entity.Task{}, "", err
+	}
+	if !ok {
+		return entity.Task{}, "no project matches event categories", nil
+	}
+
+	hours := int(math.Ceil(event.End.Sub(event.Start).Hours()))
+
+	// Задача из события должна проходить те же правила, что и задача из JSON
+	req := ReportTaskRequest{
+		ProjectID:        projectID,
+		Name:             event.Summary,
+		DeveloperNote:    event.Description,
+		EstimatePlaned:   hours,
+		EstimateProgress: hours,
+		StartTimestamp:   event.Start,
+		EndTimestamp:     event.End,
+	}
+	if fields := validate.Struct(req); fields != nil {
+		msgs := make([]string, len(fields))
+		for i, f := range fields {
+			msgs[i] = f.Message
+		}
+		return entity.Task{}, strings.Join(msgs, "; "), nil
+	}
+
+	return entity.Task{
+		ReportID:         reportID,
+		ProjectID:        req.ProjectID,
+		Name:             req.Name,
+		DeveloperNote:    req.DeveloperNote,
+		EstimatePlaned:   req.EstimatePlaned,
+		EstimateProgress: req.EstimateProgress,
+		StartTimestamp:   req.StartTimestamp,
+		EndTimestamp:     req.EndTimestamp,
+	}, "", nil
+}
+
+// projectResolver сопоставляет категории событий с проектами и запоминает
+// результаты запросов к хранилищу на время одного импорта. Категории и
+// названия проектов сравниваются без учета регистра.
+type projectResolver struct {
+	importer ReportImporter
+	rules    config.ICalImport
+	// categories - правила CategoryProjects с ключами в нижнем регистре
+	categories map[string]uint
+	exists     map[uint]bool
+	byName     map[string]uint
+}
+
+func newProjectResolver(importer ReportImporter, rules config.ICalImport) *projectResolver {
+	// Ключи, совпадающие без учета регистра, отклоняет проверка конфигурации
+	categories := make(map[string]uint, len(rules.CategoryProjects))
+	for category, id := range rules.CategoryProjects {
+		categories[strings.ToLower(category)] = id
+	}
+	return &projectResolver{
+		importer:   importer,
+		rules:      rules,
+		categories: categories,
+		exists:     make(map[uint]bool),
+		byName:     make(map[string]uint),
+	}
+}
+
+// resolve выбирает проект по категориям события: сначала по
+// настроенным правилам, затем по названию проекта, затем проект по умолчанию
+func (p *projectResolver) resolve(categories []string) (uint, bool, error) {
+	for _, category := range categories {
+		id, found := p.categories[strings.ToLower(category)]
+		if !found {
+			continue
+		}
+		ok, err := p.has(id)
+		if err != nil || ok {
+			return id, ok, err
+		}
+	}
+
+	if p.rules.MatchProjectNames {
+		for _, category := range categories {
+			id, err := p.named(category)
+			if err != nil || id != 0 {
+				return id, id != 0, err
+			}
+		}
+	}
+
+	if p.rules.DefaultProjectID != 0 {
+		ok, err := p.has(p.rules.DefaultProjectID)
+		if err != nil || ok {
+			return p.rules.DefaultProjectID, ok, err
+		}
+	}
+
+	return 0, false, nil
+}
+
+// has сообщает, можно ли добавлять задачи в проект; архивные проекты
+// пропускаются, как несуществующие
+func (p *projectResolver) has(id uint) (bool, error) {
+	if ok, cached := p.exists[id]; cached {
+		return ok, nil
+	}
+	project, err := p.importer.GetProjectByID(id)
+	if err != nil && !errors.Is(err, er.ErrProjectNotFound) {
+		return false, err
+	}
+	ok := err == nil && project.ArchivedAt == nil
+	p.exists[id] = ok
+	return ok, nil
+}
+
+// named возвращает id проекта с таким названием без учета регистра или 0.
+// GetProjectByName тоже сравнивает без учета регистра, поэтому результат
+// не зависит от того, в каком написании категория встретилась первой.
+func (p *projectResolver) named(name string) (uint, error) {
+	key := strings.ToLower(name)
+	if id, cached := p.byName[key]; cached {
+		return id, nil
+	}
+	project, err := p.importer.GetProjectByName(key)
+	if err != nil && !errors.Is(err, er.ErrProjectNotFound) {
+		return 0, err
+	}
+	if project.ArchivedAt != nil {
+		project.ID = 0
+	}
+	p.byName[key] = project.ID
+	return project.ID, nil
+}
@@ -0,0 +1,92 @@
+package report
+
+import (
+	"goproject/internal/config"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"strings"
+	"testing"
+	"time"
+)
+
+// stubProjects - проекты для projectResolver; поиск по названию без учета
+// регистра, как в хранилищах
+type stubProjects struct {
+	ReportImporter
+	projects    []entity.Project
+	nameLookups []string
+}
+
+func (s *stubProjects) GetProjectByID(ID uint) (entity.Project, error) {
+	for _, project := range s.projects {
+		if project.ID == ID {
+			return project, nil
+		}
+	}
+	return entity.Project{}, er.ErrProjectNotFound
+}
+
+func (s *stubProjects) GetProjectByName(name string) (entity.Project, error) {
+	s.nameLookups = append(s.nameLookups, name)
+	for _, project := range s.projects {
+		if strings.EqualFold(project.Name, name) {
+			return project, nil
+		}
+	}
+	return entity.Project{}, er.ErrProjectNotFound
+}
+
+func TestProjectResolver(t *testing.T) {
+	archivedAt := time.Now()
+	projects := []entity.Project{
+		{ID: 1, Name: "Alpha"},
+		{ID: 2, Name: "Beta"},
+		{ID: 3, Name: "Old", ArchivedAt: &archivedAt},
+	}
+	rules := config.ICalImport{
+		CategoryProjects:  map[string]uint{"Dev": 2, "legacy": 3},
+		MatchProjectNames: true,
+		DefaultProjectID:  1,
+	}
+
+	tests := []struct {
+		name       string
+		categories []string
+		want       uint
+		ok         bool
+	}{
+		{"rule ignores case", []string{"DEV"}, 2, true},
+		{"rule before name", []string{"alpha", "dev"}, 2, true},
+		{"name ignores case", []string{"bEtA"}, 2, true},
+		// Правило на архивный проект пропускается
+		{"archived rule", []string{"Legacy", "alpha"}, 1, true},
+		{"archived name", []string{"old"}, 1, true},
+		{"default", []string{"unknown"}, 1, true},
+		{"no categories", nil, 1, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := newProjectResolver(&stubProjects{projects: projects}, rules)
+			id, ok, err := p.resolve(tt.categories)
+			if err != nil || id != tt.want || ok != tt.ok {
+				t.Fatalf("resolve(%q) = %d, %v, %v; want %d, %v", tt.categories, id, ok, err, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestProjectResolverCachesNames(t *testing.T) {
+	stub := &stubProjects{projects: []entity.Project{{ID: 1, Name: "Alpha"}}}
+	p := newProjectResolver(stub, config.ICalImport{MatchProjectNames: true})
+
+	// Результат не зависит от того, какое написание встретилось первым
+	for _, category := range []string{"ALPHA", "alpha", "Alpha"} {
+		if id, ok, err := p.resolve([]string{category}); err != nil || !ok || id != 1 {
+			t.Fatalf("resolve(%q) = %d, %v, %v", category, id, ok, err)
+		}
+	}
+	if len(stub.nameLookups) != 1 || stub.nameLookups[0] != "alpha" {
+		t.Fatalf("name lookups %q, want one for \"alpha\"", stub.nameLookups)
+	}
+}
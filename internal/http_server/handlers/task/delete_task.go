@@ -0,0 +1,95 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/router"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+	"strconv"
+)
+
+type TaskResponseDelete struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+	TaskID uint   `json:"task_id,omitempty"`
+}
+
+type TaskDeleter interface {
+	TaskReportGetter
+	GetTaskByID(ID uint) (entity.Task, error)
+	DeleteTask(actor entity.Actor, ID uint) error
+}
+
+func NewDeleteTaskHandler(deleter TaskDeleter, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// Извлекаем ID задачи из URL
+		taskID, err := strconv.ParseUint(router.Param(r, "id"), 10, 32)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TaskResponseDelete{
+				Status: "error",
+				Error:  "invalid task ID format",
+			})
+			return
+		}
+
+		task, err := deleter.GetTaskByID(uint(taskID))
+		if err != nil {
+			if errors.Is(err, er.ErrTaskNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(TaskResponseDelete{
+					Status: "error",
+					Error:  "task not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get task", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TaskResponseDelete{
+				Status: "error",
+				Error:  "failed to get task",
+			})
+			return
+		}
+
+		if status, msg := checkTaskReport(r.Context(), deleter, task.ReportID, pol.EditReport); status != 0 {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(TaskResponseDelete{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		if err := deleter.DeleteTask(auth.Actor(r.Context()), uint(taskID)); err != nil {
+			if errors.Is(err, er.ErrTaskNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(TaskResponseDelete{
+					Status: "error",
+					Error:  "task not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to delete task", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TaskResponseDelete{
+				Status: "error",
+				Error:  "failed to delete task",
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(TaskResponseDelete{
+			Status: "ok",
+			TaskID: uint(taskID),
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/http_server/etag"
+	"goproject/internal/http_server/router"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+	"strconv"
+)
+
+// TaskResponseGet - структура ответа для получения задачи
+type TaskResponseGet struct {
+	Status string      `json:"status"`
+	Error  string      `json:"error,omitempty"`
+	Task   entity.Task `json:"task,omitempty"`
+}
+
+// TaskGetter - интерфейс для получения задачи
+type TaskGetter interface {
+	TaskReportGetter
+	GetTaskByID(ID uint) (entity.Task, error)
+}
+
+// NewGetTaskByIdHandler создает обработчик для получения задачи по ID
+func NewGetTaskByIdHandler(getter TaskGetter, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// Извлекаем ID задачи из URL
+		taskID, err := strconv.ParseUint(router.Param(r, "id"), 10, 32)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TaskResponseGet{
+				Status: "error",
+				Error:  "invalid task ID format",
+			})
+			return
+		}
+
+		task, err := getter.GetTaskByID(uint(taskID))
+		if err != nil {
+			if errors.Is(err, er.ErrTaskNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(TaskResponseGet{
+					Status: "error",
+					Error:  "task not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get task", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TaskResponseGet{
+				Status: "error",
+				Error:  "failed to get task",
+			})
+			return
+		}
+
+		if status, msg := checkTaskReport(r.Context(), getter, task.ReportID, pol.ReadReport); status != 0 {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(TaskResponseGet{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		etag.Set(w, task.ModifiedAt)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(TaskResponseGet{
+			Status: "success",
+			Task:   task,
+		})
+	}
+}
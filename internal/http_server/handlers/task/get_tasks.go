@@ -0,0 +1,77 @@
+package task
+
+import (
+	"encoding/json"
+	"goproject/internal/http_server/listparams"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+)
+
+type TaskResponseGetAll struct {
+	Status     string        `json:"status"`
+	Error      string        `json:"error,omitempty"`
+	Tasks      []entity.Task `json:"tasks,omitempty"`
+	NextCursor string        `json:"next_cursor,omitempty"`
+}
+
+type TaskGetterGetAll interface {
+	GetTasks(params er.ListParams) ([]entity.Task, string, error)
+}
+
+// NewGetAllTaskHandler отдает задачи постранично. Параметры: limit, cursor,
+// sort (start_timestamp, created_at, id; "-" - по убыванию), developer,
+// project, from, to (по времени начала задачи).
+func NewGetAllTaskHandler(getter TaskGetterGetAll, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		params, err := listparams.Parse(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TaskResponseGetAll{
+				Status: "error",
+				Error:  err.Error(),
+			})
+			return
+		}
+
+		if err := pol.ScopeList(r.Context(), &params); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(TaskResponseGetAll{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		tasks, next, err := getter.GetTasks(params)
+		if err != nil {
+			if listparams.IsBadRequest(err) {
+				w.WriteHeader(http.StatusBadRequest)
+				json.NewEncoder(w).Encode(TaskResponseGetAll{
+					Status: "error",
+					Error:  "invalid cursor or sort",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get tasks", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TaskResponseGetAll{
+				Status: "error",
+				Error:  "failed to get tasks",
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(TaskResponseGetAll{
+			Status:     "ok",
+			Tasks:      tasks,
+			NextCursor: next,
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/etag"
+	"goproject/internal/http_server/mergepatch"
+	"goproject/internal/http_server/router"
+	"goproject/internal/http_server/validate"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type TaskPatcher interface {
+	TaskRefsGetter
+	GetTaskByID(ID uint) (entity.Task, error)
+	PatchTask(actor entity.Actor, ID uint, patch entity.TaskPatch, version time.Time) error
+}
+
+// NewPatchTaskHandler частично обновляет задачу по JSON Merge Patch.
+// Результат проверяется целиком, как тело PUT: например, новое окончание
+// сравнивается с прежним началом задачи.
+func NewPatchTaskHandler(patcher TaskPatcher, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		taskID, err := strconv.ParseUint(router.Param(r, "id"), 10, 32)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  "invalid task ID format",
+			})
+			return
+		}
+
+		if err := mergepatch.CheckContentType(r); err != nil {
+			w.WriteHeader(http.StatusUnsupportedMediaType)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  err.Error(),
+			})
+			return
+		}
+
+		ifMatch := r.Header.Get("If-Match")
+		if ifMatch == "" {
+			w.WriteHeader(http.StatusPreconditionRequired)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  "If-Match header is required",
+			})
+			return
+		}
+
+		body, err := io.ReadAll(r.Body)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  "invalid request body",
+			})
+			return
+		}
+
+		existingTask, err := patcher.GetTaskByID(uint(taskID))
+		if err != nil {
+			if errors.Is(err, er.ErrTaskNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(TaskResponse{
+					Status: "error",
+					Error:  "task not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get task", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  "failed to get task",
+			})
+			return
+		}
+
+		if status, msg := checkTaskReport(r.Context(), patcher, existingTask.ReportID, pol.EditReport); status != 0 {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+		if !etag.Match(ifMatch, existingTask.ModifiedAt) {
+			writeVersionConflict(w, existingTask)
+			return
+		}
+
+		req := TaskRequest{
+			ReportID:         existingTask.ReportID,
+			ProjectID:        existingTask.ProjectID,
+			Name:             existingTask.Name,
+			DeveloperNote:    existingTask.DeveloperNote,
+			EstimatePlaned:   existingTask.EstimatePlaned,
+			EstimateProgress: existingTask.EstimateProgress,
+			StartTimestamp:   existingTask.StartTimestamp,
+			EndTimestamp:     existingTask.EndTimestamp,
+		}
+		if err := mergepatch.Apply(&req, body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  err.Error(),
+			})
+			return
+		}
+
+		if fields := validate.Struct(req); fields != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  validate.Message,
+				Fields: fields,
+			})
+			return
+		}
+
+		if status, msg := validateTaskRequest(r.Context(), req, patcher, pol); status != 0 {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		var patch entity.TaskPatch
+		if req.ReportID != existingTask.ReportID {
+			patch.ReportID = entity.Change(req.ReportID)
+		}
+		if req.ProjectID != existingTask.ProjectID {
+			patch.ProjectID = entity.Change(req.ProjectID)
+		}
+		if req.Name != existingTask.Name {
+			patch.Name = entity.Change(req.Name)
+		}
+		if req.DeveloperNote != existingTask.DeveloperNote {
+			patch.DeveloperNote = entity.Change(req.DeveloperNote)
+		}
+		if req.EstimatePlaned != existingTask.EstimatePlaned {
+			patch.EstimatePlaned = entity.Change(req.EstimatePlaned)
+		}
+		if req.EstimateProgress != existingTask.EstimateProgress {
+			patch.EstimateProgress = entity.Change(req.EstimateProgress)
+		}
+		if !req.StartTimestamp.Equal(existingTask.StartTimestamp) {
+			patch.StartTimestamp = entity.Change(req.StartTimestamp)
+		}
+		if !req.EndTimestamp.Equal(existingTask.EndTimestamp) {
+			patch.EndTimestamp = entity.Change(req.EndTimestamp)
+		}
+
+		if err := patcher.PatchTask(auth.Actor(r.Context()), uint(taskID), patch, existingTask.ModifiedAt); err != nil {
+			if errors.Is(err, er.ErrVersionMismatch) {
+				if current, err := patcher.GetTaskByID(uint(taskID)); err == nil {
+					writeVersionConflict(w, current)
+					return
+				}
+			}
+			if errors.Is(err, er.ErrTaskNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(TaskResponse{
+					Status: "error",
+					Error:  "task not found",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrInvalidTaskData) || errors.Is(err, er.ErrCheckViolation) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(TaskResponse{
+					Status: "error",
+					Error:  "invalid task data",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrForeignKeyViolation) || errors.Is(err, er.ErrProjectNotFound) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(TaskResponse{
+					Status: "error",
+					Error:  "report or project not found",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrProjectArchived) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(TaskResponse{
+					Status: "error",
+					Error:  "project is archived",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to patch task", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  "failed to patch task",
+			})
+			return
+		}
+
+		updatedTask, err := patcher.GetTaskByID(uint(taskID))
+		if err != nil {
+			logger.FromContext(r.Context()).Error("failed to get task", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  "failed to get task",
+			})
+			return
+		}
+
+		etag.Set(w, updatedTask.ModifiedAt)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(TaskResponse{
+			Status:    "success",
+			Task:      updatedTask,
+			Timestamp: time.Now(),
+		})
+	}
+}
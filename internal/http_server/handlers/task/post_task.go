@@ -0,0 +1,105 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/validate"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+)
+
+type TaskResponsePost struct {
+	Status string                `json:"status"`
+	Error  string                `json:"error,omitempty"`
+	Fields []validate.FieldError `json:"fields,omitempty"`
+	Task   entity.Task           `json:"task,omitempty"`
+}
+
+type TaskSaver interface {
+	TaskRefsGetter
+	SaveTask(actor entity.Actor, task entity.Task) (int, error)
+}
+
+func NewTaskHandler(saver TaskSaver, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		var req TaskRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TaskResponsePost{
+				Status: "error",
+				Error:  "failed to decode request",
+			})
+			return
+		}
+
+		if fields := validate.Struct(req); fields != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(TaskResponsePost{
+				Status: "error",
+				Error:  validate.Message,
+				Fields: fields,
+			})
+			return
+		}
+
+		if status, msg := validateTaskRequest(r.Context(), req, saver, pol); status != 0 {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(TaskResponsePost{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		task := req.toEntity()
+
+		id, err := saver.SaveTask(auth.Actor(r.Context()), task)
+		if err != nil {
+			if errors.Is(err, er.ErrInvalidTaskData) || errors.Is(err, er.ErrCheckViolation) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(TaskResponsePost{
+					Status: "error",
+					Error:  "invalid task data",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrForeignKeyViolation) || errors.Is(err, er.ErrProjectNotFound) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(TaskResponsePost{
+					Status: "error",
+					Error:  "report or project not found",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrProjectArchived) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(TaskResponsePost{
+					Status: "error",
+					Error:  "project is archived",
+				})
+				return
+			}
+
+			logger.FromContext(r.Context()).Error("failed to save task", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TaskResponsePost{
+				Status: "error",
+				Error:  "failed to save task",
+			})
+			return
+		}
+		task.ID = uint(id)
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(TaskResponsePost{
+			Status: "ok",
+			Task:   task,
+		})
+	}
+}
@@ -0,0 +1,210 @@
+package task
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/etag"
+	"goproject/internal/http_server/router"
+	"goproject/internal/http_server/validate"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// TaskResponse - структура ответа на обновление задачи
+type TaskResponse struct {
+	Status    string                `json:"status"`
+	Error     string                `json:"error,omitempty"`
+	Fields    []validate.FieldError `json:"fields,omitempty"`
+	Task      entity.Task           `json:"task,omitempty"`
+	Timestamp time.Time             `json:"timestamp"`
+}
+
+type TaskUpdater interface {
+	TaskRefsGetter
+	GetTaskByID(ID uint) (entity.Task, error)
+	UpdateTask(actor entity.Actor, ID uint, task entity.Task, version time.Time) error
+}
+
+// NewUpdateTaskHandler заменяет поля задачи. Запрос должен содержать If-Match
+// с ETag задачи из GET; если задача с тех пор изменилась, ответ 412 содержит
+// ее текущую версию.
+func NewUpdateTaskHandler(updater TaskUpdater, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		// Извлекаем ID задачи из URL
+		taskID, err := strconv.ParseUint(router.Param(r, "id"), 10, 32)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  "invalid task ID format",
+			})
+			return
+		}
+
+		ifMatch := r.Header.Get("If-Match")
+		if ifMatch == "" {
+			w.WriteHeader(http.StatusPreconditionRequired)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  "If-Match header is required",
+			})
+			return
+		}
+
+		var req TaskRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  "invalid request body",
+			})
+			return
+		}
+
+		if fields := validate.Struct(req); fields != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  validate.Message,
+				Fields: fields,
+			})
+			return
+		}
+
+		// Проверяем существование задачи
+		existingTask, err := updater.GetTaskByID(uint(taskID))
+		if err != nil {
+			if errors.Is(err, er.ErrTaskNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(TaskResponse{
+					Status: "error",
+					Error:  "task not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get task", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  "failed to get task",
+			})
+			return
+		}
+
+		// Задачу можно перенести только между отчетами, которые разрешено изменять
+		if status, msg := checkTaskReport(r.Context(), updater, existingTask.ReportID, pol.EditReport); status != 0 {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+		if !etag.Match(ifMatch, existingTask.ModifiedAt) {
+			writeVersionConflict(w, existingTask)
+			return
+		}
+
+		if status, msg := validateTaskRequest(r.Context(), req, updater, pol); status != 0 {
+			w.WriteHeader(status)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		updatedTask := req.toEntity()
+		updatedTask.ID = uint(taskID)
+		updatedTask.CreatedAt = existingTask.CreatedAt
+
+		if err := updater.UpdateTask(auth.Actor(r.Context()), uint(taskID), updatedTask, existingTask.ModifiedAt); err != nil {
+			if errors.Is(err, er.ErrVersionMismatch) {
+				// Задачу изменили между чтением и записью
+				if current, err := updater.GetTaskByID(uint(taskID)); err == nil {
+					writeVersionConflict(w, current)
+					return
+				}
+			}
+			if errors.Is(err, er.ErrTaskNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(TaskResponse{
+					Status: "error",
+					Error:  "task not found",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrInvalidTaskData) || errors.Is(err, er.ErrCheckViolation) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(TaskResponse{
+					Status: "error",
+					Error:  "invalid task data",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrForeignKeyViolation) || errors.Is(err, er.ErrProjectNotFound) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(TaskResponse{
+					Status: "error",
+					Error:  "report or project not found",
+				})
+				return
+			}
+			if errors.Is(err, er.ErrProjectArchived) {
+				w.WriteHeader(http.StatusUnprocessableEntity)
+				json.NewEncoder(w).Encode(TaskResponse{
+					Status: "error",
+					Error:  "project is archived",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to update task", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  "failed to update task",
+			})
+			return
+		}
+
+		updatedTask, err = updater.GetTaskByID(uint(taskID))
+		if err != nil {
+			logger.FromContext(r.Context()).Error("failed to get task", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TaskResponse{
+				Status: "error",
+				Error:  "failed to get task",
+			})
+			return
+		}
+
+		etag.Set(w, updatedTask.ModifiedAt)
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(TaskResponse{
+			Status:    "success",
+			Task:      updatedTask,
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+// writeVersionConflict отвечает 412 с текущей версией задачи, чтобы клиент
+// мог повторить изменение поверх нее
+func writeVersionConflict(w http.ResponseWriter, current entity.Task) {
+	etag.Set(w, current.ModifiedAt)
+	w.WriteHeader(http.StatusPreconditionFailed)
+	json.NewEncoder(w).Encode(TaskResponse{
+		Status:    "error",
+		Error:     "task was modified, retry with the current version",
+		Task:      current,
+		Timestamp: time.Now(),
+	})
+}
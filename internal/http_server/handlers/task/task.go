@@ -0,0 +1,90 @@
+package task
+
+import (
+	"context"
+	"errors"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+	"time"
+)
+
+// TaskRequest - тело запроса на создание и обновление задачи
+type TaskRequest struct {
+	ReportID         uint      `json:"report_id" validate:"required"`
+	ProjectID        uint      `json:"project_id" validate:"required"`
+	Name             string    `json:"name" validate:"required,max=255"`
+	DeveloperNote    string    `json:"developer_note,omitempty"`
+	EstimatePlaned   int       `json:"estimate_planed" validate:"required,min=1"`
+	EstimateProgress int       `json:"estimate_progress" validate:"min=0"`
+	StartTimestamp   time.Time `json:"start_timestamp" validate:"required"`
+	EndTimestamp     time.Time `json:"end_timestamp" validate:"required,gtfield=StartTimestamp"`
+}
+
+// TaskReportGetter - интерфейс для получения отчета, к которому относится задача
+type TaskReportGetter interface {
+	GetReportById(id uint) (entity.Report, error)
+}
+
+// TaskRefsGetter - интерфейс для проверки отчета и проекта, на которые ссылается задача
+type TaskRefsGetter interface {
+	TaskReportGetter
+	GetProjectByID(ID uint) (entity.Project, error)
+}
+
+func (req TaskRequest) toEntity() entity.Task {
+	return entity.Task{
+		ReportID:         req.ReportID,
+		ProjectID:        req.ProjectID,
+		Name:             req.Name,
+		DeveloperNote:    req.DeveloperNote,
+		EstimatePlaned:   req.EstimatePlaned,
+		EstimateProgress: req.EstimateProgress,
+		StartTimestamp:   req.StartTimestamp,
+		EndTimestamp:     req.EndTimestamp,
+	}
+}
+
+// checkTaskReport проверяет право на отчет, к которому относится задача.
+// check - метод политики (ReadReport или EditReport). Возвращает HTTP-статус
+// и текст ошибки, либо 0, если доступ разрешен.
+func checkTaskReport(ctx context.Context, reports TaskReportGetter, reportID uint, check func(context.Context, entity.Report) error) (int, string) {
+	report, err := reports.GetReportById(reportID)
+	if err != nil {
+		logger.FromContext(ctx).Error("failed to get report", logger.Err(err))
+		return http.StatusInternalServerError, "failed to get report"
+	}
+	if err := check(ctx, report); err != nil {
+		return policy.Status(ctx, err)
+	}
+	return 0, ""
+}
+
+// validateTaskRequest проверяет существование связанных отчета и проекта и
+// право изменять отчет; поля уже проверены по тегам validate. Возвращает
+// HTTP-статус и текст ошибки, либо 0, если запрос корректен.
+func validateTaskRequest(ctx context.Context, req TaskRequest, refs TaskRefsGetter, pol *policy.Policy) (int, string) {
+	report, err := refs.GetReportById(req.ReportID)
+	if err != nil {
+		if errors.Is(err, er.ErrReportNotFound) {
+			return http.StatusBadRequest, "report not found"
+		}
+		logger.FromContext(ctx).Error("failed to get report", logger.Err(err))
+		return http.StatusInternalServerError, "failed to get report"
+	}
+	if err := pol.EditReport(ctx, report); err != nil {
+		return policy.Status(ctx, err)
+	}
+
+	if _, err := refs.GetProjectByID(req.ProjectID); err != nil {
+		if errors.Is(err, er.ErrProjectNotFound) {
+			return http.StatusBadRequest, "project not found"
+		}
+		logger.FromContext(ctx).Error("failed to get project", logger.Err(err))
+		return http.StatusInternalServerError, "failed to get project"
+	}
+
+	return 0, ""
+}
@@ -0,0 +1,88 @@
+package tokens
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/router"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+)
+
+type TokenResponseDelete struct {
+	Status  string `json:"status"`
+	Error   string `json:"error,omitempty"`
+	TokenID uint   `json:"token_id,omitempty"`
+}
+
+type TokenRevoker interface {
+	RevokeToken(actor entity.Actor, developerID uuid.UUID, ID uint) error
+}
+
+// NewRevokeTokenHandler отзывает токен разработчика; запросы с ним сразу
+// перестают проходить аутентификацию
+func NewRevokeTokenHandler(revoker TokenRevoker, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := pol.ManageDevelopers(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(TokenResponseDelete{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		developerID, err := uuid.Parse(router.Param(r, "id"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TokenResponseDelete{
+				Status: "error",
+				Error:  "invalid developer ID format",
+			})
+			return
+		}
+
+		tokenID, err := strconv.ParseUint(router.Param(r, "token_id"), 10, 32)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TokenResponseDelete{
+				Status: "error",
+				Error:  "invalid token ID format",
+			})
+			return
+		}
+
+		if err := revoker.RevokeToken(auth.Actor(r.Context()), developerID, uint(tokenID)); err != nil {
+			if errors.Is(err, er.ErrTokenNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(TokenResponseDelete{
+					Status: "error",
+					Error:  "token not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to revoke token", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TokenResponseDelete{
+				Status: "error",
+				Error:  "failed to revoke token",
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(TokenResponseDelete{
+			Status:  "ok",
+			TokenID: uint(tokenID),
+		})
+	}
+}
@@ -0,0 +1,87 @@
+package tokens
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/http_server/router"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type TokenResponseGetAll struct {
+	Status string            `json:"status"`
+	Error  string            `json:"error,omitempty"`
+	Tokens []entity.APIToken `json:"tokens,omitempty"`
+}
+
+type TokensGetter interface {
+	GetDeveloperByID(uid uuid.UUID) (entity.Developer, error)
+	GetTokens(developerID uuid.UUID) ([]entity.APIToken, error)
+}
+
+// NewGetTokensHandler отдает токены разработчика без самих значений токенов
+func NewGetTokensHandler(getter TokensGetter, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := pol.ManageDevelopers(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(TokenResponseGetAll{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		developerID, err := uuid.Parse(router.Param(r, "id"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TokenResponseGetAll{
+				Status: "error",
+				Error:  "invalid developer ID format",
+			})
+			return
+		}
+
+		if _, err := getter.GetDeveloperByID(developerID); err != nil {
+			if errors.Is(err, er.ErrDeveloperNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(TokenResponseGetAll{
+					Status: "error",
+					Error:  "developer not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get developer", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TokenResponseGetAll{
+				Status: "error",
+				Error:  "failed to get developer",
+			})
+			return
+		}
+
+		tokens, err := getter.GetTokens(developerID)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("failed to get tokens", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TokenResponseGetAll{
+				Status: "error",
+				Error:  "failed to get tokens",
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(TokenResponseGetAll{
+			Status: "ok",
+			Tokens: tokens,
+		})
+	}
+}
@@ -0,0 +1,137 @@
+package tokens
+
+import (
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/router"
+	"goproject/internal/http_server/validate"
+	"goproject/internal/logger"
+	"goproject/internal/policy"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"io"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+type TokenRequestPost struct {
+	Name string `json:"name" validate:"max=100"`
+}
+
+type TokenResponsePost struct {
+	Status string                `json:"status"`
+	Error  string                `json:"error,omitempty"`
+	Fields []validate.FieldError `json:"fields,omitempty"`
+	// Token возвращается только при создании; в хранилище остается его хэш
+	Token     string           `json:"token,omitempty"`
+	TokenInfo *entity.APIToken `json:"token_info,omitempty"`
+}
+
+type TokenCreator interface {
+	GetDeveloperByID(uid uuid.UUID) (entity.Developer, error)
+	SaveToken(actor entity.Actor, token entity.APIToken, hash string) (entity.APIToken, error)
+}
+
+// NewCreateTokenHandler выпускает API-токен разработчику. Тело запроса
+// необязательно: name - подпись токена для администратора.
+func NewCreateTokenHandler(creator TokenCreator, pol *policy.Policy) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		if err := pol.ManageDevelopers(r.Context()); err != nil {
+			code, msg := policy.Status(r.Context(), err)
+			w.WriteHeader(code)
+			json.NewEncoder(w).Encode(TokenResponsePost{
+				Status: "error",
+				Error:  msg,
+			})
+			return
+		}
+
+		developerID, err := uuid.Parse(router.Param(r, "id"))
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TokenResponsePost{
+				Status: "error",
+				Error:  "invalid developer ID format",
+			})
+			return
+		}
+
+		var req TokenRequestPost
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(TokenResponsePost{
+				Status: "error",
+				Error:  "failed to decode request",
+			})
+			return
+		}
+
+		if fields := validate.Struct(req); fields != nil {
+			w.WriteHeader(http.StatusUnprocessableEntity)
+			json.NewEncoder(w).Encode(TokenResponsePost{
+				Status: "error",
+				Error:  validate.Message,
+				Fields: fields,
+			})
+			return
+		}
+
+		developer, err := creator.GetDeveloperByID(developerID)
+		if err == nil && developer.DeletedAt != nil {
+			err = er.ErrDeveloperNotFound
+		}
+		if err != nil {
+			if errors.Is(err, er.ErrDeveloperNotFound) {
+				w.WriteHeader(http.StatusNotFound)
+				json.NewEncoder(w).Encode(TokenResponsePost{
+					Status: "error",
+					Error:  "developer not found",
+				})
+				return
+			}
+			logger.FromContext(r.Context()).Error("failed to get developer", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TokenResponsePost{
+				Status: "error",
+				Error:  "failed to get developer",
+			})
+			return
+		}
+
+		token, hash, err := auth.NewToken()
+		if err != nil {
+			logger.FromContext(r.Context()).Error("failed to generate token", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TokenResponsePost{
+				Status: "error",
+				Error:  "failed to generate token",
+			})
+			return
+		}
+
+		saved, err := creator.SaveToken(auth.Actor(r.Context()), entity.APIToken{
+			DeveloperID: developerID,
+			Name:        req.Name,
+		}, hash)
+		if err != nil {
+			logger.FromContext(r.Context()).Error("failed to save token", logger.Err(err))
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(TokenResponsePost{
+				Status: "error",
+				Error:  "failed to save token",
+			})
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(TokenResponsePost{
+			Status:    "ok",
+			Token:     token,
+			TokenInfo: &saved,
+		})
+	}
+}
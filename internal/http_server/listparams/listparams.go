@@ -0,0 +1,80 @@
+// Package listparams разбирает параметры постраничных списков из query string
+package listparams
+
+import (
+	"errors"
+	er "goproject/internal/storage"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Parse читает limit, cursor, sort, developer, project, from, to, include_deleted,
+// а для журнала аудита - entity и id.
+// Даты принимаются в формате 2006-01-02 или RFC3339. Текст ошибки можно
+// отдавать клиенту как есть.
+func Parse(r *http.Request) (er.ListParams, error) {
+	query := r.URL.Query()
+
+	params := er.ListParams{
+		Cursor:   query.Get("cursor"),
+		Sort:     query.Get("sort"),
+		Entity:   query.Get("entity"),
+		EntityID: query.Get("id"),
+	}
+
+	var err error
+	if v := query.Get("limit"); v != "" {
+		params.Limit, err = strconv.Atoi(v)
+		if err != nil || params.Limit <= 0 {
+			return er.ListParams{}, errors.New("limit must be a positive integer")
+		}
+	}
+	if v := query.Get("developer"); v != "" {
+		if params.DeveloperID, err = uuid.Parse(v); err != nil {
+			return er.ListParams{}, errors.New("invalid developer ID format")
+		}
+	}
+	if v := query.Get("project"); v != "" {
+		id, err := strconv.ParseUint(v, 10, 64)
+		if err != nil || id == 0 {
+			return er.ListParams{}, errors.New("invalid project ID format")
+		}
+		params.ProjectID = uint(id)
+	}
+	if v := query.Get("from"); v != "" {
+		if params.From, err = parseTime(v); err != nil {
+			return er.ListParams{}, errors.New("invalid from format")
+		}
+	}
+	if v := query.Get("to"); v != "" {
+		if params.To, err = parseTime(v); err != nil {
+			return er.ListParams{}, errors.New("invalid to format")
+		}
+	}
+	if v := query.Get("include_deleted"); v != "" {
+		if params.IncludeDeleted, err = strconv.ParseBool(v); err != nil {
+			return er.ListParams{}, errors.New("include_deleted must be true or false")
+		}
+	}
+	if !params.From.IsZero() && !params.To.IsZero() && !params.To.After(params.From) {
+		return er.ListParams{}, errors.New("to must be after from")
+	}
+
+	return params, nil
+}
+
+// IsBadRequest сообщает, что ошибка хранилища вызвана параметрами запроса
+// (неверный курсор или поле сортировки)
+func IsBadRequest(err error) bool {
+	return errors.Is(err, er.ErrInvalidCursor) || errors.Is(err, er.ErrInvalidSort)
+}
+
+func parseTime(v string) (time.Time, error) {
+	if t, err := time.Parse("2006-01-02", v); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
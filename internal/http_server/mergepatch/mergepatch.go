@@ -0,0 +1,107 @@
+// Package mergepatch применяет документы JSON Merge Patch (RFC 7396) к
+// структурам запросов. Поле, отсутствующее в патче, сохраняет текущее
+// значение, null удаляет его (структура получает нулевое значение), вложенные
+// объекты сливаются рекурсивно.
+package mergepatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"reflect"
+)
+
+// ContentType - тип тела PATCH-запроса
+const ContentType = "application/merge-patch+json"
+
+var (
+	// ErrNotObject - патч не является JSON-объектом. RFC 7396 разрешает
+	// заменять документ целиком, но ресурс API - всегда объект.
+	ErrNotObject = errors.New("merge patch must be a JSON object")
+
+	// ErrContentType - тело передано не как merge patch или JSON
+	ErrContentType = errors.New("content type must be " + ContentType)
+)
+
+// CheckContentType разрешает application/merge-patch+json и, для простых
+// клиентов, application/json
+func CheckContentType(r *http.Request) error {
+	header := r.Header.Get("Content-Type")
+	if header == "" {
+		return nil
+	}
+	mediaType, _, err := mime.ParseMediaType(header)
+	if err != nil || (mediaType != ContentType && mediaType != "application/json") {
+		return ErrContentType
+	}
+	return nil
+}
+
+// Apply применяет patch к текущему представлению target (указатель на
+// структуру с тегами json) и записывает результат обратно в target.
+// Поля патча, которых нет в структуре, - ошибка.
+func Apply(target interface{}, patch []byte) error {
+	var p interface{}
+	if err := decode(patch, &p); err != nil {
+		return fmt.Errorf("invalid merge patch: %w", err)
+	}
+	if _, ok := p.(map[string]interface{}); !ok {
+		return ErrNotObject
+	}
+
+	current, err := json.Marshal(target)
+	if err != nil {
+		return fmt.Errorf("marshal target: %w", err)
+	}
+	var doc interface{}
+	if err := decode(current, &doc); err != nil {
+		return fmt.Errorf("decode target: %w", err)
+	}
+
+	merged, err := json.Marshal(merge(doc, p))
+	if err != nil {
+		return fmt.Errorf("marshal merged document: %w", err)
+	}
+
+	// Результат декодируется в пустую структуру, чтобы удаленные через
+	// null поля получили нулевое значение
+	v := reflect.ValueOf(target).Elem()
+	v.Set(reflect.Zero(v.Type()))
+
+	dec := json.NewDecoder(bytes.NewReader(merged))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(target); err != nil {
+		return fmt.Errorf("invalid merge patch: %w", err)
+	}
+	return nil
+}
+
+// merge - алгоритм MergePatch из RFC 7396
+func merge(target, patch interface{}) interface{} {
+	p, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+	t, ok := target.(map[string]interface{})
+	if !ok {
+		t = make(map[string]interface{})
+	}
+	for key, value := range p {
+		if value == nil {
+			delete(t, key)
+			continue
+		}
+		t[key] = merge(t[key], value)
+	}
+	return t
+}
+
+// decode читает JSON, сохраняя числа без потери точности
+func decode(data []byte, v interface{}) error {
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	return dec.Decode(v)
+}
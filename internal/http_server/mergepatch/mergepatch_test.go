@@ -0,0 +1,194 @@
+package mergepatch
+
+import (
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type address struct {
+	City   string `json:"city"`
+	Street string `json:"street,omitempty"`
+}
+
+type request struct {
+	Name      string            `json:"name"`
+	Note      string            `json:"note,omitempty"`
+	Estimate  int               `json:"estimate"`
+	ManagerID *uuid.UUID        `json:"manager_id,omitempty"`
+	Start     time.Time         `json:"start"`
+	Address   *address          `json:"address,omitempty"`
+	Labels    map[string]string `json:"labels,omitempty"`
+	Tags      []string          `json:"tags,omitempty"`
+}
+
+func TestApply(t *testing.T) {
+	manager := uuid.MustParse("7d4f7a1e-6c1b-4a58-9d8e-2f0c7f1a3b5c")
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	current := func() request {
+		return request{
+			Name:      "task",
+			Note:      "note",
+			Estimate:  3,
+			ManagerID: &manager,
+			Start:     start,
+			Address:   &address{City: "Riga", Street: "Brivibas"},
+			Labels:    map[string]string{"a": "1", "b": "2"},
+			Tags:      []string{"x", "y"},
+		}
+	}
+
+	tests := []struct {
+		name  string
+		patch string
+		want  func(r *request)
+	}{
+		{"empty patch changes nothing", `{}`, func(r *request) {}},
+		{"value replaces field", `{"name":"renamed","estimate":5}`, func(r *request) {
+			r.Name, r.Estimate = "renamed", 5
+		}},
+		{"null resets field to zero", `{"note":null,"estimate":null}`, func(r *request) {
+			r.Note, r.Estimate = "", 0
+		}},
+		{"null resets pointer", `{"manager_id":null}`, func(r *request) {
+			r.ManagerID = nil
+		}},
+		{"time value is parsed", `{"start":"2026-10-02T10:30:00Z"}`, func(r *request) {
+			r.Start = time.Date(2026, 10, 2, 10, 30, 0, 0, time.UTC)
+		}},
+		{"nested object is merged", `{"address":{"street":"Elizabetes"}}`, func(r *request) {
+			r.Address = &address{City: "Riga", Street: "Elizabetes"}
+		}},
+		{"null inside nested object resets only that field", `{"address":{"street":null}}`, func(r *request) {
+			r.Address = &address{City: "Riga"}
+		}},
+		{"null removes nested object", `{"address":null}`, func(r *request) {
+			r.Address = nil
+		}},
+		{"map keys are merged and removed", `{"labels":{"b":null,"c":"3"}}`, func(r *request) {
+			r.Labels = map[string]string{"a": "1", "c": "3"}
+		}},
+		{"array is replaced as a whole", `{"tags":["z"]}`, func(r *request) {
+			r.Tags = []string{"z"}
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := current()
+			if err := Apply(&got, []byte(tt.patch)); err != nil {
+				t.Fatal(err)
+			}
+			want := current()
+			tt.want(&want)
+			if !reflect.DeepEqual(got, want) {
+				t.Fatalf("got %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+// TestApplyOmitempty проверяет, что поля с omitempty, пропавшие из текущего
+// представления, переживают круг маршалинга без изменений
+func TestApplyOmitempty(t *testing.T) {
+	tests := []struct {
+		name    string
+		current request
+		patch   string
+		want    request
+	}{
+		{"zero omitempty fields stay zero", request{Name: "task"}, `{"estimate":1}`, request{Name: "task", Estimate: 1}},
+		{"set omitempty field is kept", request{Name: "task", Note: "keep"}, `{"name":"renamed"}`, request{Name: "renamed", Note: "keep"}},
+		{"patch sets absent omitempty field", request{Name: "task"}, `{"note":"new","address":{"city":"Riga"}}`, request{Name: "task", Note: "new", Address: &address{City: "Riga"}}},
+		{"null on absent field is a no-op", request{Name: "task"}, `{"note":null,"address":null}`, request{Name: "task"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := tt.current
+			if err := Apply(&got, []byte(tt.patch)); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		patch   string
+		want    error
+		message string
+	}{
+		{"unknown field", `{"owner":"x"}`, nil, "unknown field"},
+		{"unknown nested field", `{"address":{"zip":"LV-1050"}}`, nil, "unknown field"},
+		{"wrong type", `{"estimate":"three"}`, nil, "invalid merge patch"},
+		{"malformed json", `{"name":`, nil, "invalid merge patch"},
+		{"array", `[{"name":"x"}]`, ErrNotObject, ""},
+		{"null document", `null`, ErrNotObject, ""},
+		{"scalar", `"name"`, ErrNotObject, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			target := request{Name: "task", Estimate: 3}
+			before := target
+
+			err := Apply(&target, []byte(tt.patch))
+			if err == nil {
+				t.Fatal("expected an error")
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Fatalf("got %v, want error containing %q", err, tt.message)
+			}
+			// Отклоненный патч, кроме ошибки декодирования результата, не
+			// трогает структуру
+			if tt.want != nil && !reflect.DeepEqual(target, before) {
+				t.Fatalf("target changed: %+v", target)
+			}
+		})
+	}
+}
+
+func TestCheckContentType(t *testing.T) {
+	tests := []struct {
+		header string
+		ok     bool
+	}{
+		{"", true},
+		{ContentType, true},
+		{"application/merge-patch+json; charset=utf-8", true},
+		{"application/json", true},
+		{"application/json-patch+json", false},
+		{"application/x-www-form-urlencoded", false},
+		{"text/plain", false},
+		{"not a media type;;", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.header, func(t *testing.T) {
+			r := httptest.NewRequest("PATCH", "/", nil)
+			if tt.header != "" {
+				r.Header.Set("Content-Type", tt.header)
+			}
+			err := CheckContentType(r)
+			if tt.ok && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !tt.ok && !errors.Is(err, ErrContentType) {
+				t.Fatalf("got %v, want ErrContentType", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+// Package middleware содержит обертки http.Handler, общие для всех маршрутов
+package middleware
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/http_server/router"
+	"goproject/internal/logger"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+	"strings"
+)
+
+type TokenLookup interface {
+	GetDeveloperByToken(hash string) (entity.Developer, error)
+}
+
+// Authenticate требует заголовок "Authorization: Bearer <token>" и кладет
+// клиента в контекст запроса. adminToken из конфигурации аутентифицирует
+// администратора; пустое значение отключает его.
+func Authenticate(lookup TokenLookup, adminToken string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			token, ok := bearerToken(r)
+			if !ok {
+				unauthorized(w, "missing bearer token")
+				return
+			}
+
+			if adminToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(adminToken)) == 1 {
+				ctx := auth.WithPrincipal(r.Context(), auth.Principal{Role: entity.RoleAdmin})
+				next.ServeHTTP(w, r.WithContext(ctx))
+				return
+			}
+
+			developer, err := lookup.GetDeveloperByToken(auth.HashToken(token))
+			if err != nil {
+				if errors.Is(err, er.ErrTokenNotFound) {
+					unauthorized(w, "invalid token")
+					return
+				}
+				logger.FromContext(r.Context()).Error("failed to authenticate", logger.Err(err))
+				writeError(w, http.StatusInternalServerError, "failed to authenticate")
+				return
+			}
+
+			ctx := auth.WithPrincipal(r.Context(), auth.Principal{
+				DeveloperID: developer.ID,
+				Role:        developer.Role,
+			})
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, ok := strings.Cut(header, " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+func unauthorized(w http.ResponseWriter, msg string) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	writeError(w, http.StatusUnauthorized, msg)
+}
+
+func writeError(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	json.NewEncoder(w).Encode(router.ErrorResponse{
+		Status: "error",
+		Error:  msg,
+	})
+}
@@ -0,0 +1,50 @@
+package middleware
+
+import (
+	"goproject/internal/http_server/router"
+	"goproject/internal/metrics"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// unmatchedRoute - метка запросов, не дошедших до маршрута: неизвестный путь
+// или отказ в аутентификации. Сырой путь в метку не пишется, чтобы число
+// рядов не росло от произвольных URL.
+const unmatchedRoute = "unmatched"
+
+// otherMethod - метка нестандартных методов. Клиент может прислать любой
+// токен в качестве метода, и каждый давал бы новый ряд.
+const otherMethod = "other"
+
+// methodLabel возвращает метод для метки метрик
+func methodLabel(method string) string {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPost, http.MethodPut, http.MethodPatch,
+		http.MethodDelete, http.MethodConnect, http.MethodOptions, http.MethodTrace:
+		return method
+	}
+	return otherMethod
+}
+
+// Metrics считает запросы и их длительность по методу и шаблону маршрута
+func Metrics(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ctx, pattern := router.WithRoute(r.Context())
+		rec := &statusRecorder{ResponseWriter: w}
+
+		next.ServeHTTP(rec, r.WithContext(ctx))
+
+		route := *pattern
+		if route == "" {
+			route = unmatchedRoute
+		}
+		if rec.status == 0 {
+			rec.status = http.StatusOK
+		}
+		method := methodLabel(r.Method)
+		metrics.HTTPRequests.Inc(method, route, strconv.Itoa(rec.status))
+		metrics.HTTPDuration.Observe(time.Since(start).Seconds(), method, route)
+	})
+}
@@ -0,0 +1,24 @@
+package middleware
+
+import "testing"
+
+func TestMethodLabel(t *testing.T) {
+	tests := []struct {
+		method string
+		want   string
+	}{
+		{"GET", "GET"},
+		{"PATCH", "PATCH"},
+		{"OPTIONS", "OPTIONS"},
+		// Метод чувствителен к регистру, произвольные токены сводятся в один ряд
+		{"get", "other"},
+		{"PROPFIND", "other"},
+		{"X-RANDOM-1234", "other"},
+	}
+
+	for _, tt := range tests {
+		if got := methodLabel(tt.method); got != tt.want {
+			t.Errorf("methodLabel(%q) = %q, want %q", tt.method, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package middleware
+
+import (
+	"fmt"
+	"goproject/internal/logger"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+)
+
+// Recover перехватывает панику обработчика, пишет ее в лог со стеком и
+// отвечает стандартной JSON-ошибкой 500. Если обработчик уже начал ответ,
+// например потоковую выгрузку, JSON дописать нельзя: соединение обрывается
+// через http.ErrAbortHandler, чтобы клиент не принял ответ за целый.
+func Recover(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rec := &statusRecorder{ResponseWriter: w}
+		defer func() {
+			v := recover()
+			if v == nil {
+				return
+			}
+			// ErrAbortHandler - штатный способ прервать ответ, сервер обработает его сам
+			if v == http.ErrAbortHandler {
+				panic(v)
+			}
+
+			logger.FromContext(r.Context()).Error("panic recovered",
+				slog.String("panic", fmt.Sprint(v)),
+				slog.String("stack", string(debug.Stack())),
+				slog.Bool("response_started", rec.status != 0),
+			)
+			if rec.status != 0 {
+				panic(http.ErrAbortHandler)
+			}
+			writeError(w, http.StatusInternalServerError, "internal server error")
+		}()
+
+		next.ServeHTTP(rec, r)
+	})
+}
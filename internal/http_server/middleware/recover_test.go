@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"encoding/json"
+	"goproject/internal/http_server/router"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// serveRecover вызывает обработчик за Recover и возвращает ответ и панику,
+// вышедшую из middleware
+func serveRecover(h http.HandlerFunc) (rec *httptest.ResponseRecorder, panicked interface{}) {
+	rec = httptest.NewRecorder()
+	defer func() { panicked = recover() }()
+	Recover(h).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	return rec, nil
+}
+
+func TestRecoverBeforeWrite(t *testing.T) {
+	rec, panicked := serveRecover(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	})
+	if panicked != nil {
+		t.Fatalf("panic escaped: %v", panicked)
+	}
+	if rec.Code != http.StatusInternalServerError || rec.Header().Get("Content-Type") != "application/json" {
+		t.Fatalf("got %d, Content-Type %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	var body router.ErrorResponse
+	if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Error != "internal server error" {
+		t.Fatalf("body %+v, %v", body, err)
+	}
+}
+
+func TestRecoverAfterWrite(t *testing.T) {
+	tests := []struct {
+		name    string
+		handler http.HandlerFunc
+		code    int
+		body    string
+	}{
+		{"header only", func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusAccepted)
+			panic("boom")
+		}, http.StatusAccepted, ""},
+		{"partial body", func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "text/csv")
+			w.Write([]byte("id,name\n1,"))
+			panic("boom")
+		}, http.StatusOK, "id,name\n1,"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec, panicked := serveRecover(tt.handler)
+			// Начатый ответ обрывается, JSON к нему не дописывается
+			if panicked != http.ErrAbortHandler {
+				t.Fatalf("panic %v, want http.ErrAbortHandler", panicked)
+			}
+			if rec.Code != tt.code || rec.Body.String() != tt.body {
+				t.Fatalf("got %d %q, want %d %q", rec.Code, rec.Body.String(), tt.code, tt.body)
+			}
+		})
+	}
+}
+
+func TestRecoverPassesAbortHandler(t *testing.T) {
+	_, panicked := serveRecover(func(w http.ResponseWriter, r *http.Request) {
+		panic(http.ErrAbortHandler)
+	})
+	if panicked != http.ErrAbortHandler {
+		t.Fatalf("panic %v, want http.ErrAbortHandler", panicked)
+	}
+}
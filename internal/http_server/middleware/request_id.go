@@ -0,0 +1,45 @@
+package middleware
+
+import (
+	"goproject/internal/logger"
+	"log/slog"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// RequestIDHeader - заголовок с идентификатором запроса
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLen ограничивает идентификатор от клиента, чтобы он не раздувал логи
+const maxRequestIDLen = 128
+
+// RequestID берет идентификатор запроса из X-Request-ID или генерирует новый,
+// возвращает его в ответе и кладет в контекст логгер с полем request_id
+func RequestID(log *slog.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			id := r.Header.Get(RequestIDHeader)
+			if !validRequestID(id) {
+				id = uuid.NewString()
+			}
+
+			w.Header().Set(RequestIDHeader, id)
+			ctx := logger.WithLogger(r.Context(), log.With(slog.String("request_id", id)))
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// validRequestID пропускает только печатные ASCII-символы без пробелов
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLen {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,89 @@
+package httpserver
+
+import (
+	"bytes"
+	"flag"
+	"goproject/internal/config"
+	"goproject/internal/http_server/handlers/docs"
+	"goproject/internal/http_server/validate"
+	"goproject/internal/storage/memory"
+	"os"
+	"sort"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "rewrite handlers/docs/openapi.json from the handler types")
+
+const specFile = "handlers/docs/openapi.json"
+
+// TestOpenAPISpecIsUpToDate падает, если типы запросов и ответов обработчиков
+// разошлись с опубликованным документом. После намеренного изменения API
+// документ обновляется командой go test ./internal/http_server -update.
+func TestOpenAPISpecIsUpToDate(t *testing.T) {
+	spec, err := OpenAPISpec()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if *update {
+		if err := os.WriteFile(specFile, spec, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	if !bytes.Equal(spec, docs.Spec) {
+		t.Fatalf("%s is out of date with the handler types; run go test ./internal/http_server -update and review the diff", specFile)
+	}
+}
+
+// TestOpenAPICoversRoutes проверяет, что каждый зарегистрированный маршрут
+// описан в operations и в operations нет маршрутов, которых нет в роутере
+func TestOpenAPICoversRoutes(t *testing.T) {
+	repo := memory.New()
+	registered := append(apiRouter(repo, &config.Config{}).Routes(), publicRouter(repo).Routes()...)
+
+	documented := make([]string, 0, len(operations))
+	for _, op := range operations {
+		documented = append(documented, op.Method+" "+op.Path)
+	}
+
+	sort.Strings(registered)
+	sort.Strings(documented)
+
+	missing, stale := diff(registered, documented), diff(documented, registered)
+	for _, route := range missing {
+		t.Errorf("route %s is not described in operations", route)
+	}
+	for _, route := range stale {
+		t.Errorf("operation %s has no registered route", route)
+	}
+}
+
+// diff возвращает элементы a, которых нет в b
+func diff(a, b []string) []string {
+	seen := make(map[string]bool, len(b))
+	for _, s := range b {
+		seen[s] = true
+	}
+	var out []string
+	for _, s := range a {
+		if !seen[s] {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// TestRequestValidateTags проверяет теги validate всех тел запросов из
+// operations: опечатка в теге иначе обнаружится паникой на первом запросе
+func TestRequestValidateTags(t *testing.T) {
+	for _, op := range operations {
+		if op.Request == nil {
+			continue
+		}
+		if err := validate.Check(op.Request); err != nil {
+			t.Errorf("%s %s: %v", op.Method, op.Path, err)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+// createTask создает разработчика, проект и отчет с одной задачей и
+// возвращает пути разработчика, проекта и задачи
+func createTask(t *testing.T, srv *httptest.Server) (developer, project, task string) {
+	t.Helper()
+
+	resp := call(t, srv, http.MethodPost, "/developers", `{"name":"Ann","last_name":"Lee"}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create developer: status %d", resp.StatusCode)
+	}
+	var dev struct {
+		DeveloperID uuid.UUID `json:"developer_id"`
+	}
+	decode(t, resp, &dev)
+
+	projectID := createProject(t, srv, "Alpha")
+
+	report := fmt.Sprintf(`{"developer_id":%q,"tasks":[{"project_id":%d,"name":"task","estimate_planed":2,
+		"start_timestamp":"2026-10-01T09:00:00Z","end_timestamp":"2026-10-01T11:00:00Z"}]}`, dev.DeveloperID, projectID)
+	resp = call(t, srv, http.MethodPost, "/reports", report)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create report: status %d", resp.StatusCode)
+	}
+	var created struct {
+		Tasks []struct {
+			ID uint `json:"id"`
+		} `json:"tasks"`
+	}
+	decode(t, resp, &created)
+
+	return "/developers/" + dev.DeveloperID.String(), fmt.Sprintf("/projects/%d", projectID), fmt.Sprintf("/tasks/%d", created.Tasks[0].ID)
+}
+
+// version возвращает ETag и ModifiedAt ресурса; key - ключ сущности в
+// ответе GET
+func version(t *testing.T, srv *httptest.Server, path, key string) (string, string) {
+	t.Helper()
+	resp := call(t, srv, http.MethodGet, path, "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("get %s: status %d", path, resp.StatusCode)
+	}
+	var body map[string]json.RawMessage
+	decode(t, resp, &body)
+	var entity struct {
+		ModifiedAt string
+	}
+	if err := json.Unmarshal(body[key], &entity); err != nil || entity.ModifiedAt == "" {
+		t.Fatalf("get %s: no %s.ModifiedAt: %v", path, key, err)
+	}
+	return resp.Header.Get("ETag"), entity.ModifiedAt
+}
+
+func patch(t *testing.T, srv *httptest.Server, path, body, ifMatch string) *http.Response {
+	t.Helper()
+	return call(t, srv, http.MethodPatch, path, body, "Content-Type", "application/merge-patch+json", "If-Match", ifMatch)
+}
+
+func TestEmptyPatchKeepsVersion(t *testing.T) {
+	srv := newTestServer(t)
+	developer, project, task := createTask(t, srv)
+
+	tests := []struct {
+		path string
+		key  string
+	}{
+		{project, "project"},
+		{developer, "developer"},
+		{task, "task"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			tag, modified := version(t, srv, tt.path, tt.key)
+
+			resp := patch(t, srv, tt.path, `{}`, tag)
+			if resp.StatusCode != http.StatusOK {
+				t.Fatalf("patch: status %d", resp.StatusCode)
+			}
+			if got := resp.Header.Get("ETag"); got != tag {
+				t.Fatalf("patch response ETag %s, want %s", got, tag)
+			}
+
+			gotTag, gotModified := version(t, srv, tt.path, tt.key)
+			if gotTag != tag || gotModified != modified {
+				t.Fatalf("version changed: %s %s -> %s %s", tag, modified, gotTag, gotModified)
+			}
+		})
+	}
+}
+
+func TestPatch(t *testing.T) {
+	srv := newTestServer(t)
+	developer, project, task := createTask(t, srv)
+
+	tests := []struct {
+		name string
+		path string
+		key  string
+		body string
+		want int
+	}{
+		{"project name reset to null", project, "project", `{"name":null}`, http.StatusUnprocessableEntity},
+		{"developer last name reset to null", developer, "developer", `{"last_name":null}`, http.StatusUnprocessableEntity},
+		{"task name reset to null", task, "task", `{"name":null}`, http.StatusUnprocessableEntity},
+		{"task estimate reset to null", task, "task", `{"estimate_planed":null}`, http.StatusUnprocessableEntity},
+		{"task end moved before start", task, "task", `{"end_timestamp":"2026-10-01T08:00:00Z"}`, http.StatusUnprocessableEntity},
+		{"unknown field", project, "project", `{"owner":"x"}`, http.StatusBadRequest},
+		{"not an object", project, "project", `["name"]`, http.StatusBadRequest},
+		{"optional field reset to null", project, "project", `{"description":null}`, http.StatusOK},
+		{"field changed", task, "task", `{"name":"renamed"}`, http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tag, modified := version(t, srv, tt.path, tt.key)
+
+			resp := patch(t, srv, tt.path, tt.body, tag)
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tt.want)
+			}
+			if tt.want == http.StatusOK {
+				return
+			}
+			// Отклоненный патч не меняет ресурс
+			if gotTag, gotModified := version(t, srv, tt.path, tt.key); gotTag != tag || gotModified != modified {
+				t.Fatalf("rejected patch changed the version: %s -> %s", modified, gotModified)
+			}
+		})
+	}
+}
+
+func TestPatchPreconditions(t *testing.T) {
+	srv := newTestServer(t)
+	id := createProject(t, srv, "Alpha")
+	path := fmt.Sprintf("/projects/%d", id)
+
+	if resp := call(t, srv, http.MethodPatch, path, `{"name":"Beta"}`, "Content-Type", "application/merge-patch+json"); resp.StatusCode != http.StatusPreconditionRequired {
+		t.Fatalf("patch without If-Match: status %d", resp.StatusCode)
+	}
+	if resp := patch(t, srv, path, `{"name":"Beta"}`, `"stale"`); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("patch with stale If-Match: status %d", resp.StatusCode)
+	}
+	if resp := call(t, srv, http.MethodPatch, path, `name=Beta`, "Content-Type", "application/x-www-form-urlencoded", "If-Match", "*"); resp.StatusCode != http.StatusUnsupportedMediaType {
+		t.Fatalf("patch with form body: status %d", resp.StatusCode)
+	}
+}
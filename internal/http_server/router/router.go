@@ -0,0 +1,227 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+// ErrorResponse - стандартный ответ роутера на ненайденный путь или неподдерживаемый метод
+type ErrorResponse struct {
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+type paramsKey struct{}
+
+type routeKey struct{}
+
+type route struct {
+	pattern  string
+	segments []string
+	handlers map[string]http.Handler
+}
+
+// Router сопоставляет запросы с обработчиками по методу и шаблону пути.
+// Шаблоны состоят из сегментов, разделенных "/", где сегмент вида {name}
+// захватывает значение, доступное в обработчике через Param.
+type Router struct {
+	routes []*route
+}
+
+func New() *Router {
+	return &Router{}
+}
+
+// Handle регистрирует обработчик для метода и шаблона пути
+func (rt *Router) Handle(method, pattern string, handler http.Handler) {
+	segments := splitPath(pattern)
+
+	for _, rte := range rt.routes {
+		if equalSegments(rte.segments, segments) {
+			rte.handlers[method] = handler
+			return
+		}
+	}
+
+	rt.routes = append(rt.routes, &route{
+		pattern:  "/" + strings.Join(segments, "/"),
+		segments: segments,
+		handlers: map[string]http.Handler{method: handler},
+	})
+}
+
+func (rt *Router) Get(pattern string, handler http.HandlerFunc) {
+	rt.Handle(http.MethodGet, pattern, handler)
+}
+
+func (rt *Router) Post(pattern string, handler http.HandlerFunc) {
+	rt.Handle(http.MethodPost, pattern, handler)
+}
+
+func (rt *Router) Put(pattern string, handler http.HandlerFunc) {
+	rt.Handle(http.MethodPut, pattern, handler)
+}
+
+func (rt *Router) Patch(pattern string, handler http.HandlerFunc) {
+	rt.Handle(http.MethodPatch, pattern, handler)
+}
+
+func (rt *Router) Delete(pattern string, handler http.HandlerFunc) {
+	rt.Handle(http.MethodDelete, pattern, handler)
+}
+
+func (rt *Router) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	segments := splitPath(r.URL.Path)
+
+	var (
+		best       *route
+		bestParams map[string]string
+		bestScore  int
+	)
+	for _, rte := range rt.routes {
+		params, score, ok := rte.match(segments)
+		if !ok {
+			continue
+		}
+		if best == nil || score > bestScore {
+			best, bestParams, bestScore = rte, params, score
+		}
+	}
+
+	if best == nil {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	if pattern, ok := r.Context().Value(routeKey{}).(*string); ok {
+		*pattern = best.pattern
+	}
+
+	handler, ok := best.handlers[r.Method]
+	if !ok && r.Method == http.MethodHead {
+		handler, ok = best.handlers[http.MethodGet]
+	}
+	if !ok {
+		w.Header().Set("Allow", best.allow())
+		writeError(w, http.StatusMethodNotAllowed, "method not allowed")
+		return
+	}
+
+	if len(bestParams) > 0 {
+		r = r.WithContext(context.WithValue(r.Context(), paramsKey{}, bestParams))
+	}
+	handler.ServeHTTP(w, r)
+}
+
+// Routes возвращает зарегистрированные маршруты в виде "METHOD /pattern"
+// в порядке регистрации шаблонов
+func (rt *Router) Routes() []string {
+	var routes []string
+	for _, rte := range rt.routes {
+		methods := make([]string, 0, len(rte.handlers))
+		for method := range rte.handlers {
+			methods = append(methods, method)
+		}
+		sort.Strings(methods)
+		for _, method := range methods {
+			routes = append(routes, method+" "+rte.pattern)
+		}
+	}
+	return routes
+}
+
+// WithRoute возвращает контекст, в который Router запишет шаблон найденного
+// маршрута, например "/projects/{id}". Нужен middleware, которые оборачивают
+// роутер и узнают маршрут уже после обработки запроса; если маршрут не
+// найден, строка остается пустой.
+func WithRoute(ctx context.Context) (context.Context, *string) {
+	pattern := new(string)
+	return context.WithValue(ctx, routeKey{}, pattern), pattern
+}
+
+// Param возвращает значение параметра пути, захваченного шаблоном маршрута
+func Param(r *http.Request, name string) string {
+	params, _ := r.Context().Value(paramsKey{}).(map[string]string)
+	return params[name]
+}
+
+// match проверяет путь на соответствие маршруту. Оценка растет с числом
+// литеральных сегментов, чтобы /reports/import предпочитался /reports/{id}.
+func (rte *route) match(segments []string) (map[string]string, int, bool) {
+	if len(segments) != len(rte.segments) {
+		return nil, 0, false
+	}
+
+	var params map[string]string
+	score := 0
+	for i, seg := range rte.segments {
+		if name, ok := paramName(seg); ok {
+			if segments[i] == "" {
+				return nil, 0, false
+			}
+			if params == nil {
+				params = make(map[string]string)
+			}
+			params[name] = segments[i]
+			continue
+		}
+		if seg != segments[i] {
+			return nil, 0, false
+		}
+		score++
+	}
+
+	return params, score, true
+}
+
+func (rte *route) allow() string {
+	methods := make([]string, 0, len(rte.handlers)+1)
+	for method := range rte.handlers {
+		methods = append(methods, method)
+	}
+	if _, ok := rte.handlers[http.MethodGet]; ok {
+		if _, ok := rte.handlers[http.MethodHead]; !ok {
+			methods = append(methods, http.MethodHead)
+		}
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
+
+func paramName(segment string) (string, bool) {
+	if len(segment) > 2 && strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}") {
+		return segment[1 : len(segment)-1], true
+	}
+	return "", false
+}
+
+func splitPath(path string) []string {
+	path = strings.Trim(path, "/")
+	if path == "" {
+		return nil
+	}
+	return strings.Split(path, "/")
+}
+
+func equalSegments(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeError(w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Status: "error",
+		Error:  msg,
+	})
+}
@@ -0,0 +1,231 @@
+package router
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// named возвращает обработчик, который пишет в ответ свое имя и параметры
+// id и file
+func named(name string) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Handler", name)
+		w.Write([]byte(name + " id=" + Param(r, "id") + " file=" + Param(r, "file")))
+	}
+}
+
+func serve(rt *Router, method, path string) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	rt.ServeHTTP(rec, httptest.NewRequest(method, path, nil))
+	return rec
+}
+
+func TestMatch(t *testing.T) {
+	rt := New()
+	// Порядок регистрации не важен: выигрывает маршрут с большим числом
+	// литеральных сегментов
+	rt.Get("/developers/{id}/{file}", named("file"))
+	rt.Get("/developers/{id}/calendar.ics", named("ics"))
+	rt.Get("/developers/{id}/calendar", named("calendar"))
+	rt.Get("/developers/{id}", named("developer"))
+	rt.Get("/reports/{id}", named("report"))
+	rt.Post("/reports/import", named("import"))
+	rt.Get("/", named("root"))
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{"/developers/42/calendar.ics", "ics id=42 file="},
+		{"/developers/42/calendar", "calendar id=42 file="},
+		{"/developers/42/notes.txt", "file id=42 file=notes.txt"},
+		{"/developers/42", "developer id=42 file="},
+		{"/developers/42/", "developer id=42 file="},
+		{"/developers/a%20b", "developer id=a b file="},
+		{"/reports/7", "report id=7 file="},
+		{"/", "root id= file="},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			rec := serve(rt, http.MethodGet, tt.path)
+			if rec.Code != http.StatusOK || rec.Body.String() != tt.want {
+				t.Fatalf("got %d %q, want 200 %q", rec.Code, rec.Body.String(), tt.want)
+			}
+		})
+	}
+}
+
+func TestLiteralPrecedenceOverMethods(t *testing.T) {
+	rt := New()
+	rt.Get("/reports/{id}", named("report"))
+	rt.Post("/reports/import", named("import"))
+
+	// Литеральный маршрут выбирается и для метода, которого у него нет:
+	// GET /reports/import - 405, а не отчет с id "import"
+	rec := serve(rt, http.MethodGet, "/reports/import")
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "POST" {
+		t.Fatalf("got %d, Allow %q", rec.Code, rec.Header().Get("Allow"))
+	}
+	if rec := serve(rt, http.MethodPost, "/reports/import"); rec.Body.String() != "import id= file=" {
+		t.Fatalf("POST /reports/import served by %q", rec.Body.String())
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	rt := New()
+	rt.Get("/projects/{id}", named("project"))
+
+	for _, path := range []string{"/projects", "/projects/1/tasks", "/tasks/1", "/projects//tasks"} {
+		t.Run(path, func(t *testing.T) {
+			rec := serve(rt, http.MethodGet, path)
+			if rec.Code != http.StatusNotFound {
+				t.Fatalf("status %d, want 404", rec.Code)
+			}
+			var body ErrorResponse
+			if err := json.NewDecoder(rec.Body).Decode(&body); err != nil || body.Status != "error" || body.Error != "not found" {
+				t.Fatalf("body %+v, %v", body, err)
+			}
+			if ct := rec.Header().Get("Content-Type"); ct != "application/json" {
+				t.Fatalf("Content-Type %q", ct)
+			}
+		})
+	}
+}
+
+func TestMethodNotAllowed(t *testing.T) {
+	rt := New()
+	rt.Get("/projects/{id}", named("get"))
+	rt.Put("/projects/{id}", named("put"))
+	rt.Delete("/projects/{id}", named("delete"))
+	rt.Post("/projects/{id}/archive", named("archive"))
+	rt.Handle(http.MethodHead, "/files/{id}", named("head"))
+	rt.Handle(http.MethodGet, "/files/{id}", named("get"))
+
+	tests := []struct {
+		method string
+		path   string
+		allow  string
+	}{
+		// HEAD добавляется к GET, сам обработчик HEAD не нужен
+		{http.MethodPost, "/projects/1", "DELETE, GET, HEAD, PUT"},
+		{http.MethodPatch, "/projects/1", "DELETE, GET, HEAD, PUT"},
+		{http.MethodGet, "/projects/1/archive", "POST"},
+		{http.MethodHead, "/projects/1/archive", "POST"},
+		// Явный HEAD не повторяется
+		{http.MethodDelete, "/files/1", "GET, HEAD"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			rec := serve(rt, tt.method, tt.path)
+			if rec.Code != http.StatusMethodNotAllowed {
+				t.Fatalf("status %d, want 405", rec.Code)
+			}
+			if got := rec.Header().Get("Allow"); got != tt.allow {
+				t.Fatalf("Allow %q, want %q", got, tt.allow)
+			}
+		})
+	}
+}
+
+func TestHead(t *testing.T) {
+	rt := New()
+	rt.Get("/projects/{id}", named("get"))
+	rt.Handle(http.MethodGet, "/files/{id}", named("get"))
+	rt.Handle(http.MethodHead, "/files/{id}", named("head"))
+
+	// Без обработчика HEAD запрос обслуживает GET; тело отбрасывает
+	// http.Server
+	if rec := serve(rt, http.MethodHead, "/projects/5"); rec.Code != http.StatusOK || rec.Header().Get("X-Handler") != "get" {
+		t.Fatalf("HEAD fallback: %d, handler %q", rec.Code, rec.Header().Get("X-Handler"))
+	}
+	// Зарегистрированный HEAD имеет приоритет
+	if rec := serve(rt, http.MethodHead, "/files/5"); rec.Header().Get("X-Handler") != "head" {
+		t.Fatalf("explicit HEAD: handler %q", rec.Header().Get("X-Handler"))
+	}
+
+	srv := httptest.NewServer(rt)
+	defer srv.Close()
+	resp, err := srv.Client().Head(srv.URL + "/projects/5")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, _ := io.ReadAll(resp.Body)
+	if resp.StatusCode != http.StatusOK || resp.Header.Get("X-Handler") != "get" || len(body) != 0 {
+		t.Fatalf("HEAD over HTTP: %d, handler %q, body %q", resp.StatusCode, resp.Header.Get("X-Handler"), body)
+	}
+}
+
+func TestParam(t *testing.T) {
+	rt := New()
+	var got map[string]string
+	rt.Delete("/developers/{id}/tokens/{token_id}", func(w http.ResponseWriter, r *http.Request) {
+		got = map[string]string{
+			"id":       Param(r, "id"),
+			"token_id": Param(r, "token_id"),
+			"missing":  Param(r, "missing"),
+		}
+	})
+
+	serve(rt, http.MethodDelete, "/developers/7d4f7a1e/tokens/15")
+	want := map[string]string{"id": "7d4f7a1e", "token_id": "15", "missing": ""}
+	for name, value := range want {
+		if got[name] != value {
+			t.Fatalf("Param(%q) = %q, want %q", name, got[name], value)
+		}
+	}
+
+	// Вне роутера параметров нет
+	if v := Param(httptest.NewRequest(http.MethodGet, "/", nil), "id"); v != "" {
+		t.Fatalf("Param outside router = %q", v)
+	}
+}
+
+func TestReregisterAddsMethod(t *testing.T) {
+	rt := New()
+	rt.Get("/projects/{id}", named("get"))
+	// Тот же шаблон с другим методом дополняет маршрут, с тем же - заменяет
+	// обработчик
+	rt.Put("projects/{id}/", named("put"))
+	rt.Get("/projects/{id}", named("get2"))
+
+	if routes := strings.Join(rt.Routes(), "; "); routes != "GET /projects/{id}; PUT /projects/{id}" {
+		t.Fatalf("routes %q", routes)
+	}
+	if rec := serve(rt, http.MethodGet, "/projects/1"); rec.Header().Get("X-Handler") != "get2" {
+		t.Fatalf("GET served by %q", rec.Header().Get("X-Handler"))
+	}
+}
+
+func TestWithRoute(t *testing.T) {
+	rt := New()
+	rt.Get("/projects/{id}", named("get"))
+
+	tests := []struct {
+		method string
+		path   string
+		want   string
+	}{
+		{http.MethodGet, "/projects/1", "/projects/{id}"},
+		// Маршрут найден, хотя метод не поддерживается
+		{http.MethodPost, "/projects/1", "/projects/{id}"},
+		{http.MethodGet, "/unknown", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.path, func(t *testing.T) {
+			r := httptest.NewRequest(tt.method, tt.path, nil)
+			ctx, pattern := WithRoute(r.Context())
+			rt.ServeHTTP(httptest.NewRecorder(), r.WithContext(ctx))
+			if *pattern != tt.want {
+				t.Fatalf("pattern %q, want %q", *pattern, tt.want)
+			}
+		})
+	}
+}
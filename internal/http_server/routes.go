@@ -0,0 +1,101 @@
+package httpserver
+
+import (
+	"goproject/internal/config"
+	"goproject/internal/http_server/handlers/analytics"
+	"goproject/internal/http_server/handlers/audit"
+	"goproject/internal/http_server/handlers/calendar"
+	developers "goproject/internal/http_server/handlers/developers"
+	"goproject/internal/http_server/handlers/docs"
+	"goproject/internal/http_server/handlers/export"
+	"goproject/internal/http_server/handlers/health"
+	"goproject/internal/http_server/handlers/project"
+	"goproject/internal/http_server/handlers/report"
+	"goproject/internal/http_server/handlers/task"
+	"goproject/internal/http_server/handlers/tokens"
+	"goproject/internal/http_server/middleware"
+	"goproject/internal/http_server/router"
+	"goproject/internal/metrics"
+	"goproject/internal/policy"
+	"goproject/internal/storage"
+	"log/slog"
+	"net/http"
+)
+
+// NewRouter монтирует все обработчики API на REST-пути. Запросы к API
+// проходят аутентификацию по API-токену, права проверяет policy.Policy;
+// /healthz, /readyz, /metrics, /openapi.json и /docs доступны без токена.
+// Каждый запрос получает request ID, попадает в журнал запросов, паника
+// обработчика превращается в ответ 500.
+func NewRouter(repo storage.Repository, cfg *config.Config, log *slog.Logger) http.Handler {
+	probes := publicRouter(repo)
+
+	mux := http.NewServeMux()
+	for _, path := range publicPaths {
+		mux.Handle(path, probes)
+	}
+	mux.Handle("/", middleware.Authenticate(repo, cfg.Auth.AdminToken)(apiRouter(repo, cfg)))
+
+	return middleware.RequestID(log)(middleware.AccessLog(middleware.Metrics(middleware.Recover(mux))))
+}
+
+// publicPaths - маршруты publicRouter, доступные без токена
+var publicPaths = []string{"/healthz", "/readyz", "/metrics", "/openapi.json", "/docs"}
+
+func publicRouter(repo storage.Repository) *router.Router {
+	r := router.New()
+	r.Get("/healthz", health.NewHealthzHandler())
+	r.Get("/readyz", health.NewReadyzHandler(repo))
+	r.Get("/metrics", metrics.Default.ServeHTTP)
+	r.Get("/openapi.json", docs.NewSpecHandler())
+	r.Get("/docs", docs.NewPageHandler())
+	return r
+}
+
+func apiRouter(repo storage.Repository, cfg *config.Config) *router.Router {
+	r := router.New()
+	pol := policy.New(repo)
+
+	r.Get("/projects", project.NewGetAllProjectHandler(repo, pol))
+	r.Post("/projects", project.NewProjectHandler(repo, pol))
+	r.Get("/projects/{id}", project.NewGetProjectByIdHandler(repo, pol))
+	r.Put("/projects/{id}", project.NewUpdateProjectHandler(repo, pol))
+	r.Patch("/projects/{id}", project.NewPatchProjectHandler(repo, pol))
+	r.Delete("/projects/{id}", project.NewDeleteProjectHandler(repo, pol))
+	r.Post("/projects/{id}/archive", project.NewArchiveProjectHandler(repo, pol))
+	r.Post("/projects/{id}/restore", project.NewRestoreProjectHandler(repo, pol))
+
+	r.Get("/developers", developers.NewGetAllDeveloperHandler(repo, pol))
+	r.Post("/developers", developers.NewDeveloperHandler(repo, pol))
+	r.Get("/developers/{id}", developers.NewGetDeveloperByIdHandler(repo, pol))
+	r.Put("/developers/{id}", developers.NewUpdateDeveloperHandler(repo, pol))
+	r.Patch("/developers/{id}", developers.NewPatchDeveloperHandler(repo, pol))
+	r.Delete("/developers/{id}", developers.NewDeleteDeveloperHandler(repo, pol))
+	r.Post("/developers/{id}/restore", developers.NewRestoreDeveloperHandler(repo, pol))
+	r.Get("/developers/{id}/tokens", tokens.NewGetTokensHandler(repo, pol))
+	r.Post("/developers/{id}/tokens", tokens.NewCreateTokenHandler(repo, pol))
+	r.Delete("/developers/{id}/tokens/{token_id}", tokens.NewRevokeTokenHandler(repo, pol))
+	r.Get("/developers/{id}/reports", report.NewGetDeveloperReportsHandler(repo, pol))
+	r.Get("/developers/{id}/calendar", calendar.NewGetCalendarHandler(repo, pol))
+	r.Get("/developers/{id}/calendar.ics", calendar.NewGetCalendarICSHandler(repo, pol))
+
+	r.Get("/reports", report.NewGetAllReportHandler(repo, pol))
+	r.Post("/reports", report.NewReportHandler(repo, pol))
+	r.Get("/reports/{id}", report.NewGetReportByIdHandler(repo, pol))
+	r.Post("/reports/{id}/approve", report.NewApproveReportHandler(repo, pol))
+	r.Post("/reports/{id}/import", report.NewImportReportHandler(repo, cfg.ICalImport, pol))
+
+	r.Get("/tasks", task.NewGetAllTaskHandler(repo, pol))
+	r.Post("/tasks", task.NewTaskHandler(repo, pol))
+	r.Get("/tasks/{id}", task.NewGetTaskByIdHandler(repo, pol))
+	r.Put("/tasks/{id}", task.NewUpdateTaskHandler(repo, pol))
+	r.Patch("/tasks/{id}", task.NewPatchTaskHandler(repo, pol))
+	r.Delete("/tasks/{id}", task.NewDeleteTaskHandler(repo, pol))
+
+	r.Get("/analytics", analytics.NewGetAnalyticsHandler(repo, pol))
+	r.Get("/audit", audit.NewGetAuditHandler(repo, pol))
+	r.Get("/exports/tasks", export.NewExportTasksHandler(repo, pol))
+	r.Get("/exports/reports", export.NewExportReportsHandler(repo, pol))
+
+	return r
+}
@@ -0,0 +1,19 @@
+package httpserver
+
+import (
+	"goproject/internal/config"
+	"net/http"
+)
+
+// NewServer создает http-сервер с таймаутами из конфигурации. Timeout
+// ограничивает и чтение запроса, и запись ответа.
+func NewServer(cfg config.HTTPServer, handler http.Handler) *http.Server {
+	return &http.Server{
+		Addr:              cfg.Address,
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.Timeout,
+		ReadTimeout:       cfg.Timeout,
+		WriteTimeout:      cfg.Timeout,
+		IdleTimeout:       cfg.IdleTimeout,
+	}
+}
@@ -0,0 +1,305 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"goproject/internal/config"
+	"goproject/internal/storage/memory"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+const testAdminToken = "test-admin-token"
+
+// newTestServer поднимает полный роутер API поверх хранилища в памяти
+func newTestServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Auth.AdminToken = testAdminToken
+	log := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	srv := httptest.NewServer(NewRouter(memory.New(), cfg, log))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+// call выполняет запрос от имени администратора; заголовки задаются парами
+// имя, значение
+func call(t *testing.T, srv *httptest.Server, method, path, body string, headers ...string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(method, srv.URL+path, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Authorization", "Bearer "+testAdminToken)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+
+	resp, err := srv.Client().Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+	return resp
+}
+
+// decode разбирает тело ответа в v
+func decode(t *testing.T, resp *http.Response, v any) {
+	t.Helper()
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// createProject создает проект и возвращает его ID
+func createProject(t *testing.T, srv *httptest.Server, name string) uint {
+	t.Helper()
+	resp := call(t, srv, http.MethodPost, "/projects", fmt.Sprintf(`{"name":%q}`, name))
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create project: status %d", resp.StatusCode)
+	}
+	var body struct {
+		Project struct {
+			ID uint `json:"id"`
+		} `json:"project"`
+	}
+	decode(t, resp, &body)
+	return body.Project.ID
+}
+
+func TestAuthentication(t *testing.T) {
+	srv := newTestServer(t)
+
+	tests := []struct {
+		name  string
+		path  string
+		token string
+		want  int
+	}{
+		{"api without token", "/projects", "", http.StatusUnauthorized},
+		{"api with wrong token", "/projects", "Bearer wrong", http.StatusUnauthorized},
+		{"api with admin token", "/projects", "Bearer " + testAdminToken, http.StatusOK},
+		{"public probe without token", "/healthz", "", http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req, _ := http.NewRequest(http.MethodGet, srv.URL+tt.path, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", tt.token)
+			}
+			resp, err := srv.Client().Do(req)
+			if err != nil {
+				t.Fatal(err)
+			}
+			resp.Body.Close()
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestProjectLifecycle(t *testing.T) {
+	srv := newTestServer(t)
+	id := createProject(t, srv, "Alpha")
+	path := fmt.Sprintf("/projects/%d", id)
+
+	resp := call(t, srv, http.MethodGet, path, "")
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("get: status %d", resp.StatusCode)
+	}
+	tag := resp.Header.Get("ETag")
+	if tag == "" {
+		t.Fatal("get: no ETag")
+	}
+
+	update := `{"name":"Beta"}`
+	if resp := call(t, srv, http.MethodPut, path, update); resp.StatusCode != http.StatusPreconditionRequired {
+		t.Fatalf("put without If-Match: status %d", resp.StatusCode)
+	}
+	if resp := call(t, srv, http.MethodPut, path, update, "If-Match", `"stale"`); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("put with stale If-Match: status %d", resp.StatusCode)
+	}
+
+	resp = call(t, srv, http.MethodPut, path, update, "If-Match", tag)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("put: status %d", resp.StatusCode)
+	}
+	if resp.Header.Get("ETag") == tag {
+		t.Fatal("put did not change the ETag")
+	}
+
+	// Прежний ETag после обновления больше не подходит
+	if resp := call(t, srv, http.MethodPut, path, `{"name":"Gamma"}`, "If-Match", tag); resp.StatusCode != http.StatusPreconditionFailed {
+		t.Fatalf("put with outdated If-Match: status %d", resp.StatusCode)
+	}
+
+	if resp := call(t, srv, http.MethodDelete, path, ""); resp.StatusCode >= 300 {
+		t.Fatalf("delete: status %d", resp.StatusCode)
+	}
+	if resp := call(t, srv, http.MethodGet, path, ""); resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("get deleted: status %d", resp.StatusCode)
+	}
+}
+
+func TestProjectValidation(t *testing.T) {
+	srv := newTestServer(t)
+
+	tests := []struct {
+		name string
+		body string
+		want int
+	}{
+		{"valid", `{"name":"Alpha"}`, http.StatusCreated},
+		{"name too short", `{"name":"A"}`, http.StatusUnprocessableEntity},
+		{"name missing", `{"description":"no name"}`, http.StatusUnprocessableEntity},
+		{"malformed json", `{"name":`, http.StatusBadRequest},
+		{"unknown manager", `{"name":"Beta","manager_id":"7d4f7a1e-6c1b-4a58-9d8e-2f0c7f1a3b5c"}`, http.StatusUnprocessableEntity},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := call(t, srv, http.MethodPost, "/projects", tt.body)
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	srv := newTestServer(t)
+
+	tests := []struct {
+		path string
+		want int
+	}{
+		{"/projects/999", http.StatusNotFound},
+		{"/tasks/999", http.StatusNotFound},
+		{"/reports/999", http.StatusNotFound},
+		{"/developers/7d4f7a1e-6c1b-4a58-9d8e-2f0c7f1a3b5c", http.StatusNotFound},
+		{"/projects/abc", http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			resp := call(t, srv, http.MethodGet, tt.path, "")
+			if resp.StatusCode != tt.want {
+				t.Fatalf("status %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+}
+
+func TestAudit(t *testing.T) {
+	srv := newTestServer(t)
+	developer, project, task := createTask(t, srv)
+	etag, _ := version(t, srv, project, "project")
+	if resp := patch(t, srv, project, `{"name":"Beta"}`, etag); resp.StatusCode != http.StatusOK {
+		t.Fatalf("rename project: status %d", resp.StatusCode)
+	}
+
+	resp := call(t, srv, http.MethodPost, developer+"/tokens", `{"name":"ci"}`)
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("create token: status %d", resp.StatusCode)
+	}
+	var token struct {
+		Token string `json:"token"`
+	}
+	decode(t, resp, &token)
+
+	type entry struct {
+		EntityType string
+		EntityID   string
+		Action     string
+		ActorRole  string
+	}
+	audit := func(t *testing.T, query string) ([]entry, string) {
+		t.Helper()
+		resp := call(t, srv, http.MethodGet, "/audit"+query, "")
+		if resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET /audit%s: status %d", query, resp.StatusCode)
+		}
+		var body struct {
+			Entries    []entry `json:"entries"`
+			NextCursor string  `json:"next_cursor"`
+		}
+		decode(t, resp, &body)
+		return body.Entries, body.NextCursor
+	}
+	projectID := strings.TrimPrefix(project, "/projects/")
+	taskID := strings.TrimPrefix(task, "/tasks/")
+
+	t.Run("filters", func(t *testing.T) {
+		tests := []struct {
+			query string
+			want  []string
+		}{
+			// По умолчанию новые записи первыми
+			{"?entity=project&id=" + projectID, []string{"project " + projectID + " update", "project " + projectID + " create"}},
+			{"?entity=task", []string{"task " + taskID + " create"}},
+			{"?entity=task&id=999", nil},
+			{"?entity=project&id=" + projectID + "&sort=id", []string{"project " + projectID + " create", "project " + projectID + " update"}},
+			{"?entity=token", []string{"token 1 create"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.query, func(t *testing.T) {
+				entries, _ := audit(t, tt.query)
+				var got []string
+				for _, e := range entries {
+					got = append(got, e.EntityType+" "+e.EntityID+" "+e.Action)
+					if e.ActorRole != "admin" {
+						t.Errorf("actor role %q, want admin", e.ActorRole)
+					}
+				}
+				if strings.Join(got, "; ") != strings.Join(tt.want, "; ") {
+					t.Fatalf("got %q, want %q", got, tt.want)
+				}
+			})
+		}
+	})
+
+	t.Run("pages", func(t *testing.T) {
+		first, next := audit(t, "?entity=project&id="+projectID+"&limit=1")
+		if len(first) != 1 || first[0].Action != "update" || next == "" {
+			t.Fatalf("first page %+v, cursor %q", first, next)
+		}
+		second, next := audit(t, "?entity=project&id="+projectID+"&limit=1&cursor="+next)
+		if len(second) != 1 || second[0].Action != "create" || next != "" {
+			t.Fatalf("second page %+v, cursor %q", second, next)
+		}
+	})
+
+	t.Run("bad requests", func(t *testing.T) {
+		for query, msg := range map[string]string{
+			"?id=" + projectID: "entity is required when id is set",
+			"?sort=name":       "invalid cursor or sort",
+			"?cursor=garbage":  "invalid cursor or sort",
+		} {
+			resp := call(t, srv, http.MethodGet, "/audit"+query, "")
+			var body struct {
+				Error string `json:"error"`
+			}
+			decode(t, resp, &body)
+			if resp.StatusCode != http.StatusBadRequest || body.Error != msg {
+				t.Errorf("%s: %d %q, want 400 %q", query, resp.StatusCode, body.Error, msg)
+			}
+		}
+	})
+
+	t.Run("admin only", func(t *testing.T) {
+		resp := call(t, srv, http.MethodGet, "/audit", "", "Authorization", "Bearer "+token.Token)
+		if resp.StatusCode != http.StatusForbidden {
+			t.Fatalf("developer token: status %d, want 403", resp.StatusCode)
+		}
+	})
+}
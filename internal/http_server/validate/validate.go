@@ -0,0 +1,415 @@
+// Package validate проверяет тела запросов по тегам validate. Поддерживаются
+// правила required, omitempty, min, max, len, uuid, oneof и сравнение с
+// другим полем той же структуры gtfield, gtefield, ltfield, ltefield (для
+// дат и чисел). Длина строк считается в символах, а не в байтах.
+//
+// Вложенные структуры и срезы структур проверяются рекурсивно, путь к полю
+// строится по тегам json: tasks[0].end_timestamp.
+package validate
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+)
+
+// FieldError - нарушенное правило для одного поля запроса
+type FieldError struct {
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+// Message - текст ошибки ответа 422, подробности передаются списком FieldError
+const Message = "validation failed"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// Struct проверяет структуру v (или указатель на нее) и возвращает все
+// нарушения сразу; nil означает, что запрос корректен. Неизвестное правило
+// в теге - ошибка программиста, поэтому Struct паникует.
+func Struct(v interface{}) []FieldError {
+	rv := reflect.ValueOf(v)
+	for rv.Kind() == reflect.Ptr {
+		if rv.IsNil() {
+			return nil
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("validate: %s is not a struct", rv.Type()))
+	}
+
+	var errs []FieldError
+	validateStruct(rv, "", &errs)
+	return errs
+}
+
+func validateStruct(rv reflect.Value, prefix string, errs *[]FieldError) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name := fieldName(f)
+		if name == "" {
+			continue
+		}
+
+		fv := rv.Field(i)
+		path := prefix + name
+		if tag := f.Tag.Get("validate"); tag != "" {
+			if fe, ok := validateField(rv, fv, path, tag); !ok {
+				*errs = append(*errs, fe)
+				continue
+			}
+		}
+		dive(fv, path, errs)
+	}
+}
+
+// dive спускается во вложенные структуры и срезы структур
+func dive(fv reflect.Value, path string, errs *[]FieldError) {
+	for fv.Kind() == reflect.Ptr {
+		if fv.IsNil() {
+			return
+		}
+		fv = fv.Elem()
+	}
+
+	switch fv.Kind() {
+	case reflect.Struct:
+		if fv.Type() != timeType {
+			validateStruct(fv, path+".", errs)
+		}
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < fv.Len(); i++ {
+			elem := fv.Index(i)
+			for elem.Kind() == reflect.Ptr && !elem.IsNil() {
+				elem = elem.Elem()
+			}
+			if elem.Kind() == reflect.Struct && elem.Type() != timeType {
+				validateStruct(elem, path+"["+strconv.Itoa(i)+"].", errs)
+			}
+		}
+	}
+}
+
+// validateField применяет правила тега по порядку и останавливается на
+// первом нарушенном
+func validateField(parent, fv reflect.Value, path, tag string) (FieldError, bool) {
+	for _, rule := range strings.Split(tag, ",") {
+		key, param, _ := strings.Cut(rule, "=")
+
+		if key == "required" {
+			if isEmpty(fv) {
+				return FieldError{Field: path, Rule: key, Message: path + " is required"}, false
+			}
+			continue
+		}
+		if key == "omitempty" {
+			if isEmpty(fv) {
+				return FieldError{}, true
+			}
+			continue
+		}
+
+		// Необязательное значение, которое не передано, остальные правила не проверяют
+		v := fv
+		if v.Kind() == reflect.Ptr {
+			if v.IsNil() {
+				return FieldError{}, true
+			}
+			v = v.Elem()
+		}
+
+		if msg, ok := check(parent, v, key, param); !ok {
+			return FieldError{Field: path, Rule: key, Message: path + " " + msg}, false
+		}
+	}
+	return FieldError{}, true
+}
+
+// check проверяет одно правило и возвращает окончание текста ошибки
+func check(parent, v reflect.Value, key, param string) (string, bool) {
+	switch key {
+	case "min", "max", "len":
+		return checkBound(v, key, param)
+	case "uuid":
+		if v.Kind() != reflect.String {
+			panic(fmt.Sprintf("validate: uuid rule on %s", v.Type()))
+		}
+		if _, err := uuid.Parse(v.String()); err != nil {
+			return "must be a valid UUID", false
+		}
+		return "", true
+	case "oneof":
+		values := strings.Fields(param)
+		s := fmt.Sprint(v.Interface())
+		for _, value := range values {
+			if s == value {
+				return "", true
+			}
+		}
+		return "must be one of: " + strings.Join(values, ", "), false
+	case "gtfield", "gtefield", "ltfield", "ltefield":
+		return checkField(parent, v, key, param)
+	}
+	panic(fmt.Sprintf("validate: unknown rule %q", key))
+}
+
+// checkBound проверяет min, max и len: число символов строки, число
+// элементов среза или само число
+func checkBound(v reflect.Value, key, param string) (string, bool) {
+	n, err := strconv.ParseFloat(param, 64)
+	if err != nil {
+		panic(fmt.Sprintf("validate: invalid %s=%s", key, param))
+	}
+
+	var (
+		got  float64
+		unit string
+	)
+	switch v.Kind() {
+	case reflect.String:
+		got, unit = float64(utf8.RuneCountInString(v.String())), " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		got, unit = float64(v.Len()), " items"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		got = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		got = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		got = v.Float()
+	default:
+		panic(fmt.Sprintf("validate: %s rule on %s", key, v.Type()))
+	}
+
+	switch {
+	case key == "min" && got < n:
+		return "must be at least " + param + unit, false
+	case key == "max" && got > n:
+		return "must be at most " + param + unit, false
+	case key == "len" && got != n:
+		return "must be exactly " + param + unit, false
+	}
+	return "", true
+}
+
+// checkField сравнивает значение с другим полем той же структуры; param -
+// имя поля в Go
+func checkField(parent, v reflect.Value, key, param string) (string, bool) {
+	sf, ok := parent.Type().FieldByName(param)
+	if !ok {
+		panic(fmt.Sprintf("validate: %s=%s: no such field in %s", key, param, parent.Type()))
+	}
+	other := parent.FieldByIndex(sf.Index)
+	if other.Kind() == reflect.Ptr {
+		if other.IsNil() {
+			return "", true
+		}
+		other = other.Elem()
+	}
+	if isEmpty(other) {
+		// Отсутствие второго поля сообщает его собственное правило required
+		return "", true
+	}
+
+	cmp := compare(v, other)
+	otherName := fieldName(sf)
+	if v.Type() == timeType {
+		switch {
+		case key == "gtfield" && cmp <= 0:
+			return "must be after " + otherName, false
+		case key == "gtefield" && cmp < 0:
+			return "must not be before " + otherName, false
+		case key == "ltfield" && cmp >= 0:
+			return "must be before " + otherName, false
+		case key == "ltefield" && cmp > 0:
+			return "must not be after " + otherName, false
+		}
+		return "", true
+	}
+
+	switch {
+	case key == "gtfield" && cmp <= 0:
+		return "must be greater than " + otherName, false
+	case key == "gtefield" && cmp < 0:
+		return "must be greater than or equal to " + otherName, false
+	case key == "ltfield" && cmp >= 0:
+		return "must be less than " + otherName, false
+	case key == "ltefield" && cmp > 0:
+		return "must be less than or equal to " + otherName, false
+	}
+	return "", true
+}
+
+// compare возвращает -1, 0 или 1 для дат и чисел одного типа
+func compare(a, b reflect.Value) int {
+	if a.Type() != b.Type() {
+		panic(fmt.Sprintf("validate: cannot compare %s with %s", a.Type(), b.Type()))
+	}
+
+	var x, y float64
+	switch a.Kind() {
+	case reflect.Struct:
+		if a.Type() != timeType {
+			panic(fmt.Sprintf("validate: cannot compare %s", a.Type()))
+		}
+		return a.Interface().(time.Time).Compare(b.Interface().(time.Time))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		x, y = float64(a.Int()), float64(b.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		x, y = float64(a.Uint()), float64(b.Uint())
+	case reflect.Float32, reflect.Float64:
+		x, y = a.Float(), b.Float()
+	default:
+		panic(fmt.Sprintf("validate: cannot compare %s", a.Type()))
+	}
+
+	switch {
+	case x < y:
+		return -1
+	case x > y:
+		return 1
+	}
+	return 0
+}
+
+// isEmpty сообщает, что значение не передано: nil, нулевое значение или
+// пустая коллекция
+func isEmpty(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map:
+		return v.Len() == 0
+	case reflect.Ptr, reflect.Interface:
+		return v.IsNil()
+	}
+	return v.IsZero()
+}
+
+// fieldName возвращает имя поля в JSON или пустую строку для json:"-"
+func fieldName(f reflect.StructField) string {
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return ""
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		return f.Name
+	}
+	return name
+}
+
+// Check проверяет теги validate типа v без значения: известны ли правила,
+// разбираются ли их параметры и применимы ли они к типу поля. Struct на
+// таких ошибках паникует, поэтому Check вызывается в тестах для каждого
+// типа запроса, чтобы опечатка в теге не дошла до первого запроса.
+func Check(v interface{}) error {
+	rt := reflect.TypeOf(v)
+	for rt != nil && rt.Kind() == reflect.Ptr {
+		rt = rt.Elem()
+	}
+	if rt == nil || rt.Kind() != reflect.Struct {
+		return fmt.Errorf("validate: %v is not a struct", rt)
+	}
+
+	var errs []error
+	checkType(rt, map[reflect.Type]bool{}, &errs)
+	return errors.Join(errs...)
+}
+
+func checkType(rt reflect.Type, seen map[reflect.Type]bool, errs *[]error) {
+	if seen[rt] {
+		return
+	}
+	seen[rt] = true
+
+	for i := 0; i < rt.NumField(); i++ {
+		f := rt.Field(i)
+		if !f.IsExported() || fieldName(f) == "" {
+			continue
+		}
+		if tag := f.Tag.Get("validate"); tag != "" {
+			for _, rule := range strings.Split(tag, ",") {
+				key, param, _ := strings.Cut(rule, "=")
+				if err := checkRule(rt, f.Type, key, param); err != nil {
+					*errs = append(*errs, fmt.Errorf("validate: %s.%s: %s: %w", rt, f.Name, rule, err))
+				}
+			}
+		}
+
+		ft := deref(f.Type)
+		if ft.Kind() == reflect.Slice || ft.Kind() == reflect.Array {
+			ft = deref(ft.Elem())
+		}
+		if ft.Kind() == reflect.Struct && ft != timeType {
+			checkType(ft, seen, errs)
+		}
+	}
+}
+
+// checkRule сообщает, почему правило key=param нельзя применить к полю
+// типа ft структуры parent
+func checkRule(parent, ft reflect.Type, key, param string) error {
+	ft = deref(ft)
+	switch key {
+	case "required", "omitempty":
+		return nil
+	case "min", "max", "len":
+		if _, err := strconv.ParseFloat(param, 64); err != nil {
+			return errors.New("parameter is not a number")
+		}
+		switch ft.Kind() {
+		case reflect.String, reflect.Slice, reflect.Array, reflect.Map,
+			reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return nil
+		}
+		return fmt.Errorf("not applicable to %s", ft)
+	case "uuid":
+		if ft.Kind() != reflect.String {
+			return fmt.Errorf("not applicable to %s", ft)
+		}
+		return nil
+	case "oneof":
+		if len(strings.Fields(param)) == 0 {
+			return errors.New("no values")
+		}
+		return nil
+	case "gtfield", "gtefield", "ltfield", "ltefield":
+		sf, ok := parent.FieldByName(param)
+		if !ok {
+			return fmt.Errorf("no field %s", param)
+		}
+		if other := deref(sf.Type); other != ft {
+			return fmt.Errorf("cannot compare %s with %s", ft, other)
+		}
+		switch ft.Kind() {
+		case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+			reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+			reflect.Float32, reflect.Float64:
+			return nil
+		}
+		if ft != timeType {
+			return fmt.Errorf("cannot compare %s", ft)
+		}
+		return nil
+	}
+	return errors.New("unknown rule")
+}
+
+func deref(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t
+}
@@ -0,0 +1,220 @@
+package validate
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// rules возвращает пары поле:правило в порядке нарушений
+func rules(errs []FieldError) []string {
+	var got []string
+	for _, fe := range errs {
+		got = append(got, fe.Field+":"+fe.Rule)
+	}
+	return got
+}
+
+func TestStringLengthCountsRunes(t *testing.T) {
+	type request struct {
+		Name string `json:"name" validate:"min=2,max=3"`
+	}
+
+	tests := []struct {
+		name  string
+		value string
+		want  []string
+	}{
+		{"ascii within bounds", "abc", nil},
+		{"multi-byte within bounds", "héé", nil},
+		{"cyrillic within bounds", "абв", nil},
+		{"emoji within bounds", "🙂🙂", nil},
+		{"cyrillic too long", "абвг", []string{"name:max"}},
+		{"single two-byte rune too short", "я", []string{"name:min"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules(Struct(request{Name: tt.value}))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOmitempty(t *testing.T) {
+	type request struct {
+		Age   int     `json:"age" validate:"omitempty,min=18"`
+		Code  string  `json:"code" validate:"omitempty,len=4"`
+		Limit *int    `json:"limit" validate:"omitempty,min=1"`
+		Role  *string `json:"role" validate:"oneof=admin developer"`
+	}
+	zero, five := 0, 5
+	admin, owner := "admin", "owner"
+
+	tests := []struct {
+		name string
+		req  request
+		want []string
+	}{
+		{"zero values skip the rules", request{}, nil},
+		{"non-zero values are checked", request{Age: 5, Code: "abc"}, []string{"age:min", "code:len"}},
+		{"valid non-zero values", request{Age: 30, Code: "abcd", Limit: &five}, nil},
+		{"explicit zero through a pointer is checked", request{Limit: &zero}, []string{"limit:min"}},
+		{"nil pointer without omitempty is skipped", request{Role: nil}, nil},
+		{"pointer value is checked", request{Role: &owner}, []string{"role:oneof"}},
+		{"valid pointer value", request{Role: &admin}, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules(Struct(tt.req))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestTimeGtfield(t *testing.T) {
+	type request struct {
+		Start time.Time `json:"start_timestamp" validate:"required"`
+		End   time.Time `json:"end_timestamp" validate:"required,gtfield=Start"`
+	}
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		req  request
+		want []string
+	}{
+		{"end after start", request{Start: start, End: start.Add(time.Minute)}, nil},
+		{"end equal to start", request{Start: start, End: start}, []string{"end_timestamp:gtfield"}},
+		{"end before start", request{Start: start, End: start.Add(-time.Hour)}, []string{"end_timestamp:gtfield"}},
+		// Равные моменты в разных зонах - одно и то же время
+		{"same instant in another zone", request{Start: start, End: start.In(time.FixedZone("MSK", 3*3600))}, []string{"end_timestamp:gtfield"}},
+		// Отсутствие начала сообщает только required начала
+		{"start missing", request{End: start}, []string{"start_timestamp:required"}},
+		{"both missing", request{}, []string{"start_timestamp:required", "end_timestamp:required"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := rules(Struct(tt.req))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	errs := Struct(request{Start: start, End: start})
+	if want := "end_timestamp must be after start_timestamp"; errs[0].Message != want {
+		t.Fatalf("message %q, want %q", errs[0].Message, want)
+	}
+}
+
+func TestCollectsAllFieldErrors(t *testing.T) {
+	type request struct {
+		Name     string `json:"name" validate:"required,min=2"`
+		Estimate int    `json:"estimate" validate:"required,min=1"`
+		Progress int    `json:"progress" validate:"min=0,ltefield=Estimate"`
+		Owner    string `json:"owner" validate:"uuid"`
+	}
+
+	errs := Struct(request{Name: "a", Progress: -1, Owner: "nope"})
+	// Для каждого поля сообщается первое нарушенное правило, поля идут в
+	// порядке объявления
+	want := []string{"name:min", "estimate:required", "progress:min", "owner:uuid"}
+	if got := rules(errs); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+
+	if errs := Struct(&request{Name: "ok", Estimate: 2, Progress: 1, Owner: "7d4f7a1e-6c1b-4a58-9d8e-2f0c7f1a3b5c"}); errs != nil {
+		t.Fatalf("valid request: %v", errs)
+	}
+}
+
+func TestFieldNamesFollowJSON(t *testing.T) {
+	type task struct {
+		Name string `json:"name,omitempty" validate:"required"`
+	}
+	type request struct {
+		Title    string `validate:"required"`
+		Internal string `json:"-" validate:"required"`
+		Owner    string `json:"owner_id,omitempty" validate:"required"`
+		Tasks    []task `json:"tasks"`
+		Main     *task  `json:"main"`
+	}
+
+	errs := Struct(request{Tasks: []task{{Name: "ok"}, {}}, Main: &task{}})
+	want := []string{"Title:required", "owner_id:required", "tasks[1].name:required", "main.name:required"}
+	if got := rules(errs); !reflect.DeepEqual(got, want) {
+		t.Fatalf("got %v, want %v", got, want)
+	}
+	if want := "tasks[1].name is required"; errs[2].Message != want {
+		t.Fatalf("message %q, want %q", errs[2].Message, want)
+	}
+}
+
+func TestCheck(t *testing.T) {
+	type nested struct {
+		Code int `json:"code" validate:"lenn=3"`
+	}
+
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"valid tags", struct {
+			Name  string     `json:"name" validate:"required,min=2,max=100"`
+			ID    *string    `json:"id" validate:"omitempty,uuid"`
+			Role  string     `json:"role" validate:"oneof=admin developer"`
+			Start time.Time  `json:"start" validate:"required"`
+			End   *time.Time `json:"end" validate:"gtfield=Start"`
+		}{}, ""},
+		{"unknown rule", struct {
+			Name string `json:"name" validate:"requried"`
+		}{}, "unknown rule"},
+		{"bound is not a number", struct {
+			Name string `json:"name" validate:"max=ten"`
+		}{}, "parameter is not a number"},
+		{"bound on a bool", struct {
+			Flag bool `json:"flag" validate:"min=1"`
+		}{}, "not applicable to bool"},
+		{"uuid on an int", struct {
+			ID int `json:"id" validate:"uuid"`
+		}{}, "not applicable to int"},
+		{"oneof without values", struct {
+			Role string `json:"role" validate:"oneof="`
+		}{}, "no values"},
+		{"missing field", struct {
+			End time.Time `json:"end" validate:"gtfield=Begin"`
+		}{}, "no field Begin"},
+		{"field of another type", struct {
+			Start int       `json:"start"`
+			End   time.Time `json:"end" validate:"gtfield=Start"`
+		}{}, "cannot compare"},
+		{"nested struct", struct {
+			Items []nested `json:"items"`
+		}{}, "unknown rule"},
+		{"not a struct", 42, "is not a struct"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Check(tt.v)
+			if tt.want == "" {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("got %v, want error containing %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,362 @@
+package ical
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	// ErrMalformedCalendar returns when input is not a valid VCALENDAR stream
+	ErrMalformedCalendar = errors.New("malformed calendar")
+
+	durationRe = regexp.MustCompile(`^([+-])?P(?:(\d+)W)?(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+)S)?)?$`)
+)
+
+// EventError - ошибка разбора отдельного события
+type EventError struct {
+	// Index - порядковый номер события VEVENT в файле, начиная с нуля
+	Index int
+	UID   string
+	Err   error
+}
+
+func (e EventError) Error() string {
+	return fmt.Sprintf("event %d (%s): %v", e.Index, e.UID, e.Err)
+}
+
+func (e EventError) Unwrap() error {
+	return e.Err
+}
+
+type property struct {
+	name   string
+	params map[string]string
+	value  string
+}
+
+// Decode разбирает календарь и возвращает события VEVENT. Ошибки отдельных
+// событий не прерывают разбор и возвращаются в eventErrs; err означает,
+// что поток не удалось прочитать как календарь.
+func Decode(r io.Reader) (events []Event, eventErrs []EventError, err error) {
+	lines, err := unfold(r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		seen       bool
+		inCalendar bool
+		inEvent    bool
+		depth      int
+		current    []property
+		pending    [][]property
+
+		// Разбираемый VTIMEZONE и его компонент STANDARD или DAYLIGHT
+		zone     *timezone
+		zoneRule []property
+		inRule   bool
+		zones    = make(map[string]*timezone)
+	)
+	for _, line := range lines {
+		prop, err := parseProperty(line)
+		if err != nil {
+			if inEvent {
+				current = append(current, property{name: "X-INVALID", value: line})
+				continue
+			}
+			if zone != nil {
+				zone.err = err
+				continue
+			}
+			return nil, nil, fmt.Errorf("%w: %v", ErrMalformedCalendar, err)
+		}
+
+		switch {
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VCALENDAR"):
+			inCalendar, seen = true, true
+		case prop.name == "END" && strings.EqualFold(prop.value, "VCALENDAR"):
+			inCalendar = false
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VEVENT") && inCalendar:
+			inEvent, current, depth = true, nil, 0
+		case prop.name == "END" && strings.EqualFold(prop.value, "VEVENT") && inEvent:
+			pending = append(pending, current)
+			inEvent = false
+		case inEvent && prop.name == "BEGIN":
+			// Вложенные компоненты (VALARM) пропускаются
+			depth++
+		case inEvent && prop.name == "END":
+			depth--
+		case inEvent && depth == 0:
+			current = append(current, prop)
+
+		case prop.name == "BEGIN" && strings.EqualFold(prop.value, "VTIMEZONE") && inCalendar:
+			zone = &timezone{}
+		case prop.name == "END" && strings.EqualFold(prop.value, "VTIMEZONE") && zone != nil:
+			if zone.id != "" {
+				zones[zone.id] = zone
+			}
+			zone = nil
+		case zone != nil && prop.name == "BEGIN":
+			inRule, zoneRule = true, nil
+		case zone != nil && prop.name == "END" && inRule:
+			inRule = false
+			if !strings.EqualFold(prop.value, "STANDARD") && !strings.EqualFold(prop.value, "DAYLIGHT") {
+				continue
+			}
+			rule, err := parseTimezoneRule(zoneRule)
+			if err != nil {
+				zone.err = fmt.Errorf("%s: %w", strings.ToUpper(prop.value), err)
+				continue
+			}
+			zone.rules = append(zone.rules, rule)
+		case zone != nil && inRule:
+			zoneRule = append(zoneRule, prop)
+		case zone != nil && prop.name == "TZID":
+			zone.id = prop.value
+		}
+	}
+
+	if !seen || inEvent {
+		return nil, nil, ErrMalformedCalendar
+	}
+
+	// События разбираются после всего файла: VTIMEZONE может идти и после
+	// событий, которые на него ссылаются
+	for index, props := range pending {
+		event, err := buildEvent(props, zones)
+		if err != nil {
+			eventErrs = append(eventErrs, EventError{Index: index, UID: event.UID, Err: err})
+			continue
+		}
+		events = append(events, event)
+	}
+
+	return events, eventErrs, nil
+}
+
+// unfold читает строки контента и склеивает перенесенные строки (RFC 5545, 3.1)
+func unfold(r io.Reader) ([]string, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var lines []string
+	for scanner.Scan() {
+		line := strings.TrimSuffix(scanner.Text(), "\r")
+		if line == "" {
+			continue
+		}
+		if (line[0] == ' ' || line[0] == '\t') && len(lines) > 0 {
+			lines[len(lines)-1] += line[1:]
+			continue
+		}
+		lines = append(lines, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return lines, nil
+}
+
+func parseProperty(line string) (property, error) {
+	colon := -1
+	inQuotes := false
+	for i := 0; i < len(line); i++ {
+		if line[i] == '"' {
+			inQuotes = !inQuotes
+		}
+		if line[i] == ':' && !inQuotes {
+			colon = i
+			break
+		}
+	}
+	if colon <= 0 {
+		return property{}, fmt.Errorf("invalid content line %q", line)
+	}
+
+	parts := strings.Split(line[:colon], ";")
+	prop := property{
+		name:   strings.ToUpper(parts[0]),
+		params: make(map[string]string, len(parts)-1),
+		value:  line[colon+1:],
+	}
+	for _, param := range parts[1:] {
+		key, value, ok := strings.Cut(param, "=")
+		if !ok {
+			continue
+		}
+		prop.params[strings.ToUpper(key)] = strings.Trim(value, `"`)
+	}
+
+	return prop, nil
+}
+
+func buildEvent(props []property, zones map[string]*timezone) (Event, error) {
+	var (
+		event    Event
+		duration *time.Duration
+		hasStart bool
+	)
+
+	// UID нужен в ошибке события, даже если ошибочное свойство идет раньше
+	for _, prop := range props {
+		if prop.name == "UID" {
+			event.UID = unescapeText(prop.value)
+		}
+	}
+
+	for _, prop := range props {
+		var err error
+		switch prop.name {
+		case "SUMMARY":
+			event.Summary = unescapeText(prop.value)
+		case "DESCRIPTION":
+			event.Description = unescapeText(prop.value)
+		case "CATEGORIES":
+			for _, category := range splitList(prop.value) {
+				if category = strings.TrimSpace(unescapeText(category)); category != "" {
+					event.Categories = append(event.Categories, category)
+				}
+			}
+		case "DTSTART":
+			event.Start, err = parseDateTime(prop, zones)
+			hasStart = true
+		case "DTEND":
+			event.End, err = parseDateTime(prop, zones)
+		case "DURATION":
+			var d time.Duration
+			d, err = parseDuration(prop.value)
+			duration = &d
+		case "CREATED":
+			event.Created, err = parseDateTime(prop, zones)
+		case "LAST-MODIFIED":
+			event.LastModified, err = parseDateTime(prop, zones)
+		case "SEQUENCE":
+			event.Sequence, err = strconv.Atoi(prop.value)
+		case "X-INVALID":
+			err = fmt.Errorf("invalid content line %q", prop.value)
+		}
+		if err != nil {
+			return event, fmt.Errorf("%s: %w", prop.name, err)
+		}
+	}
+
+	if !hasStart {
+		return event, errors.New("DTSTART is required")
+	}
+	if event.End.IsZero() && duration != nil {
+		event.End = event.Start.Add(*duration)
+	}
+
+	return event, nil
+}
+
+// parseDateTime разбирает дату или дату-время. TZID ищется сначала в базе
+// IANA, затем среди VTIMEZONE календаря.
+func parseDateTime(prop property, zones map[string]*timezone) (time.Time, error) {
+	value := prop.value
+
+	if prop.params["VALUE"] == "DATE" || len(value) == len("20060102") {
+		return time.ParseInLocation("20060102", value, time.UTC)
+	}
+
+	if strings.HasSuffix(value, "Z") {
+		return time.Parse(dateTimeFormat, value)
+	}
+
+	tzid := prop.params["TZID"]
+	if tzid == "" {
+		return time.ParseInLocation("20060102T150405", value, time.UTC)
+	}
+	if loc, err := time.LoadLocation(tzid); err == nil {
+		return time.ParseInLocation("20060102T150405", value, loc)
+	}
+
+	zone, ok := zones[tzid]
+	if !ok {
+		return time.Time{}, fmt.Errorf("unknown TZID %q", tzid)
+	}
+	wall, err := time.Parse("20060102T150405", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	loc, err := zone.location(wall)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), 0, loc), nil
+}
+
+func parseDuration(value string) (time.Duration, error) {
+	m := durationRe.FindStringSubmatch(value)
+	if m == nil || value == "P" || value == "PT" {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	units := []time.Duration{7 * 24 * time.Hour, 24 * time.Hour, time.Hour, time.Minute, time.Second}
+
+	var d time.Duration
+	for i, unit := range units {
+		if m[i+2] == "" {
+			continue
+		}
+		n, err := strconv.Atoi(m[i+2])
+		if err != nil {
+			return 0, fmt.Errorf("invalid duration %q", value)
+		}
+		d += time.Duration(n) * unit
+	}
+	if m[1] == "-" {
+		d = -d
+	}
+
+	return d, nil
+}
+
+// splitList разбивает значение-список по неэкранированным запятым
+func splitList(value string) []string {
+	var (
+		items []string
+		start int
+	)
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' {
+			i++
+			continue
+		}
+		if value[i] == ',' {
+			items = append(items, value[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, value[start:])
+}
+
+// unescapeText снимает экранирование значения типа TEXT (RFC 5545, 3.3.11)
+func unescapeText(s string) string {
+	if !strings.Contains(s, `\`) {
+		return s
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		if s[i] != '\\' || i+1 == len(s) {
+			b.WriteByte(s[i])
+			continue
+		}
+		i++
+		switch s[i] {
+		case 'n', 'N':
+			b.WriteByte('\n')
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String()
+}
@@ -0,0 +1,253 @@
+package ical
+
+import (
+	"errors"
+	"os"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// Календари в testdata повторяют выгрузки Google Calendar и Outlook. В
+// google.ics длинные строки перенесены ровно по 75 октетов, в том числе
+// посреди многобайтового символа, в outlook.ics - по границам символов.
+
+func decodeFile(t *testing.T, name string) ([]Event, []EventError) {
+	t.Helper()
+	f, err := os.Open("testdata/" + name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	events, eventErrs, err := Decode(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return events, eventErrs
+}
+
+func utc(value string) time.Time {
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+// checkEvents сравнивает события по UID: начало и конец как моменты времени,
+// остальные поля как есть
+func checkEvents(t *testing.T, got []Event, want []Event) {
+	t.Helper()
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d", len(got), len(want))
+	}
+	for i := range want {
+		g, w := got[i], want[i]
+		if g.UID != w.UID {
+			t.Fatalf("event %d: UID %q, want %q", i, g.UID, w.UID)
+		}
+		if !g.Start.Equal(w.Start) || !g.End.Equal(w.End) {
+			t.Errorf("%s: %v - %v, want %v - %v", w.UID, g.Start.UTC(), g.End.UTC(), w.Start, w.End)
+		}
+		if g.Summary != w.Summary || g.Description != w.Description {
+			t.Errorf("%s: summary %q, description %q, want %q, %q", w.UID, g.Summary, g.Description, w.Summary, w.Description)
+		}
+		if !reflect.DeepEqual(g.Categories, w.Categories) {
+			t.Errorf("%s: categories %q, want %q", w.UID, g.Categories, w.Categories)
+		}
+		if !g.LastModified.Equal(w.LastModified) || g.Sequence != w.Sequence {
+			t.Errorf("%s: last modified %v, sequence %d, want %v, %d", w.UID, g.LastModified, g.Sequence, w.LastModified, w.Sequence)
+		}
+	}
+}
+
+func TestDecodeGoogle(t *testing.T) {
+	events, eventErrs := decodeFile(t, "google.ics")
+
+	checkEvents(t, events, []Event{
+		{
+			// TZID из базы IANA, длинное описание перенесено посреди символа
+			UID:          "5s8k2m1q0vj3c7a9d4e6f1b2h0@google.com",
+			Summary:      "Созвон по релизу",
+			Description:  "Обсуждаем релиз 2.4: миграции, откат; дежурства и мониторинг после выкладки.\nСсылка на документ в комментариях.",
+			Start:        utc("2026-10-05T07:00:00Z"),
+			End:          utc("2026-10-05T08:30:00Z"),
+			LastModified: utc("2026-10-02T09:30:00Z"),
+			Sequence:     2,
+		},
+		{
+			// Событие на весь день: VALUE=DATE, конец не включается
+			UID:          "0a1b2c3d4e5f6g7h8i9j0k1l2m@google.com",
+			Summary:      "Код-ревью весь день",
+			Start:        utc("2026-10-07T00:00:00Z"),
+			End:          utc("2026-10-08T00:00:00Z"),
+			LastModified: utc("2026-10-01T09:10:00Z"),
+		},
+		{
+			// DURATION вместо DTEND
+			UID:     "9z8y7x6w5v4u3t2s1r0q@google.com",
+			Summary: "Парное программирование",
+			Start:   utc("2026-10-08T07:00:00Z"),
+			End:     utc("2026-10-08T08:45:00Z"),
+		},
+	})
+
+	// Ошибка одного события не мешает разобрать остальные и сообщает
+	// номер события в файле и его UID
+	if len(eventErrs) != 1 {
+		t.Fatalf("got %d event errors, want 1: %v", len(eventErrs), eventErrs)
+	}
+	if e := eventErrs[0]; e.Index != 3 || e.UID != "broken-start@google.com" || !strings.HasPrefix(e.Err.Error(), "DTSTART:") {
+		t.Fatalf("unexpected event error: %v", e)
+	}
+}
+
+func TestDecodeOutlook(t *testing.T) {
+	events, eventErrs := decodeFile(t, "outlook.ics")
+
+	checkEvents(t, events, []Event{
+		{
+			// Имя зоны Windows, смещение берется из VTIMEZONE календаря
+			UID:          "040000008200E00074C5B7101A82E0080000000010A6C1F5A2F6DB01000000000000000010000000B1F2",
+			Summary:      "Ретро",
+			Description:  "Повестка:\n1. Итоги спринта, риски\n2. План на следующий спринт\n",
+			Categories:   []string{"Alpha"},
+			Start:        utc("2026-10-06T08:00:00Z"),
+			End:          utc("2026-10-06T09:00:00Z"),
+			LastModified: utc("2026-09-28T10:15:00Z"),
+		},
+		{
+			// До последнего воскресенья октября действует летнее время, +02:00
+			UID:        "040000008200E00074C5B7101A82E0080000000020A6C1F5A2F6DB01000000000000000010000000B1F3",
+			Summary:    "Design review (summer time)",
+			Categories: []string{"Beta", "Design Review"},
+			Start:      utc("2026-10-20T12:00:00Z"),
+			End:        utc("2026-10-20T13:30:00Z"),
+		},
+		{
+			// После 25 октября 2026 - зимнее, +01:00
+			UID:     "040000008200E00074C5B7101A82E0080000000030A6C1F5A2F6DB01000000000000000010000000B1F4",
+			Summary: "Design review (winter time)",
+			Start:   utc("2026-11-03T13:00:00Z"),
+			End:     utc("2026-11-03T14:30:00Z"),
+		},
+		{
+			UID:     "040000008200E00074C5B7101A82E0080000000040A6C1F5A2F6DB01000000000000000010000000B1F5",
+			Summary: "Отпуск",
+			Start:   utc("2026-10-09T00:00:00Z"),
+			End:     utc("2026-10-10T00:00:00Z"),
+		},
+	})
+
+	// Зона без VTIMEZONE и без записи в базе IANA - ошибка этого события
+	if len(eventErrs) != 1 {
+		t.Fatalf("got %d event errors, want 1: %v", len(eventErrs), eventErrs)
+	}
+	e := eventErrs[0]
+	if e.Index != 4 || !strings.Contains(e.Err.Error(), `unknown TZID "Pacific Standard Time"`) {
+		t.Fatalf("unexpected event error: %v", e)
+	}
+}
+
+func TestDecodeTimezoneTransitions(t *testing.T) {
+	// Правила зоны W. Europe Standard Time из outlook.ics; переходы 2026
+	// года - 29 марта в 02:00 и 25 октября в 03:00 местного времени
+	const calendar = "BEGIN:VCALENDAR\r\n" +
+		"BEGIN:VEVENT\r\nUID:%s\r\nDTSTART;TZID=\"W. Europe Standard Time\":%s\r\nEND:VEVENT\r\n" +
+		"BEGIN:VTIMEZONE\r\nTZID:W. Europe Standard Time\r\n" +
+		"BEGIN:STANDARD\r\nDTSTART:16011028T030000\r\nRRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=10\r\nTZOFFSETFROM:+0200\r\nTZOFFSETTO:+0100\r\nEND:STANDARD\r\n" +
+		"BEGIN:DAYLIGHT\r\nDTSTART:16010325T020000\r\nRRULE:FREQ=YEARLY;BYDAY=-1SU;BYMONTH=3\r\nTZOFFSETFROM:+0100\r\nTZOFFSETTO:+0200\r\nEND:DAYLIGHT\r\n" +
+		"END:VTIMEZONE\r\nEND:VCALENDAR\r\n"
+
+	tests := []struct {
+		local string
+		want  string
+	}{
+		{"20260115T120000", "2026-01-15T11:00:00Z"},
+		{"20260329T015959", "2026-03-29T00:59:59Z"},
+		{"20260329T030000", "2026-03-29T01:00:00Z"},
+		{"20260701T120000", "2026-07-01T10:00:00Z"},
+		{"20261025T015959", "2026-10-24T23:59:59Z"},
+		{"20261025T040000", "2026-10-25T03:00:00Z"},
+		{"20261231T235959", "2026-12-31T22:59:59Z"},
+		{"20270328T120000", "2027-03-28T10:00:00Z"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.local, func(t *testing.T) {
+			// VTIMEZONE идет после события, которое на него ссылается
+			events, eventErrs, err := Decode(strings.NewReader(strings.Replace(strings.Replace(calendar, "%s", "e", 1), "%s", tt.local, 1)))
+			if err != nil || len(eventErrs) != 0 || len(events) != 1 {
+				t.Fatalf("decode: %v, %v, %d events", err, eventErrs, len(events))
+			}
+			if got := events[0].Start; !got.Equal(utc(tt.want)) {
+				t.Fatalf("got %v, want %s", got.UTC(), tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeEventErrors(t *testing.T) {
+	const header = "BEGIN:VCALENDAR\r\nVERSION:2.0\r\n"
+
+	tests := []struct {
+		name  string
+		event string
+		want  string
+	}{
+		{"missing DTSTART", "UID:a\r\nSUMMARY:x\r\n", "DTSTART is required"},
+		{"bad duration", "UID:a\r\nDTSTART:20261001T090000Z\r\nDURATION:1 hour\r\n", "DURATION: invalid duration"},
+		{"bad date", "UID:a\r\nDTSTART;VALUE=DATE:2026-10-01\r\n", "DTSTART:"},
+		{"content line without colon", "UID:a\r\nDTSTART:20261001T090000Z\r\ngarbage\r\n", "invalid content line"},
+		{"bad sequence", "UID:a\r\nDTSTART:20261001T090000Z\r\nSEQUENCE:first\r\n", "SEQUENCE:"},
+		{"unsupported timezone rule", "UID:a\r\nDTSTART;TZID=Custom:20261001T090000\r\n", "VTIMEZONE \"Custom\": STANDARD: RRULE: unsupported frequency"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			input := header +
+				"BEGIN:VTIMEZONE\r\nTZID:Custom\r\nBEGIN:STANDARD\r\nDTSTART:20000101T000000\r\nRRULE:FREQ=MONTHLY;BYDAY=1SU\r\nTZOFFSETTO:+0100\r\nEND:STANDARD\r\nEND:VTIMEZONE\r\n" +
+				"BEGIN:VEVENT\r\nUID:ok\r\nDTSTART:20261001T090000Z\r\nEND:VEVENT\r\n" +
+				"BEGIN:VEVENT\r\n" + tt.event + "END:VEVENT\r\n" +
+				"END:VCALENDAR\r\n"
+
+			events, eventErrs, err := Decode(strings.NewReader(input))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(events) != 1 || events[0].UID != "ok" {
+				t.Fatalf("valid event was not decoded: %+v", events)
+			}
+			if len(eventErrs) != 1 {
+				t.Fatalf("got %d event errors, want 1", len(eventErrs))
+			}
+			e := eventErrs[0]
+			if e.Index != 1 || e.UID != "a" || !strings.Contains(e.Error(), tt.want) {
+				t.Fatalf("got %v, want error %q for event 1 (a)", e, tt.want)
+			}
+		})
+	}
+}
+
+func TestDecodeMalformed(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+	}{
+		{"empty", ""},
+		{"no calendar", "BEGIN:VEVENT\r\nUID:a\r\nEND:VEVENT\r\n"},
+		{"unterminated event", "BEGIN:VCALENDAR\r\nBEGIN:VEVENT\r\nUID:a\r\n"},
+		{"garbage outside events", "BEGIN:VCALENDAR\r\nnot a content line\r\nEND:VCALENDAR\r\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Decode(strings.NewReader(tt.input))
+			if !errors.Is(err, ErrMalformedCalendar) {
+				t.Fatalf("got %v, want ErrMalformedCalendar", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,156 @@
+// Package ical реализует кодирование и разбор календарей в формате iCalendar (RFC 5545).
+package ical
+
+import (
+	"bufio"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// maxLineOctets - максимальная длина строки контента без CRLF (RFC 5545, 3.1)
+	maxLineOctets = 75
+
+	dateTimeFormat = "20060102T150405Z"
+)
+
+// Event - событие VEVENT
+type Event struct {
+	UID          string
+	Summary      string
+	Description  string
+	Categories   []string
+	Start        time.Time
+	End          time.Time
+	Created      time.Time
+	LastModified time.Time
+	// Sequence - номер ревизии события; клиенты заменяют сохраненную копию
+	// события, только если номер вырос (RFC 5545, 3.8.7.4)
+	Sequence int
+}
+
+// Calendar - объект VCALENDAR
+type Calendar struct {
+	ProdID string
+	Name   string
+	Events []Event
+}
+
+// Encode записывает календарь в w в формате iCalendar
+func (c Calendar) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	now := time.Now()
+
+	lines := []string{
+		"BEGIN:VCALENDAR",
+		"VERSION:2.0",
+		"PRODID:" + escapeText(c.ProdID),
+		"CALSCALE:GREGORIAN",
+		"METHOD:PUBLISH",
+	}
+	if c.Name != "" {
+		lines = append(lines, "X-WR-CALNAME:"+escapeText(c.Name))
+	}
+
+	for _, e := range c.Events {
+		// DTSTAMP - время последней ревизии события, чтобы повторная
+		// выгрузка неизмененного события не отличалась от предыдущей
+		stamp := e.LastModified
+		if stamp.IsZero() {
+			stamp = now
+		}
+		lines = append(lines,
+			"BEGIN:VEVENT",
+			"UID:"+escapeText(e.UID),
+			"DTSTAMP:"+formatDateTime(stamp),
+			"DTSTART:"+formatDateTime(e.Start),
+			"DTEND:"+formatDateTime(e.End),
+			"SUMMARY:"+escapeText(e.Summary),
+		)
+		if e.Description != "" {
+			lines = append(lines, "DESCRIPTION:"+escapeText(e.Description))
+		}
+		if len(e.Categories) > 0 {
+			escaped := make([]string, len(e.Categories))
+			for i, category := range e.Categories {
+				escaped[i] = escapeText(category)
+			}
+			lines = append(lines, "CATEGORIES:"+strings.Join(escaped, ","))
+		}
+		if !e.Created.IsZero() {
+			lines = append(lines, "CREATED:"+formatDateTime(e.Created))
+		}
+		if !e.LastModified.IsZero() {
+			lines = append(lines, "LAST-MODIFIED:"+formatDateTime(e.LastModified))
+		}
+		if e.Sequence > 0 {
+			lines = append(lines, "SEQUENCE:"+strconv.Itoa(e.Sequence))
+		}
+		lines = append(lines, "END:VEVENT")
+	}
+
+	lines = append(lines, "END:VCALENDAR")
+
+	for _, line := range lines {
+		if _, err := bw.WriteString(foldLine(line)); err != nil {
+			return err
+		}
+	}
+
+	return bw.Flush()
+}
+
+func formatDateTime(t time.Time) string {
+	return t.UTC().Format(dateTimeFormat)
+}
+
+// escapeText экранирует значение типа TEXT (RFC 5545, 3.3.11)
+func escapeText(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		switch c := s[i]; c {
+		case '\\':
+			b.WriteString(`\\`)
+		case ';':
+			b.WriteString(`\;`)
+		case ',':
+			b.WriteString(`\,`)
+		case '\r':
+			if i+1 < len(s) && s[i+1] == '\n' {
+				i++
+			}
+			b.WriteString(`\n`)
+		case '\n':
+			b.WriteString(`\n`)
+		default:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
+
+// foldLine разбивает строку контента на строки не длиннее 75 октетов,
+// не разрывая многобайтовые символы UTF-8 (RFC 5545, 3.1)
+func foldLine(line string) string {
+	var b strings.Builder
+
+	limit := maxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		b.WriteString(line[:cut])
+		b.WriteString("\r\n ")
+		line = line[cut:]
+		// Ведущий пробел продолжения входит в длину строки
+		limit = maxLineOctets - 1
+	}
+	b.WriteString(line)
+	b.WriteString("\r\n")
+
+	return b.String()
+}
@@ -0,0 +1,169 @@
+package ical
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestFoldLine(t *testing.T) {
+	tests := []struct {
+		name string
+		line string
+	}{
+		{"short", "SUMMARY:short"},
+		{"exactly 75 octets", "SUMMARY:" + strings.Repeat("a", 67)},
+		{"ascii", "DESCRIPTION:" + strings.Repeat("abcdefghij", 20)},
+		// Двухбайтовые символы: граница 75 октетов приходится на середину символа
+		{"cyrillic", "SUMMARY:" + strings.Repeat("задача ", 30)},
+		// Трех- и четырехбайтовые символы вперемешку с ASCII
+		{"mixed", "DESCRIPTION:" + strings.Repeat("a€🙂б", 40)},
+		{"emoji after odd prefix", "SUMMARY:x" + strings.Repeat("🙂", 50)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			folded := foldLine(tt.line)
+			if !strings.HasSuffix(folded, "\r\n") {
+				t.Fatalf("folded line does not end with CRLF: %q", folded)
+			}
+
+			physical := strings.Split(strings.TrimSuffix(folded, "\r\n"), "\r\n")
+			var unfolded strings.Builder
+			for i, line := range physical {
+				if len(line) > maxLineOctets {
+					t.Errorf("line %d is %d octets: %q", i, len(line), line)
+				}
+				if !utf8.ValidString(line) {
+					t.Errorf("line %d splits a UTF-8 sequence: %q", i, line)
+				}
+				if i > 0 {
+					if line[0] != ' ' {
+						t.Fatalf("continuation line %d does not start with a space: %q", i, line)
+					}
+					line = line[1:]
+				}
+				unfolded.WriteString(line)
+			}
+			if unfolded.String() != tt.line {
+				t.Fatalf("unfolded %q, want %q", unfolded.String(), tt.line)
+			}
+			if len(tt.line) <= maxLineOctets && len(physical) != 1 {
+				t.Fatalf("line of %d octets was folded", len(tt.line))
+			}
+		})
+	}
+}
+
+func TestEscapeText(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"plain text", "plain text"},
+		{"a,b", `a\,b`},
+		{"a;b", `a\;b`},
+		{`C:\tmp`, `C:\\tmp`},
+		{"line 1\nline 2", `line 1\nline 2`},
+		{"line 1\r\nline 2", `line 1\nline 2`},
+		{"bare\rreturn", `bare\nreturn`},
+		{`all ,;\` + "\n", `all \,\;\\\n`},
+		{`\n literal`, `\\n literal`},
+		{"колонка: значение", "колонка: значение"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got := escapeText(tt.in)
+			if got != tt.want {
+				t.Fatalf("escapeText(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+			// Разбор возвращает исходный текст, кроме одиночного CR,
+			// который становится переводом строки
+			if back := unescapeText(got); back != strings.ReplaceAll(strings.ReplaceAll(tt.in, "\r\n", "\n"), "\r", "\n") {
+				t.Fatalf("unescapeText(%q) = %q", got, back)
+			}
+		})
+	}
+}
+
+func TestEncode(t *testing.T) {
+	created := time.Date(2026, 10, 1, 8, 0, 0, 0, time.UTC)
+	modified := created.Add(90 * time.Minute)
+	cal := Calendar{
+		ProdID: "-//test//RU",
+		Name:   "Анна, Ли",
+		Events: []Event{{
+			UID:          "task-1@test",
+			Summary:      "Отчет; итоги, выводы",
+			Description:  "строка 1\nстрока 2 " + strings.Repeat("длинное описание ", 10),
+			Categories:   []string{"Alpha, Beta", "Gamma"},
+			Start:        time.Date(2026, 10, 1, 12, 0, 0, 0, time.FixedZone("MSK", 3*3600)),
+			End:          time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC),
+			Created:      created,
+			LastModified: modified,
+			Sequence:     5400,
+		}},
+	}
+
+	var buf bytes.Buffer
+	if err := cal.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	for _, want := range []string{
+		"X-WR-CALNAME:Анна\\, Ли\r\n",
+		"DTSTART:20261001T090000Z\r\n",
+		"DTEND:20261001T100000Z\r\n",
+		"SUMMARY:Отчет\\; итоги\\, выводы\r\n",
+		"CATEGORIES:Alpha\\, Beta,Gamma\r\n",
+		"CREATED:20261001T080000Z\r\n",
+		"LAST-MODIFIED:20261001T093000Z\r\n",
+		// DTSTAMP не зависит от времени выгрузки, если известна ревизия
+		"DTSTAMP:20261001T093000Z\r\n",
+		"SEQUENCE:5400\r\n",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output has no %q", want)
+		}
+	}
+	for i, line := range strings.Split(strings.TrimSuffix(out, "\r\n"), "\r\n") {
+		if len(line) > maxLineOctets {
+			t.Errorf("line %d is %d octets", i, len(line))
+		}
+	}
+
+	// Закодированный календарь разбирается обратно в то же событие
+	events, eventErrs, err := Decode(&buf)
+	if err != nil || len(eventErrs) != 0 || len(events) != 1 {
+		t.Fatalf("decode: %v, %v, %d events", err, eventErrs, len(events))
+	}
+	got, want := events[0], cal.Events[0]
+	if got.UID != want.UID || got.Summary != want.Summary || got.Description != want.Description ||
+		!got.Start.Equal(want.Start) || !got.End.Equal(want.End) ||
+		!got.LastModified.Equal(want.LastModified) || got.Sequence != want.Sequence ||
+		strings.Join(got.Categories, "|") != strings.Join(want.Categories, "|") {
+		t.Fatalf("round trip: got %+v, want %+v", got, want)
+	}
+}
+
+func TestEncodeWithoutRevision(t *testing.T) {
+	cal := Calendar{ProdID: "-//test//RU", Events: []Event{{UID: "1", Start: time.Now(), End: time.Now()}}}
+
+	var buf bytes.Buffer
+	if err := cal.Encode(&buf); err != nil {
+		t.Fatal(err)
+	}
+	out := buf.String()
+
+	if !strings.Contains(out, "DTSTAMP:") {
+		t.Fatal("DTSTAMP is required even without a revision")
+	}
+	for _, unexpected := range []string{"LAST-MODIFIED:", "SEQUENCE:", "CREATED:", "DESCRIPTION:", "CATEGORIES:", "X-WR-CALNAME:"} {
+		if strings.Contains(out, unexpected) {
+			t.Errorf("output has %q for an unset field", unexpected)
+		}
+	}
+}
@@ -0,0 +1,200 @@
+package ical
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// timezone - описание VTIMEZONE из самого календаря. Outlook пишет в TZID
+// имена зон Windows ("Russian Standard Time"), которых нет в базе IANA,
+// поэтому смещение берется из правил STANDARD и DAYLIGHT.
+type timezone struct {
+	id    string
+	rules []tzRule
+	// err - правила зоны не удалось разобрать; сообщается событиям,
+	// которые на нее ссылаются
+	err error
+}
+
+// tzRule - переход STANDARD или DAYLIGHT. Без RRULE переход однократный,
+// иначе ежегодный: день недели byDay с номером n в месяце month.
+type tzRule struct {
+	start  time.Time // местное время первого перехода
+	offset int       // TZOFFSETTO, секунды
+	yearly bool
+	month  time.Month
+	n      int
+	byDay  time.Weekday
+	until  time.Time
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday, "MO": time.Monday, "TU": time.Tuesday, "WE": time.Wednesday,
+	"TH": time.Thursday, "FR": time.Friday, "SA": time.Saturday,
+}
+
+// parseTimezoneRule разбирает свойства компонента STANDARD или DAYLIGHT
+func parseTimezoneRule(props []property) (tzRule, error) {
+	var (
+		rule      tzRule
+		hasStart  bool
+		hasOffset bool
+	)
+	for _, prop := range props {
+		var err error
+		switch prop.name {
+		case "DTSTART":
+			rule.start, err = time.Parse("20060102T150405", prop.value)
+			hasStart = true
+		case "TZOFFSETTO":
+			rule.offset, err = parseOffset(prop.value)
+			hasOffset = true
+		case "RRULE":
+			err = parseYearlyRule(prop.value, &rule)
+		}
+		if err != nil {
+			return rule, fmt.Errorf("%s: %w", prop.name, err)
+		}
+	}
+	if !hasStart || !hasOffset {
+		return rule, errors.New("DTSTART and TZOFFSETTO are required")
+	}
+	return rule, nil
+}
+
+// parseOffset разбирает смещение вида +0300 или -023000
+func parseOffset(value string) (int, error) {
+	if len(value) != 5 && len(value) != 7 || (value[0] != '+' && value[0] != '-') {
+		return 0, fmt.Errorf("invalid offset %q", value)
+	}
+	n, err := strconv.Atoi(value[1:])
+	if err != nil {
+		return 0, fmt.Errorf("invalid offset %q", value)
+	}
+	if len(value) == 5 {
+		n *= 100
+	}
+	seconds := n/10000*3600 + n/100%100*60 + n%100
+	if value[0] == '-' {
+		seconds = -seconds
+	}
+	return seconds, nil
+}
+
+// parseYearlyRule поддерживает правила, которые Google и Outlook пишут для
+// переходов на летнее время: FREQ=YEARLY;BYMONTH=3;BYDAY=-1SU
+func parseYearlyRule(value string, rule *tzRule) error {
+	rule.yearly = true
+	for _, part := range strings.Split(value, ";") {
+		key, val, _ := strings.Cut(part, "=")
+		switch strings.ToUpper(key) {
+		case "FREQ":
+			if !strings.EqualFold(val, "YEARLY") {
+				return fmt.Errorf("unsupported frequency %q", val)
+			}
+		case "BYMONTH":
+			month, err := strconv.Atoi(val)
+			if err != nil || month < 1 || month > 12 {
+				return fmt.Errorf("invalid BYMONTH %q", val)
+			}
+			rule.month = time.Month(month)
+		case "BYDAY":
+			if len(val) < 3 {
+				return fmt.Errorf("invalid BYDAY %q", val)
+			}
+			day, ok := weekdays[strings.ToUpper(val[len(val)-2:])]
+			n, err := strconv.Atoi(val[:len(val)-2])
+			if !ok || err != nil || n == 0 || n < -5 || n > 5 {
+				return fmt.Errorf("invalid BYDAY %q", val)
+			}
+			rule.byDay, rule.n = day, n
+		case "UNTIL":
+			until, err := time.Parse(dateTimeFormat, val)
+			if err != nil {
+				if until, err = time.Parse("20060102T150405", val); err != nil {
+					return fmt.Errorf("invalid UNTIL %q", val)
+				}
+			}
+			rule.until = until
+		default:
+			return fmt.Errorf("unsupported RRULE part %q", key)
+		}
+	}
+	if rule.month == 0 || rule.n == 0 {
+		return errors.New("RRULE needs BYMONTH and BYDAY")
+	}
+	return nil
+}
+
+// onset возвращает местное время ежегодного перехода в году year, если он был
+func (r tzRule) onset(year int) (time.Time, bool) {
+	if year < r.start.Year() {
+		return time.Time{}, false
+	}
+
+	var day time.Time
+	if r.n > 0 {
+		day = time.Date(year, r.month, 1, 0, 0, 0, 0, time.UTC)
+		day = day.AddDate(0, 0, (int(r.byDay)-int(day.Weekday())+7)%7+(r.n-1)*7)
+	} else {
+		day = time.Date(year, r.month+1, 0, 0, 0, 0, 0, time.UTC)
+		day = day.AddDate(0, 0, -((int(day.Weekday())-int(r.byDay)+7)%7)+(r.n+1)*7)
+	}
+	if day.Month() != r.month {
+		return time.Time{}, false
+	}
+
+	h, m, s := r.start.Clock()
+	at := day.Add(time.Duration(h)*time.Hour + time.Duration(m)*time.Minute + time.Duration(s)*time.Second)
+	if !r.until.IsZero() && at.After(r.until) {
+		return time.Time{}, false
+	}
+	return at, true
+}
+
+// location возвращает зону со смещением, действующим в местное время wall
+// (часы и дата без зоны, записанные как UTC): смещение последнего перехода
+// не позже wall. До первого перехода действует самое раннее правило.
+func (tz *timezone) location(wall time.Time) (*time.Location, error) {
+	if tz.err != nil {
+		return nil, fmt.Errorf("VTIMEZONE %q: %w", tz.id, tz.err)
+	}
+	if len(tz.rules) == 0 {
+		return nil, fmt.Errorf("VTIMEZONE %q has no STANDARD or DAYLIGHT rules", tz.id)
+	}
+
+	var (
+		latest   time.Time
+		offset   int
+		found    bool
+		earliest = tz.rules[0]
+	)
+	for _, rule := range tz.rules {
+		if rule.start.Before(earliest.start) {
+			earliest = rule
+		}
+		// Однократный переход действует, пока его не сменит другой, а
+		// ежегодный достаточно искать в текущем и прошлом году
+		onsets := []time.Time{rule.start}
+		if rule.yearly {
+			onsets = onsets[:0]
+			for _, year := range []int{wall.Year() - 1, wall.Year()} {
+				if at, ok := rule.onset(year); ok {
+					onsets = append(onsets, at)
+				}
+			}
+		}
+		for _, at := range onsets {
+			if !at.After(wall) && (!found || at.After(latest)) {
+				latest, offset, found = at, rule.offset, true
+			}
+		}
+	}
+	if !found {
+		offset = earliest.offset
+	}
+	return time.FixedZone(tz.id, offset), nil
+}
@@ -0,0 +1,43 @@
+// Package logger настраивает структурированный JSON-лог приложения и хранит
+// логгер запроса в контексте
+package logger
+
+import (
+	"context"
+	"log/slog"
+	"os"
+)
+
+const envProd = "prod"
+
+type loggerKey struct{}
+
+// New создает JSON-логгер. В prod пишутся сообщения от уровня info,
+// в остальных окружениях - от debug.
+func New(env string) *slog.Logger {
+	level := slog.LevelDebug
+	if env == envProd {
+		level = slog.LevelInfo
+	}
+
+	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level}))
+}
+
+// WithLogger кладет логгер в контекст запроса
+func WithLogger(ctx context.Context, log *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, log)
+}
+
+// FromContext возвращает логгер запроса с его request_id; вне запроса -
+// slog.Default()
+func FromContext(ctx context.Context) *slog.Logger {
+	if log, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+		return log
+	}
+	return slog.Default()
+}
+
+// Err - атрибут с текстом ошибки
+func Err(err error) slog.Attr {
+	return slog.String("error", err.Error())
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"context"
+	"database/sql"
+	"goproject/internal/logger"
+	"goproject/internal/storage/postgres/entity"
+	"time"
+)
+
+var (
+	HTTPRequests = NewCounterVec("http_requests_total",
+		"Number of HTTP requests by method, route pattern and status.",
+		"method", "route", "status")
+	HTTPDuration = NewHistogramVec("http_request_duration_seconds",
+		"HTTP request latency by method and route pattern.",
+		DefBuckets, "method", "route")
+	StorageDuration = NewHistogramVec("storage_query_duration_seconds",
+		"Storage call duration by operation.",
+		DefBuckets, "op")
+)
+
+// ObserveQuery записывает длительность вызова хранилища с начала start.
+// Вызывается через defer в начале метода: defer metrics.ObserveQuery(op, time.Now()).
+func ObserveQuery(op string, start time.Time) {
+	StorageDuration.Observe(time.Since(start).Seconds(), op)
+}
+
+// RegisterDBStats отдает статистику пула соединений sql.DB
+func RegisterDBStats(reg *Registry, stats func() sql.DBStats) {
+	reg.RegisterFunc(func(ctx context.Context, w *Writer) {
+		s := stats()
+		w.Gauge("db_max_open_connections", "Maximum number of open connections to the database.", float64(s.MaxOpenConnections))
+		w.Gauge("db_open_connections", "Number of established connections, in use and idle.", float64(s.OpenConnections))
+		w.Gauge("db_in_use_connections", "Number of connections currently in use.", float64(s.InUse))
+		w.Gauge("db_idle_connections", "Number of idle connections.", float64(s.Idle))
+		w.Counter("db_wait_count_total", "Number of connections waited for.", float64(s.WaitCount))
+		w.Counter("db_wait_duration_seconds_total", "Time blocked waiting for a new connection.", s.WaitDuration.Seconds())
+		w.Counter("db_max_idle_closed_total", "Connections closed due to SetMaxIdleConns.", float64(s.MaxIdleClosed))
+		w.Counter("db_max_idle_time_closed_total", "Connections closed due to SetConnMaxIdleTime.", float64(s.MaxIdleTimeClosed))
+		w.Counter("db_max_lifetime_closed_total", "Connections closed due to SetConnMaxLifetime.", float64(s.MaxLifetimeClosed))
+	})
+}
+
+type ActivityGetter interface {
+	GetActivityStats(from time.Time) (entity.ActivityStats, error)
+}
+
+// RegisterActivity отдает бизнес-показатели за текущие сутки (по времени
+// сервера). Значения читаются из хранилища при каждом запросе /metrics.
+func RegisterActivity(reg *Registry, getter ActivityGetter) {
+	reg.RegisterFunc(func(ctx context.Context, w *Writer) {
+		now := time.Now()
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+		stats, err := getter.GetActivityStats(today)
+		if err != nil {
+			logger.FromContext(ctx).Error("failed to collect activity metrics", logger.Err(err))
+			return
+		}
+
+		w.Gauge("app_reports_submitted_today", "Reports submitted since midnight.", float64(stats.ReportsSubmitted))
+		w.Gauge("app_reports_approved_today", "Reports approved since midnight.", float64(stats.ReportsApproved))
+		w.Gauge("app_tasks_created_today", "Tasks created since midnight.", float64(stats.TasksCreated))
+		w.Gauge("app_active_developers_today", "Developers who submitted a report since midnight.", float64(stats.ActiveDevelopers))
+	})
+}
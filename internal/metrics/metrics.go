@@ -0,0 +1,262 @@
+// Package metrics - минимальная реализация метрик в текстовом формате
+// Prometheus: счетчики и гистограммы с метками и значения, которые читаются
+// в момент запроса /metrics.
+package metrics
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// DefBuckets - границы гистограмм длительности в секундах
+var DefBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// Default - реестр приложения, его отдает /metrics
+var Default = NewRegistry()
+
+type metric interface {
+	write(w io.Writer)
+}
+
+// CollectFunc пишет метрики, значения которых вычисляются при каждом запросе
+// /metrics. Ошибку чтения значения функция обрабатывает сама и просто
+// пропускает метрику.
+type CollectFunc func(ctx context.Context, w *Writer)
+
+// Registry хранит метрики и отдает их в текстовом формате Prometheus
+type Registry struct {
+	mu         sync.Mutex
+	metrics    []metric
+	collectors []CollectFunc
+}
+
+func NewRegistry() *Registry {
+	return &Registry{}
+}
+
+func (reg *Registry) register(m metric) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.metrics = append(reg.metrics, m)
+}
+
+// RegisterFunc добавляет функцию, вызываемую при каждом запросе /metrics
+func (reg *Registry) RegisterFunc(fn CollectFunc) {
+	reg.mu.Lock()
+	defer reg.mu.Unlock()
+	reg.collectors = append(reg.collectors, fn)
+}
+
+func (reg *Registry) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	reg.mu.Lock()
+	metrics := append([]metric(nil), reg.metrics...)
+	collectors := append([]CollectFunc(nil), reg.collectors...)
+	reg.mu.Unlock()
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	for _, m := range metrics {
+		m.write(w)
+	}
+	writer := &Writer{w: w}
+	for _, collect := range collectors {
+		collect(r.Context(), writer)
+	}
+}
+
+// Writer пишет значения метрик из CollectFunc
+type Writer struct {
+	w io.Writer
+}
+
+func (wr *Writer) Gauge(name, help string, value float64) {
+	writeHeader(wr.w, name, help, "gauge")
+	fmt.Fprintf(wr.w, "%s %s\n", name, formatFloat(value))
+}
+
+// Counter пишет монотонно растущее значение, которое хранится вне реестра,
+// например в sql.DBStats
+func (wr *Writer) Counter(name, help string, value float64) {
+	writeHeader(wr.w, name, help, "counter")
+	fmt.Fprintf(wr.w, "%s %s\n", name, formatFloat(value))
+}
+
+// vec - общая часть метрик с метками: значения по наборам меток
+type vec[T any] struct {
+	name   string
+	help   string
+	labels []string
+
+	mu     sync.Mutex
+	values map[string]*T
+	keys   map[string][]string
+	newT   func() *T
+}
+
+func newVec[T any](name, help string, labels []string, newT func() *T) *vec[T] {
+	return &vec[T]{
+		name:   name,
+		help:   help,
+		labels: labels,
+		values: make(map[string]*T),
+		keys:   make(map[string][]string),
+		newT:   newT,
+	}
+}
+
+// with возвращает значение для набора меток; вызывается под v.mu
+func (v *vec[T]) with(labelValues []string) *T {
+	if len(labelValues) != len(v.labels) {
+		panic(fmt.Sprintf("metrics: %s: got %d label values, want %d", v.name, len(labelValues), len(v.labels)))
+	}
+
+	key := strings.Join(labelValues, "\xff")
+	value, ok := v.values[key]
+	if !ok {
+		value = v.newT()
+		v.values[key] = value
+		v.keys[key] = append([]string(nil), labelValues...)
+	}
+	return value
+}
+
+// sortedKeys упорядочивает вывод, чтобы он не менялся между запросами
+func (v *vec[T]) sortedKeys() []string {
+	keys := make([]string, 0, len(v.values))
+	for key := range v.values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// CounterVec - счетчик с метками
+type CounterVec struct {
+	*vec[float64]
+}
+
+// NewCounterVec создает счетчик и регистрирует его в Default
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{newVec(name, help, labels, func() *float64 { return new(float64) })}
+	Default.register(c)
+	return c
+}
+
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	*c.with(labelValues)++
+}
+
+func (c *CounterVec) write(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	writeHeader(w, c.name, c.help, "counter")
+	for _, key := range c.sortedKeys() {
+		fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, c.keys[key], "", ""), formatFloat(*c.values[key]))
+	}
+}
+
+type histogram struct {
+	counts []uint64
+	sum    float64
+	count  uint64
+}
+
+// HistogramVec - гистограмма с метками
+type HistogramVec struct {
+	*vec[histogram]
+	buckets []float64
+}
+
+// NewHistogramVec создает гистограмму и регистрирует ее в Default
+func NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{buckets: buckets}
+	h.vec = newVec(name, help, labels, func() *histogram {
+		return &histogram{counts: make([]uint64, len(buckets))}
+	})
+	Default.register(h)
+	return h
+}
+
+func (h *HistogramVec) Observe(value float64, labelValues ...string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	hist := h.with(labelValues)
+	for i, bound := range h.buckets {
+		if value <= bound {
+			hist.counts[i]++
+		}
+	}
+	hist.sum += value
+	hist.count++
+}
+
+func (h *HistogramVec) write(w io.Writer) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	writeHeader(w, h.name, h.help, "histogram")
+	for _, key := range h.sortedKeys() {
+		hist, values := h.values[key], h.keys[key]
+		for i, bound := range h.buckets {
+			fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, values, "le", formatFloat(bound)), hist.counts[i])
+		}
+		fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(h.labels, values, "le", "+Inf"), hist.count)
+		fmt.Fprintf(w, "%s_sum%s %s\n", h.name, formatLabels(h.labels, values, "", ""), formatFloat(hist.sum))
+		fmt.Fprintf(w, "%s_count%s %d\n", h.name, formatLabels(h.labels, values, "", ""), hist.count)
+	}
+}
+
+func writeHeader(w io.Writer, name, help, typ string) {
+	fmt.Fprintf(w, "# HELP %s %s\n", name, help)
+	fmt.Fprintf(w, "# TYPE %s %s\n", name, typ)
+}
+
+// formatLabels форматирует набор меток; extraName добавляется последним,
+// если не пустой (метка le у бакетов гистограммы)
+func formatLabels(names, values []string, extraName, extraValue string) string {
+	if len(names) == 0 && extraName == "" {
+		return ""
+	}
+
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, name := range names {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, name, labelEscaper.Replace(values[i]))
+	}
+	if extraName != "" {
+		if len(names) > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, extraName, extraValue)
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// labelEscaper экранирует значение метки по правилам текстового формата
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatFloat(v float64) string {
+	switch {
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	case math.IsNaN(v):
+		return "NaN"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
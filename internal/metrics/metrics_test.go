@@ -0,0 +1,139 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// Метрики тестов не регистрируются в Default
+func newTestHistogram(buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{buckets: buckets}
+	h.vec = newVec("test_duration_seconds", "Test duration.", labels, func() *histogram {
+		return &histogram{counts: make([]uint64, len(buckets))}
+	})
+	return h
+}
+
+func TestHistogramWrite(t *testing.T) {
+	h := newTestHistogram([]float64{.1, 1}, "route")
+	h.Observe(0.05, "/b")
+	h.Observe(0.1, "/b")
+	h.Observe(0.5, "/b")
+	h.Observe(3, "/b")
+	h.Observe(2, "/a")
+
+	var buf bytes.Buffer
+	h.write(&buf)
+
+	// Бакеты накопительные, +Inf равен числу наблюдений, ряды по меткам
+	// отсортированы
+	want := `# HELP test_duration_seconds Test duration.
+# TYPE test_duration_seconds histogram
+test_duration_seconds_bucket{route="/a",le="0.1"} 0
+test_duration_seconds_bucket{route="/a",le="1"} 0
+test_duration_seconds_bucket{route="/a",le="+Inf"} 1
+test_duration_seconds_sum{route="/a"} 2
+test_duration_seconds_count{route="/a"} 1
+test_duration_seconds_bucket{route="/b",le="0.1"} 2
+test_duration_seconds_bucket{route="/b",le="1"} 3
+test_duration_seconds_bucket{route="/b",le="+Inf"} 4
+test_duration_seconds_sum{route="/b"} 3.65
+test_duration_seconds_count{route="/b"} 4
+`
+	if buf.String() != want {
+		t.Fatalf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestHistogramWithoutLabels(t *testing.T) {
+	h := newTestHistogram([]float64{1})
+	h.Observe(2)
+
+	var buf bytes.Buffer
+	h.write(&buf)
+	for _, line := range []string{
+		`test_duration_seconds_bucket{le="1"} 0`,
+		`test_duration_seconds_bucket{le="+Inf"} 1`,
+		`test_duration_seconds_sum 2`,
+		`test_duration_seconds_count 1`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("missing %q in\n%s", line, buf.String())
+		}
+	}
+}
+
+func TestCounterLabelEscaping(t *testing.T) {
+	c := &CounterVec{newVec("test_total", "Test counter.", []string{"path"}, func() *float64 { return new(float64) })}
+	c.Inc(`C:\tmp`)
+	c.Inc(`say "hi"`)
+	c.Inc("two\nlines")
+	c.Inc("two\nlines")
+
+	var buf bytes.Buffer
+	c.write(&buf)
+
+	want := `# HELP test_total Test counter.
+# TYPE test_total counter
+test_total{path="C:\\tmp"} 1
+test_total{path="say \"hi\""} 1
+test_total{path="two\nlines"} 2
+`
+	if buf.String() != want {
+		t.Fatalf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestLabelCountMismatchPanics(t *testing.T) {
+	c := &CounterVec{newVec("test_total", "Test counter.", []string{"a", "b"}, func() *float64 { return new(float64) })}
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Inc with one label value did not panic")
+		}
+	}()
+	c.Inc("only")
+}
+
+func TestFormatFloat(t *testing.T) {
+	tests := []struct {
+		v    float64
+		want string
+	}{
+		{0.005, "0.005"},
+		{10, "10"},
+		{math.Inf(1), "+Inf"},
+		{math.Inf(-1), "-Inf"},
+		{math.NaN(), "NaN"},
+	}
+	for _, tt := range tests {
+		if got := formatFloat(tt.v); got != tt.want {
+			t.Errorf("formatFloat(%v) = %q, want %q", tt.v, got, tt.want)
+		}
+	}
+}
+
+func TestRegistryServeHTTP(t *testing.T) {
+	reg := NewRegistry()
+	reg.register(newTestHistogram([]float64{1}))
+	reg.RegisterFunc(func(ctx context.Context, w *Writer) {
+		w.Gauge("test_open", "Open connections.", 3)
+	})
+
+	rec := httptest.NewRecorder()
+	reg.ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	if ct := rec.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/plain; version=0.0.4") {
+		t.Fatalf("Content-Type %q", ct)
+	}
+	body := rec.Body.String()
+	// Гистограмма без наблюдений пишет только заголовок
+	for _, line := range []string{"# TYPE test_duration_seconds histogram", "# TYPE test_open gauge", "test_open 3"} {
+		if !strings.Contains(body, line+"\n") {
+			t.Errorf("missing %q in\n%s", line, body)
+		}
+	}
+}
@@ -0,0 +1,238 @@
+// Package openapi строит документ OpenAPI 3 из описаний маршрутов и типов
+// запросов и ответов обработчиков. Схемы выводятся отражением по тегам json
+// и validate, поэтому документ меняется вместе с типами.
+package openapi
+
+import (
+	"fmt"
+	"net/http"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	ContentJSON     = "application/json"
+	ContentCalendar = "text/calendar"
+	ContentCSV      = "text/csv"
+	ContentText     = "text/plain"
+	ContentHTML     = "text/html"
+	ContentForm     = "multipart/form-data"
+)
+
+// Param - параметр пути или строки запроса
+type Param struct {
+	Name        string
+	In          string
+	Description string
+	Schema      *Schema
+	Required    bool
+}
+
+// PathInt - числовой параметр пути, например {id} проекта
+func PathInt(name string) Param {
+	return Param{Name: name, In: "path", Required: true, Schema: &Schema{Type: "integer", Minimum: float(1)}}
+}
+
+// PathUUID - параметр пути с UUID, например {id} разработчика
+func PathUUID(name string) Param {
+	return Param{Name: name, In: "path", Required: true, Schema: &Schema{Type: "string", Format: "uuid"}}
+}
+
+// Query - необязательный параметр строки запроса
+func Query(name, typ, description string) Param {
+	schema := &Schema{Type: typ}
+	switch typ {
+	case "date-time", "date", "uuid":
+		schema = &Schema{Type: "string", Format: typ}
+	}
+	return Param{Name: name, In: "query", Description: description, Schema: schema}
+}
+
+// QueryEnum - параметр строки запроса с перечнем допустимых значений
+func QueryEnum(name, description string, values ...string) Param {
+	return Param{Name: name, In: "query", Description: description, Schema: &Schema{Type: "string", Enum: values}}
+}
+
+// Operation описывает маршрут API. Request и Response - нулевые значения
+// типов тела запроса и ответа; ошибки из Errors отдаются тем же типом, что
+// и успешный ответ, как принято в обработчиках.
+type Operation struct {
+	Method  string
+	Path    string
+	Tag     string
+	Summary string
+	Params  []Param
+
+	Request interface{}
+	// RequestContent - тип тела запроса, если это не JSON
+	RequestContent string
+
+	Status   int
+	Response interface{}
+	// ResponseContent - тип успешного ответа, если это не JSON;
+	// тогда ошибки по-прежнему отдаются в JSON типом ErrorResponse
+	ResponseContent string
+	Errors          []int
+	// ErrorResponse - тип тела ошибок, если Response не JSON
+	ErrorResponse interface{}
+
+	// Public - маршрут доступен без токена
+	Public bool
+}
+
+// Build собирает документ по операциям. unauthorized - тип ответа 401,
+// который добавляется всем защищенным маршрутам.
+func Build(title, version string, ops []Operation, unauthorized interface{}) (*Document, error) {
+	g := newGenerator()
+	doc := &Document{
+		OpenAPI: "3.0.3",
+		Info:    Info{Title: title, Version: version},
+		Paths:   make(map[string]PathItem),
+		Security: []map[string][]string{
+			{"bearerAuth": {}},
+		},
+	}
+
+	for _, op := range ops {
+		operation, err := g.operation(op, unauthorized)
+		if err != nil {
+			return nil, fmt.Errorf("%s %s: %w", op.Method, op.Path, err)
+		}
+
+		item := doc.Paths[op.Path]
+		if item == nil {
+			item = make(PathItem)
+			doc.Paths[op.Path] = item
+		}
+		method := strings.ToLower(op.Method)
+		if _, ok := item[method]; ok {
+			return nil, fmt.Errorf("%s %s: duplicate operation", op.Method, op.Path)
+		}
+		item[method] = operation
+	}
+
+	doc.Components = Components{
+		Schemas: g.schemas,
+		SecuritySchemes: map[string]SecurityScheme{
+			"bearerAuth": {Type: "http", Scheme: "bearer"},
+		},
+	}
+	return doc, nil
+}
+
+func (g *generator) operation(op Operation, unauthorized interface{}) (*DocOperation, error) {
+	out := &DocOperation{
+		Summary:     op.Summary,
+		OperationID: operationID(op.Method, op.Path),
+		Responses:   make(map[string]Response),
+	}
+	if op.Tag != "" {
+		out.Tags = []string{op.Tag}
+	}
+	if op.Public {
+		out.Security = &[]map[string][]string{}
+	}
+
+	declared := make(map[string]bool)
+	for _, p := range op.Params {
+		declared[p.In+":"+p.Name] = true
+		out.Parameters = append(out.Parameters, DocParam{
+			Name:        p.Name,
+			In:          p.In,
+			Description: p.Description,
+			Required:    p.Required,
+			Schema:      p.Schema,
+		})
+	}
+	for _, name := range pathParams(op.Path) {
+		if !declared["path:"+name] {
+			return nil, fmt.Errorf("path parameter {%s} is not declared", name)
+		}
+	}
+
+	if op.Request != nil || op.RequestContent != "" {
+		content := op.RequestContent
+		if content == "" {
+			content = ContentJSON
+		}
+		schema := &Schema{Type: "string"}
+		if op.Request != nil {
+			schema = g.schema(reflect.TypeOf(op.Request))
+		}
+		out.RequestBody = &RequestBody{
+			Required: true,
+			Content:  map[string]MediaType{content: {Schema: schema}},
+		}
+	}
+
+	if op.Status == 0 {
+		return nil, fmt.Errorf("success status is not set")
+	}
+	success := Response{Description: http.StatusText(op.Status)}
+	switch {
+	case op.ResponseContent != "":
+		success.Content = map[string]MediaType{op.ResponseContent: {Schema: &Schema{Type: "string"}}}
+	case op.Response != nil:
+		success.Content = map[string]MediaType{ContentJSON: {Schema: g.schema(reflect.TypeOf(op.Response))}}
+	}
+	out.Responses[strconv.Itoa(op.Status)] = success
+
+	errBody := op.ErrorResponse
+	if errBody == nil {
+		errBody = op.Response
+	}
+	if !op.Public && unauthorized != nil {
+		out.Responses[strconv.Itoa(http.StatusUnauthorized)] = g.response(http.StatusUnauthorized, unauthorized)
+	}
+	for _, code := range op.Errors {
+		if errBody == nil {
+			return nil, fmt.Errorf("error status %d has no body type", code)
+		}
+		out.Responses[strconv.Itoa(code)] = g.response(code, errBody)
+	}
+
+	return out, nil
+}
+
+func (g *generator) response(code int, body interface{}) Response {
+	return Response{
+		Description: http.StatusText(code),
+		Content:     map[string]MediaType{ContentJSON: {Schema: g.schema(reflect.TypeOf(body))}},
+	}
+}
+
+// operationID строит стабильный идентификатор операции из метода и пути:
+// GET /projects/{id} -> getProjectsById
+func operationID(method, path string) string {
+	var b strings.Builder
+	b.WriteString(strings.ToLower(method))
+	for _, seg := range strings.FieldsFunc(path, func(r rune) bool { return r == '/' || r == '.' }) {
+		if strings.HasPrefix(seg, "{") {
+			b.WriteString("By")
+			seg = strings.Trim(seg, "{}")
+		}
+		for _, part := range strings.Split(seg, "_") {
+			if part != "" {
+				b.WriteString(strings.ToUpper(part[:1]) + part[1:])
+			}
+		}
+	}
+	return b.String()
+}
+
+func pathParams(path string) []string {
+	var names []string
+	for _, seg := range strings.Split(path, "/") {
+		if len(seg) > 2 && strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}") {
+			names = append(names, seg[1:len(seg)-1])
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+func float(v float64) *float64 {
+	return &v
+}
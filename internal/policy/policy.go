@@ -0,0 +1,184 @@
+// Package policy решает, что разрешено аутентифицированному клиенту API.
+// Обработчики вызывают политику до чтения или изменения данных.
+//
+//   - developer ведет только свои отчеты и задачи;
+//   - manager, кроме своих данных, читает и утверждает отчеты разработчиков
+//     по проектам, где он назначен менеджером;
+//   - admin управляет проектами и разработчиками и имеет доступ ко всему.
+package policy
+
+import (
+	"context"
+	"errors"
+	"goproject/internal/auth"
+	"goproject/internal/logger"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"net/http"
+
+	"github.com/google/uuid"
+)
+
+// ErrForbidden returns when the caller's role does not allow the action
+var ErrForbidden = errors.New("forbidden")
+
+type Policy struct {
+	access er.AccessRepository
+}
+
+func New(access er.AccessRepository) *Policy {
+	return &Policy{access: access}
+}
+
+// Status возвращает HTTP-статус и текст ответа для ошибки проверки прав.
+// Ошибки хранилища пишутся в лог запроса.
+func Status(ctx context.Context, err error) (int, string) {
+	if errors.Is(err, ErrForbidden) {
+		return http.StatusForbidden, "forbidden"
+	}
+	logger.FromContext(ctx).Error("failed to check permissions", logger.Err(err))
+	return http.StatusInternalServerError, "failed to check permissions"
+}
+
+func principal(ctx context.Context) auth.Principal {
+	p, _ := auth.FromContext(ctx)
+	return p
+}
+
+func allow(ok bool) error {
+	if !ok {
+		return ErrForbidden
+	}
+	return nil
+}
+
+// ReadProjects - проекты видны любому аутентифицированному клиенту
+func (pol *Policy) ReadProjects(ctx context.Context) error {
+	return allow(entity.ValidRole(principal(ctx).Role))
+}
+
+func (pol *Policy) ManageProjects(ctx context.Context) error {
+	return allow(principal(ctx).Role == entity.RoleAdmin)
+}
+
+// ManageDevelopers - создание, изменение, удаление разработчиков и выпуск их токенов
+func (pol *Policy) ManageDevelopers(ctx context.Context) error {
+	return allow(principal(ctx).Role == entity.RoleAdmin)
+}
+
+// ReadAudit - журнал аудита изменений
+func (pol *Policy) ReadAudit(ctx context.Context) error {
+	return allow(principal(ctx).Role == entity.RoleAdmin)
+}
+
+func (pol *Policy) ReadAnalytics(ctx context.Context) error {
+	role := principal(ctx).Role
+	return allow(role == entity.RoleAdmin || role == entity.RoleManager)
+}
+
+// ReadDeveloper - профиль, отчеты и календарь разработчика
+func (pol *Policy) ReadDeveloper(ctx context.Context, developerID uuid.UUID) error {
+	p := principal(ctx)
+	switch {
+	case p.Role == entity.RoleAdmin:
+		return nil
+	case entity.ValidRole(p.Role) && p.DeveloperID == developerID:
+		return nil
+	case p.Role == entity.RoleManager:
+		ok, err := pol.access.ManagesDeveloper(p.DeveloperID, developerID)
+		if err != nil {
+			return err
+		}
+		return allow(ok)
+	}
+	return ErrForbidden
+}
+
+// ListDevelopers ограничивает список разработчиков: менеджер видит
+// разработчиков своих проектов, разработчику список недоступен
+func (pol *Policy) ListDevelopers(ctx context.Context, params *er.ListParams) error {
+	p := principal(ctx)
+	switch p.Role {
+	case entity.RoleAdmin:
+		return nil
+	case entity.RoleManager:
+		params.ManagerID = p.DeveloperID
+		return nil
+	}
+	return ErrForbidden
+}
+
+// ScopeList ограничивает списки отчетов и задач. Разработчик видит только
+// свои записи. Менеджер видит свои записи при фильтре developer, равном
+// ему самому, а иначе - записи по своим проектам.
+func (pol *Policy) ScopeList(ctx context.Context, params *er.ListParams) error {
+	p := principal(ctx)
+	switch p.Role {
+	case entity.RoleAdmin:
+		return nil
+	case entity.RoleManager:
+		if params.DeveloperID != p.DeveloperID {
+			params.ManagerID = p.DeveloperID
+		}
+		return nil
+	case entity.RoleDeveloper:
+		if params.DeveloperID == uuid.Nil {
+			params.DeveloperID = p.DeveloperID
+		}
+		return allow(params.DeveloperID == p.DeveloperID)
+	}
+	return ErrForbidden
+}
+
+// ReadReport - отчет и его задачи
+func (pol *Policy) ReadReport(ctx context.Context, report entity.Report) error {
+	p := principal(ctx)
+	switch {
+	case p.Role == entity.RoleAdmin:
+		return nil
+	case entity.ValidRole(p.Role) && p.DeveloperID == report.DeveloperID:
+		return nil
+	case p.Role == entity.RoleManager:
+		ok, err := pol.access.ManagesReport(p.DeveloperID, report.ID)
+		if err != nil {
+			return err
+		}
+		return allow(ok)
+	}
+	return ErrForbidden
+}
+
+// CreateReport - отчет создается только от своего имени; администратор
+// может создать отчет за любого разработчика
+func (pol *Policy) CreateReport(ctx context.Context, developerID uuid.UUID) error {
+	p := principal(ctx)
+	if p.Role == entity.RoleAdmin {
+		return nil
+	}
+	return allow(entity.ValidRole(p.Role) && p.DeveloperID == developerID)
+}
+
+// EditReport - изменение отчета и его задач, включая импорт
+func (pol *Policy) EditReport(ctx context.Context, report entity.Report) error {
+	return pol.CreateReport(ctx, report.DeveloperID)
+}
+
+// ApproveReport - отчет утверждает администратор или менеджер проекта,
+// но не автор отчета
+func (pol *Policy) ApproveReport(ctx context.Context, report entity.Report) error {
+	p := principal(ctx)
+	switch p.Role {
+	case entity.RoleAdmin:
+		return nil
+	case entity.RoleManager:
+		if p.DeveloperID == report.DeveloperID {
+			return ErrForbidden
+		}
+		ok, err := pol.access.ManagesReport(p.DeveloperID, report.ID)
+		if err != nil {
+			return err
+		}
+		return allow(ok)
+	}
+	return ErrForbidden
+}
@@ -0,0 +1,168 @@
+package policy
+
+import (
+	"context"
+	"errors"
+	"goproject/internal/auth"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"testing"
+
+	"github.com/google/uuid"
+)
+
+var (
+	adminID   = uuid.New()
+	managerID = uuid.New()
+	devID     = uuid.New()
+	otherID   = uuid.New()
+
+	// отчет разработчика devID по проекту менеджера managerID
+	managedReport = entity.Report{ID: 1, DeveloperID: devID}
+	// отчет разработчика otherID по проекту без менеджера
+	foreignReport = entity.Report{ID: 2, DeveloperID: otherID}
+	// собственный отчет менеджера по его же проекту
+	managerReport = entity.Report{ID: 3, DeveloperID: managerID}
+)
+
+type fakeAccess struct{}
+
+func (fakeAccess) ManagesReport(manager uuid.UUID, reportID uint) (bool, error) {
+	return manager == managerID && (reportID == managedReport.ID || reportID == managerReport.ID), nil
+}
+
+func (fakeAccess) ManagesDeveloper(manager, developer uuid.UUID) (bool, error) {
+	return manager == managerID && (developer == devID || developer == managerID), nil
+}
+
+func as(id uuid.UUID, role string) context.Context {
+	return auth.WithPrincipal(context.Background(), auth.Principal{DeveloperID: id, Role: role})
+}
+
+var (
+	admin       = as(adminID, entity.RoleAdmin)
+	manager     = as(managerID, entity.RoleManager)
+	developer   = as(devID, entity.RoleDeveloper)
+	other       = as(otherID, entity.RoleDeveloper)
+	anonymous   = context.Background()
+	unknownRole = as(devID, "owner")
+)
+
+func TestPermissionMatrix(t *testing.T) {
+	pol := New(fakeAccess{})
+
+	actions := map[string]func(context.Context) error{
+		"read projects":              pol.ReadProjects,
+		"manage projects":            pol.ManageProjects,
+		"manage developers":          pol.ManageDevelopers,
+		"read analytics":             pol.ReadAnalytics,
+		"read audit":                 pol.ReadAudit,
+		"read own profile":           func(ctx context.Context) error { return pol.ReadDeveloper(ctx, devID) },
+		"read foreign profile":       func(ctx context.Context) error { return pol.ReadDeveloper(ctx, otherID) },
+		"read managed report":        func(ctx context.Context) error { return pol.ReadReport(ctx, managedReport) },
+		"read foreign report":        func(ctx context.Context) error { return pol.ReadReport(ctx, foreignReport) },
+		"create report for dev":      func(ctx context.Context) error { return pol.CreateReport(ctx, devID) },
+		"edit managed report":        func(ctx context.Context) error { return pol.EditReport(ctx, managedReport) },
+		"approve managed report":     func(ctx context.Context) error { return pol.ApproveReport(ctx, managedReport) },
+		"approve foreign report":     func(ctx context.Context) error { return pol.ApproveReport(ctx, foreignReport) },
+		"approve own manager report": func(ctx context.Context) error { return pol.ApproveReport(ctx, managerReport) },
+		"list developers":            func(ctx context.Context) error { return pol.ListDevelopers(ctx, &er.ListParams{}) },
+		"list all reports":           func(ctx context.Context) error { return pol.ScopeList(ctx, &er.ListParams{}) },
+		"list foreign reports":       func(ctx context.Context) error { return pol.ScopeList(ctx, &er.ListParams{DeveloperID: otherID}) },
+		"list reports of developer":  func(ctx context.Context) error { return pol.ScopeList(ctx, &er.ListParams{DeveloperID: devID}) },
+	}
+
+	// allowed перечисляет разрешенные действия для каждого клиента;
+	// остальные действия должны возвращать ErrForbidden
+	allowed := map[string][]string{
+		"admin": {
+			"read projects", "manage projects", "manage developers", "read analytics", "read audit",
+			"read own profile", "read foreign profile", "read managed report", "read foreign report",
+			"create report for dev", "edit managed report", "approve managed report",
+			"approve foreign report", "approve own manager report", "list developers",
+			"list all reports", "list foreign reports", "list reports of developer",
+		},
+		"manager": {
+			"read projects", "read analytics", "read own profile", "read managed report",
+			"approve managed report", "list developers", "list all reports",
+			"list foreign reports", "list reports of developer",
+		},
+		"developer": {
+			"read projects", "read own profile", "read managed report", "create report for dev",
+			"edit managed report", "list all reports", "list reports of developer",
+		},
+		"other developer": {
+			// для otherID "чужие" профиль и отчеты - собственные
+			"read projects", "read foreign profile", "read foreign report",
+			"list all reports", "list foreign reports",
+		},
+		"anonymous":    nil,
+		"unknown role": nil,
+	}
+	clients := map[string]context.Context{
+		"admin":           admin,
+		"manager":         manager,
+		"developer":       developer,
+		"other developer": other,
+		"anonymous":       anonymous,
+		"unknown role":    unknownRole,
+	}
+
+	for client, ctx := range clients {
+		permitted := map[string]bool{}
+		for _, action := range allowed[client] {
+			permitted[action] = true
+		}
+		for action, check := range actions {
+			err := check(ctx)
+			if permitted[action] && err != nil {
+				t.Errorf("%s: %s: want allowed, got %v", client, action, err)
+			}
+			if !permitted[action] && !errors.Is(err, ErrForbidden) {
+				t.Errorf("%s: %s: want ErrForbidden, got %v", client, action, err)
+			}
+		}
+	}
+}
+
+func TestScopeList(t *testing.T) {
+	pol := New(fakeAccess{})
+
+	tests := []struct {
+		name        string
+		ctx         context.Context
+		params      er.ListParams
+		wantDev     uuid.UUID
+		wantManager uuid.UUID
+	}{
+		{"admin is not restricted", admin, er.ListParams{}, uuid.Nil, uuid.Nil},
+		{"developer defaults to self", developer, er.ListParams{}, devID, uuid.Nil},
+		{"manager sees managed projects", manager, er.ListParams{}, uuid.Nil, managerID},
+		{"manager filtering a developer", manager, er.ListParams{DeveloperID: devID}, devID, managerID},
+		{"manager listing own records", manager, er.ListParams{DeveloperID: managerID}, managerID, uuid.Nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			params := tt.params
+			if err := pol.ScopeList(tt.ctx, &params); err != nil {
+				t.Fatalf("ScopeList: %v", err)
+			}
+			if params.DeveloperID != tt.wantDev || params.ManagerID != tt.wantManager {
+				t.Errorf("got developer=%s manager=%s, want developer=%s manager=%s",
+					params.DeveloperID, params.ManagerID, tt.wantDev, tt.wantManager)
+			}
+		})
+	}
+}
+
+func TestListDevelopersScopesManager(t *testing.T) {
+	pol := New(fakeAccess{})
+
+	var params er.ListParams
+	if err := pol.ListDevelopers(manager, &params); err != nil {
+		t.Fatalf("ListDevelopers: %v", err)
+	}
+	if params.ManagerID != managerID {
+		t.Errorf("ManagerID = %s, want %s", params.ManagerID, managerID)
+	}
+}
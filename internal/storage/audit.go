@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"bytes"
+	"encoding/json"
+	"goproject/internal/storage/postgres/entity"
+
+	"github.com/google/uuid"
+)
+
+// FieldChange - значение поля до и после изменения; null, если записи не было
+type FieldChange struct {
+	Old json.RawMessage `json:"old"`
+	New json.RawMessage `json:"new"`
+}
+
+// diffIgnored - поля, которые меняются при любом изменении и не несут информации
+var diffIgnored = map[string]bool{"ModifiedAt": true}
+
+// Diff сравнивает два состояния записи и возвращает JSON-объект изменившихся
+// полей. before равен nil при создании записи, after - при удалении.
+// Пустой объект означает, что запись не изменилась.
+func Diff(before, after interface{}) (json.RawMessage, error) {
+	old, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	cur, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string]FieldChange)
+	for name, value := range old {
+		if !bytes.Equal(value, cur[name]) {
+			changes[name] = FieldChange{Old: value, New: cur[name]}
+		}
+	}
+	for name, value := range cur {
+		if _, ok := old[name]; !ok {
+			changes[name] = FieldChange{New: value}
+		}
+	}
+	for name := range diffIgnored {
+		delete(changes, name)
+	}
+
+	return json.Marshal(changes)
+}
+
+// IsEmptyDiff сообщает, что Diff не нашел изменений
+func IsEmptyDiff(diff json.RawMessage) bool {
+	return string(diff) == "{}"
+}
+
+func fields(v interface{}) (map[string]json.RawMessage, error) {
+	if v == nil {
+		return nil, nil
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// ActorID возвращает ID автора изменения для записи в журнал; nil для
+// токена администратора из конфигурации
+func ActorID(actor entity.Actor) *uuid.UUID {
+	if actor.DeveloperID == uuid.Nil {
+		return nil
+	}
+	id := actor.DeveloperID
+	return &id
+}
@@ -0,0 +1,104 @@
+package storage
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	// DefaultLimit - размер страницы, если limit не указан
+	DefaultLimit = 50
+	// MaxLimit - максимальный размер страницы
+	MaxLimit = 200
+)
+
+var (
+	// ErrInvalidCursor returns when pagination cursor is malformed or was issued for another sort
+	ErrInvalidCursor = errors.New("invalid cursor")
+
+	// ErrInvalidSort returns when sort field is not supported by the list
+	ErrInvalidSort = errors.New("invalid sort")
+)
+
+// ListParams - параметры постраничной выборки списков. Фильтры, не имеющие
+// смысла для конкретного списка, игнорируются. Списочные методы хранилищ
+// возвращают страницу записей и курсор следующей страницы; пустой курсор
+// означает, что страница последняя.
+type ListParams struct {
+	Limit int
+	// Cursor - значение next_cursor предыдущей страницы
+	Cursor string
+	// Sort - поле сортировки; префикс "-" означает сортировку по убыванию.
+	// Пустое значение - сортировка списка по умолчанию.
+	Sort string
+
+	DeveloperID uuid.UUID
+	ProjectID   uint
+	// ManagerID ограничивает список записями, связанными с проектами менеджера
+	ManagerID uuid.UUID
+	// From и To ограничивают основную дату записи интервалом [From, To)
+	From time.Time
+	To   time.Time
+
+	// IncludeDeleted включает в список мягко удаленные записи
+	IncludeDeleted bool
+
+	// Entity и EntityID ограничивают журнал аудита записями одной сущности
+	Entity   string
+	EntityID string
+}
+
+// PageLimit возвращает размер страницы с учетом значений по умолчанию и ограничения
+func (p ListParams) PageLimit() int {
+	if p.Limit <= 0 {
+		return DefaultLimit
+	}
+	if p.Limit > MaxLimit {
+		return MaxLimit
+	}
+	return p.Limit
+}
+
+// SortField разбирает Sort на имя поля и направление
+func (p ListParams) SortField(defaultSort string) (field string, desc bool) {
+	sort := p.Sort
+	if sort == "" {
+		sort = defaultSort
+	}
+	return strings.TrimPrefix(sort, "-"), strings.HasPrefix(sort, "-")
+}
+
+// Cursor - позиция последней записи страницы для keyset-пагинации
+type Cursor struct {
+	Sort  string `json:"s"`
+	Value string `json:"v"`
+	ID    string `json:"id"`
+}
+
+func EncodeCursor(c Cursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor разбирает курсор и проверяет, что он выдан для той же сортировки
+func DecodeCursor(s, sort string) (Cursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	var c Cursor
+	if err := json.Unmarshal(data, &c); err != nil || c.ID == "" {
+		return Cursor{}, ErrInvalidCursor
+	}
+	if c.Sort != sort {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return c, nil
+}
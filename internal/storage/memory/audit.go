@@ -0,0 +1,75 @@
+package memory
+
+import (
+	"fmt"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// writeAudit добавляет запись в журнал аудита, как postgres.Storage.
+// Вызывается под s.mu вместе с самим изменением.
+func (s *Storage) writeAudit(actor entity.Actor, entityType string, entityID interface{}, action string, before, after interface{}) {
+	diff, err := er.Diff(before, after)
+	if err != nil {
+		// сущности хранилища всегда сериализуются в JSON
+		panic(fmt.Sprintf("memory: audit diff: %v", err))
+	}
+	if er.IsEmptyDiff(diff) {
+		return
+	}
+
+	s.lastAuditID++
+	s.audit = append(s.audit, entity.AuditEntry{
+		ID:         s.lastAuditID,
+		ActorID:    er.ActorID(actor),
+		ActorRole:  actor.Role,
+		EntityType: entityType,
+		EntityID:   fmt.Sprint(entityID),
+		Action:     action,
+		Diff:       diff,
+		CreatedAt:  time.Now(),
+	})
+}
+
+var auditSorts = map[string]sortKey[entity.AuditEntry]{
+	"created_at": func(a entity.AuditEntry) interface{} { return a.CreatedAt },
+	"id":         auditID,
+}
+
+func auditID(a entity.AuditEntry) interface{} { return a.ID }
+
+func (s *Storage) GetAudit(params er.ListParams) ([]entity.AuditEntry, string, error) {
+	const op = "storage.memory.GetAudit"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var entries []entity.AuditEntry
+	for _, entry := range s.audit {
+		if params.Entity != "" && entry.EntityType != params.Entity {
+			continue
+		}
+		if params.EntityID != "" && entry.EntityID != params.EntityID {
+			continue
+		}
+		if params.DeveloperID != uuid.Nil && (entry.ActorID == nil || *entry.ActorID != params.DeveloperID) {
+			continue
+		}
+		if !inRange(entry.CreatedAt, params.From, params.To) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ID < entries[j].ID })
+
+	entries, next, err := page(entries, params, auditSorts, "-created_at", auditID)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return entries, next, nil
+}
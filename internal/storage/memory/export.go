@@ -0,0 +1,109 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"sort"
+
+	"github.com/google/uuid"
+)
+
+// ExportTasks собирает строки под блокировкой и передает их в fn уже после
+// нее, чтобы медленный клиент не задерживал запись в хранилище
+func (s *Storage) ExportTasks(ctx context.Context, params er.ListParams, fn func(entity.TaskExport) error) error {
+	const op = "storage.memory.ExportTasks"
+
+	s.mu.RLock()
+	tasks := s.filterTasks(func(t entity.Task) bool {
+		return (params.DeveloperID == uuid.Nil || s.reports[t.ReportID].DeveloperID == params.DeveloperID) &&
+			(params.ProjectID == 0 || t.ProjectID == params.ProjectID) &&
+			(params.ManagerID == uuid.Nil || s.managesProject(params.ManagerID, t.ProjectID)) &&
+			inRange(t.StartTimestamp, params.From, params.To)
+	})
+	sortByStart(tasks)
+
+	rows := make([]entity.TaskExport, 0, len(tasks))
+	for _, task := range tasks {
+		report := s.reports[task.ReportID]
+		developer := s.developers[report.DeveloperID]
+		rows = append(rows, entity.TaskExport{
+			Task:              task,
+			DeveloperID:       report.DeveloperID,
+			DeveloperName:     developer.Name,
+			DeveloperLastName: developer.LastName,
+			ProjectName:       s.projects[task.ProjectID].Name,
+			ApprovedAt:        report.ApprovedAt,
+		})
+	}
+	s.mu.RUnlock()
+
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := fn(row); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
+
+// ExportReports, как ExportTasks, собирает строки под блокировкой и
+// передает их в fn после нее
+func (s *Storage) ExportReports(ctx context.Context, params er.ListParams, fn func(entity.ReportExport) error) error {
+	const op = "storage.memory.ExportReports"
+
+	s.mu.RLock()
+	reports := s.filterReports(func(r entity.Report) bool {
+		return (params.DeveloperID == uuid.Nil || r.DeveloperID == params.DeveloperID) &&
+			(params.ProjectID == 0 || s.reportHasProject(r.ID, params.ProjectID)) &&
+			(params.ManagerID == uuid.Nil || s.managesReport(params.ManagerID, r.ID)) &&
+			inRange(r.CreatedAt, params.From, params.To)
+	})
+	sort.SliceStable(reports, func(i, j int) bool {
+		if !reports[i].CreatedAt.Equal(reports[j].CreatedAt) {
+			return reports[i].CreatedAt.Before(reports[j].CreatedAt)
+		}
+		return reports[i].ID < reports[j].ID
+	})
+
+	rows := make([]entity.ReportExport, 0, len(reports))
+	for _, report := range reports {
+		developer := s.developers[report.DeveloperID]
+		row := entity.ReportExport{
+			Report:            report,
+			DeveloperName:     developer.Name,
+			DeveloperLastName: developer.LastName,
+		}
+		for _, task := range s.tasks {
+			if task.ReportID != report.ID {
+				continue
+			}
+			row.TaskCount++
+			row.EstimatePlaned += task.EstimatePlaned
+			row.EstimateProgress += task.EstimateProgress
+			if start := task.StartTimestamp; row.FirstStart == nil || start.Before(*row.FirstStart) {
+				row.FirstStart = &start
+			}
+			if end := task.EndTimestamp; row.LastEnd == nil || end.After(*row.LastEnd) {
+				row.LastEnd = &end
+			}
+		}
+		rows = append(rows, row)
+	}
+	s.mu.RUnlock()
+
+	for _, row := range rows {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+		if err := fn(row); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	return nil
+}
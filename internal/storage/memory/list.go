@@ -0,0 +1,137 @@
+package memory
+
+import (
+	"fmt"
+	er "goproject/internal/storage"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// sortKey возвращает значение поля сортировки записи
+type sortKey[T any] func(T) interface{}
+
+// page сортирует записи так же, как postgres.Storage (поле сортировки, затем
+// id), отбрасывает записи до курсора и возвращает страницу и курсор следующей
+func page[T any](items []T, params er.ListParams, keys map[string]sortKey[T], defaultSort string, id sortKey[T]) ([]T, string, error) {
+	name, desc := params.SortField(defaultSort)
+	key, ok := keys[name]
+	if !ok {
+		return nil, "", er.ErrInvalidSort
+	}
+	sortName := name
+	if desc {
+		sortName = "-" + name
+	}
+
+	less := func(a, b T) bool {
+		c := compare(key(a), key(b))
+		if c == 0 {
+			c = compare(id(a), id(b))
+		}
+		if desc {
+			return c > 0
+		}
+		return c < 0
+	}
+	sort.Slice(items, func(i, j int) bool { return less(items[i], items[j]) })
+
+	if params.Cursor != "" {
+		cursor, err := er.DecodeCursor(params.Cursor, sortName)
+		if err != nil {
+			return nil, "", err
+		}
+		var zero T
+		value, err := parseValue(key(zero), cursor.Value)
+		if err != nil {
+			return nil, "", er.ErrInvalidCursor
+		}
+		idValue, err := parseValue(id(zero), cursor.ID)
+		if err != nil {
+			return nil, "", er.ErrInvalidCursor
+		}
+
+		start := sort.Search(len(items), func(i int) bool {
+			c := compare(key(items[i]), value)
+			if c == 0 {
+				c = compare(id(items[i]), idValue)
+			}
+			if desc {
+				return c < 0
+			}
+			return c > 0
+		})
+		items = items[start:]
+	}
+
+	limit := params.PageLimit()
+	if len(items) <= limit {
+		return items, "", nil
+	}
+
+	items = items[:limit]
+	last := items[len(items)-1]
+	next := er.EncodeCursor(er.Cursor{
+		Sort:  sortName,
+		Value: formatValue(key(last)),
+		ID:    formatValue(id(last)),
+	})
+
+	return items, next, nil
+}
+
+func inRange(t, from, to time.Time) bool {
+	return (from.IsZero() || !t.Before(from)) && (to.IsZero() || t.Before(to))
+}
+
+func compare(a, b interface{}) int {
+	switch a := a.(type) {
+	case time.Time:
+		b := b.(time.Time)
+		switch {
+		case a.Before(b):
+			return -1
+		case a.After(b):
+			return 1
+		}
+		return 0
+	case string:
+		return strings.Compare(a, b.(string))
+	case uint:
+		b := b.(uint)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case uuid.UUID:
+		return strings.Compare(a.String(), b.(uuid.UUID).String())
+	}
+	panic(fmt.Sprintf("memory: unsupported sort value %T", a))
+}
+
+// parseValue разбирает значение курсора в тип, который возвращает поле сортировки
+func parseValue(typ interface{}, s string) (interface{}, error) {
+	switch typ.(type) {
+	case time.Time:
+		return time.Parse(time.RFC3339Nano, s)
+	case uint:
+		v, err := strconv.ParseUint(s, 10, 64)
+		return uint(v), err
+	case uuid.UUID:
+		return uuid.Parse(s)
+	}
+	return s, nil
+}
+
+func formatValue(v interface{}) string {
+	if t, ok := v.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
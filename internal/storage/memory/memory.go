@@ -0,0 +1,1135 @@
+// Package memory реализует storage.Repository в памяти процесса. Используется
+// для запуска сервера и тестов без базы данных; ошибки совпадают с postgres.Storage.
+package memory
+
+import (
+	"context"
+	"fmt"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Storage struct {
+	mu sync.RWMutex
+
+	developers map[uuid.UUID]entity.Developer
+	projects   map[uint]entity.Project
+	reports    map[uint]entity.Report
+	tasks      map[uint]entity.Task
+	tokens     map[uint]apiToken
+	audit      []entity.AuditEntry
+
+	lastProjectID uint
+	lastReportID  uint
+	lastTaskID    uint
+	lastTokenID   uint
+	lastAuditID   uint
+}
+
+type apiToken struct {
+	entity.APIToken
+	hash string
+}
+
+var _ er.Repository = (*Storage)(nil)
+
+func New() *Storage {
+	return &Storage{
+		developers: make(map[uuid.UUID]entity.Developer),
+		projects:   make(map[uint]entity.Project),
+		reports:    make(map[uint]entity.Report),
+		tasks:      make(map[uint]entity.Task),
+		tokens:     make(map[uint]apiToken),
+	}
+}
+
+// Ping всегда успешен: хранилище в памяти процесса доступно, пока жив процесс
+func (s *Storage) Ping(ctx context.Context) error {
+	return nil
+}
+
+func (s *Storage) Close() error {
+	return nil
+}
+
+/////TASKS//////
+
+// validateTask - те же правила, что и в postgres.Storage, плюс проверка ссылок,
+// которую в базе выполняют внешние ключи
+func (s *Storage) validateTask(task entity.Task) error {
+	if _, ok := s.reports[task.ReportID]; !ok {
+		return foreignKeyError("tasks_report_id_fkey")
+	}
+	return s.validateTaskData(task)
+}
+
+// validateTaskData проверяет поля задачи и ссылку на проект без учета отчета
+func (s *Storage) validateTaskData(task entity.Task) error {
+	if task.Name == "" || task.EstimatePlaned <= 0 {
+		return er.ErrInvalidTaskData
+	}
+	if !task.EndTimestamp.After(task.StartTimestamp) {
+		return er.ErrInvalidTaskData
+	}
+	if _, ok := s.projects[task.ProjectID]; !ok {
+		return foreignKeyError("tasks_project_id_fkey")
+	}
+	return nil
+}
+
+// checkProject - аналог проверки postgres.Storage при создании и переносе
+// задач: в удаленных и архивных проектах задачи не создаются и в них не
+// переносятся
+func (s *Storage) checkProject(task entity.Task) error {
+	project, ok := s.projects[task.ProjectID]
+	if !ok {
+		return nil
+	}
+	if project.DeletedAt != nil {
+		return fmt.Errorf("project %d: %w", project.ID, er.ErrProjectNotFound)
+	}
+	if project.ArchivedAt != nil {
+		return fmt.Errorf("project %d: %w", project.ID, er.ErrProjectArchived)
+	}
+	return nil
+}
+
+// foreignKeyError повторяет ошибку, которую postgres.Storage возвращает
+// при нарушении внешнего ключа с таким именем
+func foreignKeyError(constraint string) error {
+	return &er.ConstraintError{Constraint: constraint, Err: er.ErrForeignKeyViolation}
+}
+
+func (s *Storage) insertTask(task entity.Task) entity.Task {
+	s.lastTaskID++
+	task.ID = s.lastTaskID
+	task.CreatedAt = time.Now()
+	task.ModifiedAt = task.CreatedAt
+	s.tasks[task.ID] = task
+	return task
+}
+
+func (s *Storage) SaveTask(actor entity.Actor, task entity.Task) (int, error) {
+	const op = "storage.memory.SaveTask"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.validateTask(task); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+	if err := s.checkProject(task); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	task = s.insertTask(task)
+	s.writeAudit(actor, entity.AuditTask, task.ID, entity.ActionCreate, nil, task)
+
+	return int(task.ID), nil
+}
+
+func (s *Storage) SaveTasks(actor entity.Actor, tasks []entity.Task) ([]int, error) {
+	const op = "storage.memory.SaveTasks"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for i, task := range tasks {
+		if err := s.validateTask(task); err != nil {
+			return nil, fmt.Errorf("%s: task %d: %w", op, i, err)
+		}
+		if err := s.checkProject(task); err != nil {
+			return nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	ids := make([]int, 0, len(tasks))
+	for _, task := range tasks {
+		task = s.insertTask(task)
+		s.writeAudit(actor, entity.AuditTask, task.ID, entity.ActionCreate, nil, task)
+		ids = append(ids, int(task.ID))
+	}
+
+	return ids, nil
+}
+
+func (s *Storage) GetTaskByID(ID uint) (entity.Task, error) {
+	const op = "storage.memory.GetTask"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	task, ok := s.tasks[ID]
+	if !ok {
+		return entity.Task{}, fmt.Errorf("%s: %w", op, er.ErrTaskNotFound)
+	}
+
+	return task, nil
+}
+
+var taskSorts = map[string]sortKey[entity.Task]{
+	"start_timestamp": func(t entity.Task) interface{} { return t.StartTimestamp },
+	"created_at":      func(t entity.Task) interface{} { return t.CreatedAt },
+	"id":              taskID,
+}
+
+func taskID(t entity.Task) interface{} { return t.ID }
+
+func (s *Storage) GetTasks(params er.ListParams) ([]entity.Task, string, error) {
+	const op = "storage.memory.GetTasks"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks := s.filterTasks(func(t entity.Task) bool {
+		return (params.DeveloperID == uuid.Nil || s.reports[t.ReportID].DeveloperID == params.DeveloperID) &&
+			(params.ProjectID == 0 || t.ProjectID == params.ProjectID) &&
+			(params.ManagerID == uuid.Nil || s.managesProject(params.ManagerID, t.ProjectID)) &&
+			inRange(t.StartTimestamp, params.From, params.To)
+	})
+
+	tasks, next, err := page(tasks, params, taskSorts, "-start_timestamp", taskID)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return tasks, next, nil
+}
+
+func (s *Storage) GetTasksByReportID(ID uint) ([]entity.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.filterTasks(func(t entity.Task) bool { return t.ReportID == ID }), nil
+}
+
+func (s *Storage) GetTasksByDeveloperID(developerID uuid.UUID) ([]entity.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks := s.filterTasks(func(t entity.Task) bool {
+		return s.reports[t.ReportID].DeveloperID == developerID
+	})
+	sortByStart(tasks)
+
+	return tasks, nil
+}
+
+func (s *Storage) GetTasksByDeveloperInRange(developerID uuid.UUID, from, to time.Time) ([]entity.Task, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	tasks := s.filterTasks(func(t entity.Task) bool {
+		return s.reports[t.ReportID].DeveloperID == developerID &&
+			t.StartTimestamp.Before(to) && t.EndTimestamp.After(from)
+	})
+	sortByStart(tasks)
+
+	return tasks, nil
+}
+
+func (s *Storage) UpdateTask(actor entity.Actor, ID uint, task entity.Task, version time.Time) error {
+	const op = "storage.memory.UpdateTask"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.tasks[ID]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, er.ErrTaskNotFound)
+	}
+	if err := s.validateTask(task); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+	if !existing.ModifiedAt.Equal(version) {
+		return fmt.Errorf("%s: %w", op, er.ErrVersionMismatch)
+	}
+	if task.ProjectID != existing.ProjectID {
+		if err := s.checkProject(task); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	task.ID = ID
+	task.CreatedAt = existing.CreatedAt
+	task.ModifiedAt = time.Now()
+	task.Sequence = existing.Sequence + 1
+	s.tasks[ID] = task
+	s.writeAudit(actor, entity.AuditTask, ID, entity.ActionUpdate, existing, task)
+
+	return nil
+}
+
+// PatchTask применяет patch к текущей задаче и сохраняет результат через
+// UpdateTask
+func (s *Storage) PatchTask(actor entity.Actor, ID uint, patch entity.TaskPatch, version time.Time) error {
+	const op = "storage.memory.PatchTask"
+
+	if patch.Empty() {
+		return nil
+	}
+
+	s.mu.RLock()
+	task, ok := s.tasks[ID]
+	s.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%s: %w", op, er.ErrTaskNotFound)
+	}
+
+	patch.Apply(&task)
+	return s.UpdateTask(actor, ID, task, version)
+}
+
+func (s *Storage) DeleteTask(actor entity.Actor, ID uint) error {
+	const op = "storage.memory.DeleteTask"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.tasks[ID]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, er.ErrTaskNotFound)
+	}
+	delete(s.tasks, ID)
+	s.writeAudit(actor, entity.AuditTask, ID, entity.ActionDelete, existing, nil)
+
+	return nil
+}
+
+func (s *Storage) filterTasks(keep func(entity.Task) bool) []entity.Task {
+	var tasks []entity.Task
+	for _, task := range s.tasks {
+		if keep(task) {
+			tasks = append(tasks, task)
+		}
+	}
+	sort.Slice(tasks, func(i, j int) bool { return tasks[i].ID < tasks[j].ID })
+	return tasks
+}
+
+func sortByStart(tasks []entity.Task) {
+	sort.SliceStable(tasks, func(i, j int) bool {
+		return tasks[i].StartTimestamp.Before(tasks[j].StartTimestamp)
+	})
+}
+
+/////////DEVELOPERS/////////////
+
+func (s *Storage) SaveDeveloper(actor entity.Actor, developer entity.Developer) (uuid.UUID, error) {
+	const op = "storage.memory.SaveDeveloper"
+
+	if developer.Name == "" || developer.LastName == "" {
+		return uuid.Nil, fmt.Errorf("%s: %w", op, er.ErrInvalidDeveloperData)
+	}
+	if developer.Role != "" && !entity.ValidRole(developer.Role) {
+		return uuid.Nil, fmt.Errorf("%s: %w", op, er.ErrInvalidDeveloperData)
+	}
+	if developer.Role == "" {
+		developer.Role = entity.RoleDeveloper
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	developer.ID = uuid.New()
+	developer.CreatedAt = now
+	developer.ModifiedAt = now
+	s.developers[developer.ID] = developer
+	s.writeAudit(actor, entity.AuditDeveloper, developer.ID, entity.ActionCreate, nil, developer)
+
+	return developer.ID, nil
+}
+
+func (s *Storage) GetDeveloperByID(uid uuid.UUID) (entity.Developer, error) {
+	const op = "storage.memory.GetDeveloper"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	developer, ok := s.developers[uid]
+	if !ok {
+		return entity.Developer{}, fmt.Errorf("%s: %w", op, er.ErrDeveloperNotFound)
+	}
+
+	return developer, nil
+}
+
+var developerSorts = map[string]sortKey[entity.Developer]{
+	"created_at": func(d entity.Developer) interface{} { return d.CreatedAt },
+	"name":       func(d entity.Developer) interface{} { return d.Name },
+	"last_name":  func(d entity.Developer) interface{} { return d.LastName },
+}
+
+func developerID(d entity.Developer) interface{} { return d.ID }
+
+func (s *Storage) GetDevelopers(params er.ListParams) ([]entity.Developer, string, error) {
+	const op = "storage.memory.GetDevelopers"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var developers []entity.Developer
+	for _, developer := range s.developers {
+		if developer.DeletedAt != nil && !params.IncludeDeleted {
+			continue
+		}
+		if params.ProjectID != 0 && !s.hasTask(developer.ID, params.ProjectID) {
+			continue
+		}
+		if params.ManagerID != uuid.Nil && !s.managesDeveloper(params.ManagerID, developer.ID) {
+			continue
+		}
+		if !inRange(developer.CreatedAt, params.From, params.To) {
+			continue
+		}
+		developers = append(developers, developer)
+	}
+
+	developers, next, err := page(developers, params, developerSorts, "created_at", developerID)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return developers, next, nil
+}
+
+// hasTask сообщает, есть ли у разработчика задачи в проекте
+func (s *Storage) hasTask(developerID uuid.UUID, projectID uint) bool {
+	for _, task := range s.tasks {
+		if task.ProjectID == projectID && s.reports[task.ReportID].DeveloperID == developerID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Storage) UpdateDeveloper(actor entity.Actor, uid uuid.UUID, developer entity.Developer, version time.Time) error {
+	const op = "storage.memory.UpdateDeveloper"
+
+	if developer.Name == "" || developer.LastName == "" {
+		return fmt.Errorf("%s: %w", op, er.ErrInvalidDeveloperData)
+	}
+	if developer.Role != "" && !entity.ValidRole(developer.Role) {
+		return fmt.Errorf("%s: %w", op, er.ErrInvalidDeveloperData)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.developers[uid]
+	if !ok || existing.DeletedAt != nil {
+		return fmt.Errorf("%s: %w", op, er.ErrDeveloperNotFound)
+	}
+	if !existing.ModifiedAt.Equal(version) {
+		return fmt.Errorf("%s: %w", op, er.ErrVersionMismatch)
+	}
+
+	updated := existing
+	updated.Name = developer.Name
+	updated.LastName = developer.LastName
+	if developer.Role != "" {
+		updated.Role = developer.Role
+	}
+	updated.ModifiedAt = time.Now()
+	s.developers[uid] = updated
+	s.writeAudit(actor, entity.AuditDeveloper, uid, entity.ActionUpdate, existing, updated)
+
+	return nil
+}
+
+// PatchDeveloper применяет patch к текущему разработчику и сохраняет
+// результат через UpdateDeveloper
+func (s *Storage) PatchDeveloper(actor entity.Actor, uid uuid.UUID, patch entity.DeveloperPatch, version time.Time) error {
+	const op = "storage.memory.PatchDeveloper"
+
+	if patch.Role.Set && !entity.ValidRole(patch.Role.Value) {
+		return fmt.Errorf("%s: %w", op, er.ErrInvalidDeveloperData)
+	}
+	if patch.Empty() {
+		return nil
+	}
+
+	s.mu.RLock()
+	developer, ok := s.developers[uid]
+	s.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%s: %w", op, er.ErrDeveloperNotFound)
+	}
+
+	patch.Apply(&developer)
+	return s.UpdateDeveloper(actor, uid, developer, version)
+}
+
+func (s *Storage) SoftDeleteDeveloper(actor entity.Actor, uid uuid.UUID) error {
+	const op = "storage.memory.SoftDeleteDeveloper"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.developers[uid]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, er.ErrDeveloperNotFound)
+	}
+
+	// Повторное удаление ничего не меняет
+	if existing.DeletedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	updated := existing
+	updated.DeletedAt = &now
+	updated.ModifiedAt = now
+	s.developers[uid] = updated
+	s.writeAudit(actor, entity.AuditDeveloper, uid, entity.ActionDelete, existing, updated)
+
+	return nil
+}
+
+func (s *Storage) RestoreDeveloper(actor entity.Actor, uid uuid.UUID) error {
+	const op = "storage.memory.RestoreDeveloper"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.developers[uid]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, er.ErrDeveloperNotFound)
+	}
+
+	if existing.DeletedAt == nil {
+		return nil
+	}
+
+	updated := existing
+	updated.DeletedAt = nil
+	updated.ModifiedAt = time.Now()
+	s.developers[uid] = updated
+	s.writeAudit(actor, entity.AuditDeveloper, uid, entity.ActionRestore, existing, updated)
+
+	return nil
+}
+
+/////////////////////////////////REPORTS//////////////////////////////
+
+func (s *Storage) insertReport(actor entity.Actor, report entity.Report) entity.Report {
+	s.lastReportID++
+	report.ID = s.lastReportID
+	report.CreatedAt = time.Now()
+	s.reports[report.ID] = report
+	s.writeAudit(actor, entity.AuditReport, report.ID, entity.ActionCreate, nil, report)
+	return report
+}
+
+func (s *Storage) SaveReport(actor entity.Actor, report entity.Report) (entity.Report, error) {
+	const op = "storage.memory.SaveReport"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.developers[report.DeveloperID]; !ok {
+		return entity.Report{}, fmt.Errorf("%s: %w", op, foreignKeyError("reports_developer_id_fkey"))
+	}
+
+	return s.insertReport(actor, report), nil
+}
+
+func (s *Storage) SaveReportWithTasks(actor entity.Actor, report entity.Report, tasks []entity.Task) (entity.Report, []entity.Task, error) {
+	const op = "storage.memory.SaveReportWithTasks"
+
+	if report.DeveloperID == uuid.Nil {
+		return entity.Report{}, nil, fmt.Errorf("%s: %w", op, er.ErrInvalidReportData)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.developers[report.DeveloperID]; !ok {
+		return entity.Report{}, nil, fmt.Errorf("%s: %w", op, foreignKeyError("reports_developer_id_fkey"))
+	}
+
+	// Отчет создается только после проверки всех задач, чтобы ошибка
+	// не оставляла частично записанных данных
+	for i, task := range tasks {
+		if err := s.validateTaskData(task); err != nil {
+			return entity.Report{}, nil, fmt.Errorf("%s: task %d: %w", op, i, err)
+		}
+		if err := s.checkProject(task); err != nil {
+			return entity.Report{}, nil, fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	report = s.insertReport(actor, report)
+	saved := make([]entity.Task, 0, len(tasks))
+	for _, task := range tasks {
+		task.ReportID = report.ID
+		task = s.insertTask(task)
+		s.writeAudit(actor, entity.AuditTask, task.ID, entity.ActionCreate, nil, task)
+		saved = append(saved, task)
+	}
+
+	return report, saved, nil
+}
+
+var reportSorts = map[string]sortKey[entity.Report]{
+	"created_at": func(r entity.Report) interface{} { return r.CreatedAt },
+	"id":         reportID,
+}
+
+func reportID(r entity.Report) interface{} { return r.ID }
+
+func (s *Storage) GetReport(params er.ListParams) ([]entity.Report, string, error) {
+	const op = "storage.memory.GetReport"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	reports := s.filterReports(func(r entity.Report) bool {
+		if params.DeveloperID != uuid.Nil && r.DeveloperID != params.DeveloperID {
+			return false
+		}
+		if params.ProjectID != 0 && !s.reportHasProject(r.ID, params.ProjectID) {
+			return false
+		}
+		if params.ManagerID != uuid.Nil && !s.managesReport(params.ManagerID, r.ID) {
+			return false
+		}
+		return inRange(r.CreatedAt, params.From, params.To)
+	})
+
+	reports, next, err := page(reports, params, reportSorts, "-created_at", reportID)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return reports, next, nil
+}
+
+func (s *Storage) reportHasProject(reportID, projectID uint) bool {
+	for _, task := range s.tasks {
+		if task.ReportID == reportID && task.ProjectID == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Storage) GetReportById(id uint) (entity.Report, error) {
+	const op = "storage.memory.GetReportById"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	report, ok := s.reports[id]
+	if !ok {
+		return entity.Report{}, fmt.Errorf("%s: %w", op, er.ErrReportNotFound)
+	}
+
+	return report, nil
+}
+
+func (s *Storage) GetReportsByDeveloperID(developerID uuid.UUID) ([]entity.Report, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.filterReports(func(r entity.Report) bool { return r.DeveloperID == developerID }), nil
+}
+
+func (s *Storage) ApproveReport(actor entity.Actor, ID uint) error {
+	const op = "storage.memory.ApproveReport"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	report, ok := s.reports[ID]
+	if !ok {
+		return fmt.Errorf("%s: %w", op, er.ErrReportNotFound)
+	}
+	approverID := er.ActorID(actor)
+	if approverID != nil {
+		if _, ok := s.developers[*approverID]; !ok {
+			return fmt.Errorf("%s: %w", op, foreignKeyError("reports_approved_by_fkey"))
+		}
+	}
+
+	if report.ApprovedAt == nil {
+		now := time.Now()
+		approved := report
+		approved.ApprovedAt = &now
+		approved.ApprovedBy = approverID
+		s.reports[ID] = approved
+		s.writeAudit(actor, entity.AuditReport, ID, entity.ActionApprove, report, approved)
+	}
+
+	return nil
+}
+
+// filterReports возвращает отчеты от новых к старым, как postgres.Storage
+func (s *Storage) filterReports(keep func(entity.Report) bool) []entity.Report {
+	var reports []entity.Report
+	for _, report := range s.reports {
+		if keep(report) {
+			reports = append(reports, report)
+		}
+	}
+	sort.Slice(reports, func(i, j int) bool { return reports[i].ID > reports[j].ID })
+	return reports
+}
+
+/////////////////////////////////PROJECTS//////////////////////////////
+
+func (s *Storage) SaveProject(actor entity.Actor, project entity.Project) (entity.Project, error) {
+	const op = "storage.memory.SaveProject"
+
+	if project.Name == "" {
+		return entity.Project{}, fmt.Errorf("%s: %w", op, er.ErrInvalidProjectData)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if project.ManagerID != nil {
+		if _, ok := s.developers[*project.ManagerID]; !ok {
+			return entity.Project{}, fmt.Errorf("%s: %w", op, foreignKeyError("projects_manager_id_fkey"))
+		}
+	}
+
+	now := time.Now()
+	s.lastProjectID++
+	project.ID = s.lastProjectID
+	project.CreatedAt = now
+	project.ModifiedAt = now
+	s.projects[project.ID] = project
+	s.writeAudit(actor, entity.AuditProject, project.ID, entity.ActionCreate, nil, project)
+
+	return project, nil
+}
+
+var projectSorts = map[string]sortKey[entity.Project]{
+	"created_at": func(p entity.Project) interface{} { return p.CreatedAt },
+	"name":       func(p entity.Project) interface{} { return p.Name },
+	"id":         projectID,
+}
+
+func projectID(p entity.Project) interface{} { return p.ID }
+
+func (s *Storage) GetProject(params er.ListParams) ([]entity.Project, string, error) {
+	const op = "storage.memory.GetProject"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var projects []entity.Project
+	for _, project := range s.projects {
+		if project.DeletedAt != nil && !params.IncludeDeleted {
+			continue
+		}
+		if params.DeveloperID != uuid.Nil && !s.hasTask(params.DeveloperID, project.ID) {
+			continue
+		}
+		if !inRange(project.CreatedAt, params.From, params.To) {
+			continue
+		}
+		projects = append(projects, project)
+	}
+
+	projects, next, err := page(projects, params, projectSorts, "-created_at", projectID)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	return projects, next, nil
+}
+
+func (s *Storage) GetProjectByName(name string) (entity.Project, error) {
+	const op = "storage.memory.GetProjectByName"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var (
+		found entity.Project
+		ok    bool
+	)
+	for _, project := range s.projects {
+		if project.DeletedAt == nil && strings.EqualFold(project.Name, name) && (!ok || project.ID < found.ID) {
+			found, ok = project, true
+		}
+	}
+	if !ok {
+		return entity.Project{}, fmt.Errorf("%s: %w", op, er.ErrProjectNotFound)
+	}
+
+	return found, nil
+}
+
+func (s *Storage) GetProjectByID(ID uint) (entity.Project, error) {
+	const op = "storage.memory.GetProjectByID"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	project, ok := s.projects[ID]
+	if !ok || project.DeletedAt != nil {
+		return entity.Project{}, fmt.Errorf("%s: %w", op, er.ErrProjectNotFound)
+	}
+
+	return project, nil
+}
+
+// GetProjectsByIDs возвращает проекты с заданными ID; удаленные и
+// несуществующие проекты пропускаются
+func (s *Storage) GetProjectsByIDs(IDs []uint) ([]entity.Project, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var projects []entity.Project
+	for _, id := range IDs {
+		if project, ok := s.projects[id]; ok && project.DeletedAt == nil {
+			projects = append(projects, project)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+
+	return projects, nil
+}
+
+func (s *Storage) UpdateProject(actor entity.Actor, ID uint, project entity.Project, version time.Time) error {
+	const op = "storage.memory.UpdateProject"
+
+	if project.Name == "" {
+		return fmt.Errorf("%s: %w", op, er.ErrInvalidProjectData)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.projects[ID]
+	if !ok || existing.DeletedAt != nil {
+		return fmt.Errorf("%s: %w", op, er.ErrProjectNotFound)
+	}
+	if !existing.ModifiedAt.Equal(version) {
+		return fmt.Errorf("%s: %w", op, er.ErrVersionMismatch)
+	}
+
+	if project.ManagerID != nil {
+		if _, ok := s.developers[*project.ManagerID]; !ok {
+			return fmt.Errorf("%s: %w", op, foreignKeyError("projects_manager_id_fkey"))
+		}
+	}
+
+	updated := existing
+	updated.Name = project.Name
+	updated.Description = project.Description
+	updated.ManagerID = project.ManagerID
+	updated.ModifiedAt = time.Now()
+	s.projects[ID] = updated
+	s.writeAudit(actor, entity.AuditProject, ID, entity.ActionUpdate, existing, updated)
+
+	return nil
+}
+
+// PatchProject применяет patch к текущему проекту и сохраняет результат
+// через UpdateProject; изменение проекта после чтения отсекает проверка version
+func (s *Storage) PatchProject(actor entity.Actor, ID uint, patch entity.ProjectPatch, version time.Time) error {
+	const op = "storage.memory.PatchProject"
+
+	if patch.Empty() {
+		return nil
+	}
+
+	s.mu.RLock()
+	project, ok := s.projects[ID]
+	s.mu.RUnlock()
+	if !ok {
+		return fmt.Errorf("%s: %w", op, er.ErrProjectNotFound)
+	}
+
+	patch.Apply(&project)
+	return s.UpdateProject(actor, ID, project, version)
+}
+
+func (s *Storage) DeleteProject(actor entity.Actor, ID uint) error {
+	const op = "storage.memory.DeleteProject"
+
+	return s.setProjectState(op, actor, ID, entity.ActionDelete, false, func(p *entity.Project, now time.Time) {
+		p.DeletedAt = &now
+	})
+}
+
+func (s *Storage) ArchiveProject(actor entity.Actor, ID uint) error {
+	const op = "storage.memory.ArchiveProject"
+
+	return s.setProjectState(op, actor, ID, entity.ActionArchive, false, func(p *entity.Project, now time.Time) {
+		if p.ArchivedAt == nil {
+			p.ArchivedAt = &now
+		}
+	})
+}
+
+func (s *Storage) RestoreProject(actor entity.Actor, ID uint) error {
+	const op = "storage.memory.RestoreProject"
+
+	return s.setProjectState(op, actor, ID, entity.ActionRestore, true, func(p *entity.Project, now time.Time) {
+		p.ArchivedAt = nil
+		p.DeletedAt = nil
+	})
+}
+
+// setProjectState меняет состояние проекта и пишет изменение в журнал аудита;
+// удаленные проекты доступны только при withDeleted
+func (s *Storage) setProjectState(op string, actor entity.Actor, ID uint, action string, withDeleted bool, change func(p *entity.Project, now time.Time)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	project, ok := s.projects[ID]
+	if !ok || (project.DeletedAt != nil && !withDeleted) {
+		return fmt.Errorf("%s: %w", op, er.ErrProjectNotFound)
+	}
+
+	now := time.Now()
+	updated := project
+	change(&updated, now)
+	updated.ModifiedAt = now
+	s.projects[ID] = updated
+	s.writeAudit(actor, entity.AuditProject, ID, action, project, updated)
+
+	return nil
+}
+
+/////////////////////////////////ANALYTICS//////////////////////////////
+
+func (s *Storage) GetProjectStats(from, to time.Time) ([]entity.ProjectStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byProject := make(map[uint]*entity.ProjectStats)
+	for _, task := range s.tasksStartedIn(from, to) {
+		st, ok := byProject[task.ProjectID]
+		if !ok {
+			st = &entity.ProjectStats{
+				ProjectID:   task.ProjectID,
+				ProjectName: s.projects[task.ProjectID].Name,
+			}
+			byProject[task.ProjectID] = st
+		}
+		addTask(&st.EstimateStats, task)
+	}
+
+	stats := make([]entity.ProjectStats, 0, len(byProject))
+	for _, st := range byProject {
+		finishStats(&st.EstimateStats)
+		stats = append(stats, *st)
+	}
+	sort.Slice(stats, func(i, j int) bool { return stats[i].ProjectName < stats[j].ProjectName })
+
+	return stats, nil
+}
+
+func (s *Storage) GetDeveloperStats(from, to time.Time) ([]entity.DeveloperStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	byDeveloper := make(map[uuid.UUID]*entity.DeveloperStats)
+	for _, task := range s.tasksStartedIn(from, to) {
+		developerID := s.reports[task.ReportID].DeveloperID
+		st, ok := byDeveloper[developerID]
+		if !ok {
+			developer := s.developers[developerID]
+			st = &entity.DeveloperStats{
+				DeveloperID: developerID,
+				Name:        developer.Name,
+				LastName:    developer.LastName,
+			}
+			byDeveloper[developerID] = st
+		}
+		addTask(&st.EstimateStats, task)
+	}
+
+	stats := make([]entity.DeveloperStats, 0, len(byDeveloper))
+	for _, st := range byDeveloper {
+		finishStats(&st.EstimateStats)
+		stats = append(stats, *st)
+	}
+	sort.Slice(stats, func(i, j int) bool {
+		if stats[i].LastName != stats[j].LastName {
+			return stats[i].LastName < stats[j].LastName
+		}
+		return stats[i].Name < stats[j].Name
+	})
+
+	return stats, nil
+}
+
+func (s *Storage) GetActivityStats(from time.Time) (entity.ActivityStats, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var stats entity.ActivityStats
+	developers := make(map[uuid.UUID]bool)
+	for _, report := range s.reports {
+		if !report.CreatedAt.Before(from) {
+			stats.ReportsSubmitted++
+			developers[report.DeveloperID] = true
+		}
+		if report.ApprovedAt != nil && !report.ApprovedAt.Before(from) {
+			stats.ReportsApproved++
+		}
+	}
+	for _, task := range s.tasks {
+		if !task.CreatedAt.Before(from) {
+			stats.TasksCreated++
+		}
+	}
+	stats.ActiveDevelopers = len(developers)
+
+	return stats, nil
+}
+
+func (s *Storage) tasksStartedIn(from, to time.Time) []entity.Task {
+	return s.filterTasks(func(t entity.Task) bool {
+		return !t.StartTimestamp.Before(from) && t.StartTimestamp.Before(to)
+	})
+}
+
+/////////////////////////////////ACCESS//////////////////////////////
+
+func (s *Storage) ManagesReport(managerID uuid.UUID, reportID uint) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.managesReport(managerID, reportID), nil
+}
+
+func (s *Storage) ManagesDeveloper(managerID, developerID uuid.UUID) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	return s.managesDeveloper(managerID, developerID), nil
+}
+
+func (s *Storage) managesProject(managerID uuid.UUID, projectID uint) bool {
+	m := s.projects[projectID].ManagerID
+	return m != nil && *m == managerID
+}
+
+func (s *Storage) managesReport(managerID uuid.UUID, reportID uint) bool {
+	for _, task := range s.tasks {
+		if task.ReportID == reportID && s.managesProject(managerID, task.ProjectID) {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *Storage) managesDeveloper(managerID, developerID uuid.UUID) bool {
+	for _, task := range s.tasks {
+		if s.reports[task.ReportID].DeveloperID == developerID && s.managesProject(managerID, task.ProjectID) {
+			return true
+		}
+	}
+	return false
+}
+
+/////////////////////////////////TOKENS//////////////////////////////
+
+func (s *Storage) SaveToken(actor entity.Actor, token entity.APIToken, hash string) (entity.APIToken, error) {
+	const op = "storage.memory.SaveToken"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.developers[token.DeveloperID]; !ok {
+		return entity.APIToken{}, fmt.Errorf("%s: %w", op, foreignKeyError("api_tokens_developer_id_fkey"))
+	}
+	for _, t := range s.tokens {
+		if t.hash == hash {
+			return entity.APIToken{}, fmt.Errorf("%s: %w", op,
+				&er.ConstraintError{Constraint: "api_tokens_token_hash_key", Err: er.ErrUniqueViolation})
+		}
+	}
+
+	s.lastTokenID++
+	token.ID = s.lastTokenID
+	token.CreatedAt = time.Now()
+	token.RevokedAt = nil
+	s.tokens[token.ID] = apiToken{APIToken: token, hash: hash}
+	s.writeAudit(actor, entity.AuditToken, token.ID, entity.ActionCreate, nil, token)
+
+	return token, nil
+}
+
+func (s *Storage) GetTokens(developerID uuid.UUID) ([]entity.APIToken, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var tokens []entity.APIToken
+	for _, t := range s.tokens {
+		if t.DeveloperID == developerID {
+			tokens = append(tokens, t.APIToken)
+		}
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].ID < tokens[j].ID })
+
+	return tokens, nil
+}
+
+func (s *Storage) GetDeveloperByToken(hash string) (entity.Developer, error) {
+	const op = "storage.memory.GetDeveloperByToken"
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, t := range s.tokens {
+		if t.hash != hash || t.RevokedAt != nil {
+			continue
+		}
+		developer, ok := s.developers[t.DeveloperID]
+		if ok && developer.DeletedAt == nil {
+			return developer, nil
+		}
+	}
+
+	return entity.Developer{}, fmt.Errorf("%s: %w", op, er.ErrTokenNotFound)
+}
+
+func (s *Storage) RevokeToken(actor entity.Actor, developerID uuid.UUID, ID uint) error {
+	const op = "storage.memory.RevokeToken"
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	t, ok := s.tokens[ID]
+	if !ok || t.DeveloperID != developerID {
+		return fmt.Errorf("%s: %w", op, er.ErrTokenNotFound)
+	}
+	if t.RevokedAt == nil {
+		now := time.Now()
+		revoked := t
+		revoked.RevokedAt = &now
+		s.tokens[ID] = revoked
+		s.writeAudit(actor, entity.AuditToken, ID, entity.ActionRevoke, t.APIToken, revoked.APIToken)
+	}
+
+	return nil
+}
+
+func addTask(st *entity.EstimateStats, task entity.Task) {
+	st.TaskCount++
+	st.PlannedHours += task.EstimatePlaned
+	st.LoggedHours += task.EstimateProgress
+	st.ActualHours += task.EndTimestamp.Sub(task.StartTimestamp).Hours()
+}
+
+func finishStats(st *entity.EstimateStats) {
+	if st.PlannedHours > 0 {
+		st.OverrunRatio = float64(st.LoggedHours) / float64(st.PlannedHours)
+	}
+}
@@ -0,0 +1,519 @@
+package memory
+
+import (
+	"errors"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+var admin = entity.Actor{Role: entity.RoleAdmin}
+
+// fixture - хранилище с разработчиком, действующим и архивным проектом,
+// отчетом и одной задачей в нем
+type fixture struct {
+	s         *Storage
+	developer entity.Developer
+	project   entity.Project
+	archived  entity.Project
+	report    entity.Report
+	task      entity.Task
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+	s := New()
+
+	devID, err := s.SaveDeveloper(admin, entity.Developer{Name: "Ann", LastName: "Lee"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	developer, _ := s.GetDeveloperByID(devID)
+
+	project, err := s.SaveProject(admin, entity.Project{Name: "Alpha"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	archived, err := s.SaveProject(admin, entity.Project{Name: "Old"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.ArchiveProject(admin, archived.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	start := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC)
+	report, tasks, err := s.SaveReportWithTasks(admin, entity.Report{DeveloperID: devID}, []entity.Task{{
+		ProjectID:      project.ID,
+		Name:           "task",
+		EstimatePlaned: 2,
+		StartTimestamp: start,
+		EndTimestamp:   start.Add(2 * time.Hour),
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return fixture{s: s, developer: developer, project: project, archived: archived, report: report, task: tasks[0]}
+}
+
+// validTask - задача в отчете фикстуры, которую можно сохранить
+func (f fixture) validTask() entity.Task {
+	task := f.task
+	task.ID = 0
+	return task
+}
+
+// TestErrorSemantics проверяет, что хранилище в памяти возвращает те же
+// ошибки пакета storage, что и postgres.Storage
+func TestErrorSemantics(t *testing.T) {
+	tests := []struct {
+		name string
+		call func(f fixture) error
+		want error
+	}{
+		{"missing project", func(f fixture) error {
+			_, err := f.s.GetProjectByID(999)
+			return err
+		}, er.ErrProjectNotFound},
+		{"missing task", func(f fixture) error {
+			_, err := f.s.GetTaskByID(999)
+			return err
+		}, er.ErrTaskNotFound},
+		{"missing report", func(f fixture) error {
+			_, err := f.s.GetReportById(999)
+			return err
+		}, er.ErrReportNotFound},
+		{"missing developer", func(f fixture) error {
+			_, err := f.s.GetDeveloperByID(uuid.New())
+			return err
+		}, er.ErrDeveloperNotFound},
+		{"project without name", func(f fixture) error {
+			_, err := f.s.SaveProject(admin, entity.Project{})
+			return err
+		}, er.ErrInvalidProjectData},
+		{"project with unknown manager", func(f fixture) error {
+			manager := uuid.New()
+			_, err := f.s.SaveProject(admin, entity.Project{Name: "Beta", ManagerID: &manager})
+			return err
+		}, er.ErrForeignKeyViolation},
+		{"developer with unknown role", func(f fixture) error {
+			_, err := f.s.SaveDeveloper(admin, entity.Developer{Name: "Bob", LastName: "Ray", Role: "owner"})
+			return err
+		}, er.ErrInvalidDeveloperData},
+		{"task ending before start", func(f fixture) error {
+			task := f.validTask()
+			task.EndTimestamp = task.StartTimestamp.Add(-time.Hour)
+			_, err := f.s.SaveTask(admin, task)
+			return err
+		}, er.ErrInvalidTaskData},
+		{"task in unknown project", func(f fixture) error {
+			task := f.validTask()
+			task.ProjectID = 999
+			_, err := f.s.SaveTask(admin, task)
+			return err
+		}, er.ErrForeignKeyViolation},
+		{"task in archived project", func(f fixture) error {
+			task := f.validTask()
+			task.ProjectID = f.archived.ID
+			_, err := f.s.SaveTask(admin, task)
+			return err
+		}, er.ErrProjectArchived},
+		{"task moved to archived project", func(f fixture) error {
+			task := f.task
+			task.ProjectID = f.archived.ID
+			return f.s.UpdateTask(admin, task.ID, task, f.task.ModifiedAt)
+		}, er.ErrProjectArchived},
+		{"task patched into archived project", func(f fixture) error {
+			patch := entity.TaskPatch{ProjectID: entity.Change(f.archived.ID)}
+			return f.s.PatchTask(admin, f.task.ID, patch, f.task.ModifiedAt)
+		}, er.ErrProjectArchived},
+		{"stale project version", func(f fixture) error {
+			project := f.project
+			project.Name = "Renamed"
+			return f.s.UpdateProject(admin, project.ID, project, f.project.ModifiedAt.Add(-time.Second))
+		}, er.ErrVersionMismatch},
+		{"stale task version", func(f fixture) error {
+			return f.s.UpdateTask(admin, f.task.ID, f.task, f.task.ModifiedAt.Add(-time.Second))
+		}, er.ErrVersionMismatch},
+		{"stale developer patch", func(f fixture) error {
+			patch := entity.DeveloperPatch{Name: entity.Change("Anna")}
+			return f.s.PatchDeveloper(admin, f.developer.ID, patch, f.developer.ModifiedAt.Add(-time.Second))
+		}, er.ErrVersionMismatch},
+		{"patch with unknown role", func(f fixture) error {
+			patch := entity.DeveloperPatch{Role: entity.Change("owner")}
+			return f.s.PatchDeveloper(admin, f.developer.ID, patch, f.developer.ModifiedAt)
+		}, er.ErrInvalidDeveloperData},
+		{"update deleted developer", func(f fixture) error {
+			if err := f.s.SoftDeleteDeveloper(admin, f.developer.ID); err != nil {
+				return err
+			}
+			deleted, _ := f.s.GetDeveloperByID(f.developer.ID)
+			return f.s.UpdateDeveloper(admin, f.developer.ID, f.developer, deleted.ModifiedAt)
+		}, er.ErrDeveloperNotFound},
+		{"archive deleted project", func(f fixture) error {
+			if err := f.s.DeleteProject(admin, f.project.ID); err != nil {
+				return err
+			}
+			return f.s.ArchiveProject(admin, f.project.ID)
+		}, er.ErrProjectNotFound},
+		{"report for unknown developer", func(f fixture) error {
+			_, _, err := f.s.SaveReportWithTasks(admin, entity.Report{DeveloperID: uuid.New()}, nil)
+			return err
+		}, er.ErrForeignKeyViolation},
+		{"unknown sort", func(f fixture) error {
+			_, _, err := f.s.GetTasks(er.ListParams{Sort: "name"})
+			return err
+		}, er.ErrInvalidSort},
+		{"malformed cursor", func(f fixture) error {
+			_, _, err := f.s.GetTasks(er.ListParams{Cursor: "not-a-cursor"})
+			return err
+		}, er.ErrInvalidCursor},
+		{"cursor of another sort", func(f fixture) error {
+			cursor := er.EncodeCursor(er.Cursor{Sort: "created_at", Value: time.Now().Format(time.RFC3339Nano), ID: "1"})
+			_, _, err := f.s.GetTasks(er.ListParams{Sort: "-start_timestamp", Cursor: cursor})
+			return err
+		}, er.ErrInvalidCursor},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.call(newFixture(t))
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestFailedSaveLeavesNoReport(t *testing.T) {
+	f := newFixture(t)
+
+	task := f.validTask()
+	task.ProjectID = f.archived.ID
+	if _, _, err := f.s.SaveReportWithTasks(admin, entity.Report{DeveloperID: f.developer.ID}, []entity.Task{task}); err == nil {
+		t.Fatal("report with a task in an archived project was saved")
+	}
+
+	reports, _ := f.s.GetReportsByDeveloperID(f.developer.ID)
+	if len(reports) != 1 {
+		t.Fatalf("got %d reports, want only the fixture report", len(reports))
+	}
+}
+
+func TestUpdateBumpsVersion(t *testing.T) {
+	f := newFixture(t)
+
+	project := f.project
+	project.Name = "Renamed"
+	if err := f.s.UpdateProject(admin, project.ID, project, f.project.ModifiedAt); err != nil {
+		t.Fatal(err)
+	}
+	updated, _ := f.s.GetProjectByID(project.ID)
+	if !updated.ModifiedAt.After(f.project.ModifiedAt) {
+		t.Fatalf("modified_at was not bumped: %v -> %v", f.project.ModifiedAt, updated.ModifiedAt)
+	}
+
+	// Запись по прежней версии после успешного обновления отклоняется
+	project.Name = "Lost update"
+	if err := f.s.UpdateProject(admin, project.ID, project, f.project.ModifiedAt); !errors.Is(err, er.ErrVersionMismatch) {
+		t.Fatalf("got %v, want ErrVersionMismatch", err)
+	}
+}
+
+func TestEmptyPatchKeepsVersion(t *testing.T) {
+	f := newFixture(t)
+
+	if err := f.s.PatchProject(admin, f.project.ID, entity.ProjectPatch{}, f.project.ModifiedAt); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.s.PatchTask(admin, f.task.ID, entity.TaskPatch{}, f.task.ModifiedAt); err != nil {
+		t.Fatal(err)
+	}
+
+	project, _ := f.s.GetProjectByID(f.project.ID)
+	task, _ := f.s.GetTaskByID(f.task.ID)
+	if !project.ModifiedAt.Equal(f.project.ModifiedAt) || !task.ModifiedAt.Equal(f.task.ModifiedAt) {
+		t.Fatal("empty patch changed modified_at")
+	}
+}
+
+func TestSoftDeleteVisibility(t *testing.T) {
+	f := newFixture(t)
+
+	if err := f.s.SoftDeleteDeveloper(admin, f.developer.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.s.DeleteProject(admin, f.project.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Удаленный разработчик доступен по ID с отметкой удаления, но не в списке
+	developer, err := f.s.GetDeveloperByID(f.developer.ID)
+	if err != nil || developer.DeletedAt == nil {
+		t.Fatalf("deleted developer by ID: %+v, %v", developer, err)
+	}
+	if developers, _, _ := f.s.GetDevelopers(er.ListParams{}); len(developers) != 0 {
+		t.Fatalf("deleted developer is listed: %+v", developers)
+	}
+	if developers, _, _ := f.s.GetDevelopers(er.ListParams{IncludeDeleted: true}); len(developers) != 1 {
+		t.Fatalf("include_deleted returned %d developers, want 1", len(developers))
+	}
+
+	// Удаленный проект не находится ни по ID, ни по имени, ни в списке
+	if _, err := f.s.GetProjectByID(f.project.ID); !errors.Is(err, er.ErrProjectNotFound) {
+		t.Fatalf("deleted project by ID: %v", err)
+	}
+	if _, err := f.s.GetProjectByName(f.project.Name); !errors.Is(err, er.ErrProjectNotFound) {
+		t.Fatalf("deleted project by name: %v", err)
+	}
+	if projects, _, _ := f.s.GetProject(er.ListParams{}); len(projects) != 1 || projects[0].ID != f.archived.ID {
+		t.Fatalf("projects after delete: %+v", projects)
+	}
+
+	if err := f.s.RestoreProject(admin, f.project.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := f.s.GetProjectByID(f.project.ID); err != nil {
+		t.Fatalf("restored project: %v", err)
+	}
+}
+
+func TestDeveloperDeleteRestoreIdempotent(t *testing.T) {
+	f := newFixture(t)
+	id := f.developer.ID
+
+	// developerState возвращает версию разработчика и число его записей аудита
+	developerState := func() (time.Time, int) {
+		t.Helper()
+		developer, err := f.s.GetDeveloperByID(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries, _, err := f.s.GetAudit(er.ListParams{Entity: entity.AuditDeveloper, EntityID: id.String()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return developer.ModifiedAt, len(entries)
+	}
+
+	// Восстановление неудаленного разработчика ничего не меняет
+	version, entries := developerState()
+	if err := f.s.RestoreDeveloper(admin, id); err != nil {
+		t.Fatal(err)
+	}
+	if v, n := developerState(); !v.Equal(version) || n != entries {
+		t.Fatalf("restore of active developer: modified_at %s -> %s, audit %d -> %d", version, v, entries, n)
+	}
+
+	if err := f.s.SoftDeleteDeveloper(admin, id); err != nil {
+		t.Fatal(err)
+	}
+	version, entries = developerState()
+	// Повторное удаление - тоже пустая операция
+	if err := f.s.SoftDeleteDeveloper(admin, id); err != nil {
+		t.Fatal(err)
+	}
+	if v, n := developerState(); !v.Equal(version) || n != entries {
+		t.Fatalf("second delete: modified_at %s -> %s, audit %d -> %d", version, v, entries, n)
+	}
+
+	if err := f.s.RestoreDeveloper(admin, id); err != nil {
+		t.Fatal(err)
+	}
+	if _, n := developerState(); n != entries+1 {
+		t.Fatalf("restore of deleted developer wrote %d audit entries, want 1", n-entries)
+	}
+
+	if err := f.s.SoftDeleteDeveloper(admin, uuid.New()); !errors.Is(err, er.ErrDeveloperNotFound) {
+		t.Fatalf("delete of missing developer: %v", err)
+	}
+}
+
+func TestPagination(t *testing.T) {
+	s := New()
+	// Повторяющиеся имена проверяют, что при равных значениях порядок
+	// определяет id и курсор не теряет и не повторяет записи
+	for _, name := range []string{"b", "a", "c", "a", "b", "d", "a"} {
+		if _, err := s.SaveProject(admin, entity.Project{Name: name}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		sort string
+		want []uint
+	}{
+		{"name", []uint{2, 4, 7, 1, 5, 3, 6}},
+		{"-name", []uint{6, 3, 5, 1, 7, 4, 2}},
+		{"id", []uint{1, 2, 3, 4, 5, 6, 7}},
+		{"-id", []uint{7, 6, 5, 4, 3, 2, 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.sort, func(t *testing.T) {
+			var got []uint
+			params := er.ListParams{Limit: 3, Sort: tt.sort}
+			for pages := 0; ; pages++ {
+				if pages > len(tt.want) {
+					t.Fatal("pagination does not terminate")
+				}
+				projects, next, err := s.GetProject(params)
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, p := range projects {
+					got = append(got, p.ID)
+				}
+				if next == "" {
+					break
+				}
+				params.Cursor = next
+			}
+
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("got %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestListFilters(t *testing.T) {
+	f := newFixture(t)
+
+	other, err := f.s.SaveDeveloper(admin, entity.Developer{Name: "Bob", LastName: "Ray"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		params er.ListParams
+		want   int
+	}{
+		{"all", er.ListParams{}, 1},
+		{"by developer", er.ListParams{DeveloperID: f.developer.ID}, 1},
+		{"by other developer", er.ListParams{DeveloperID: other}, 0},
+		{"by project", er.ListParams{ProjectID: f.project.ID}, 1},
+		{"by other project", er.ListParams{ProjectID: f.archived.ID}, 0},
+		{"from is inclusive", er.ListParams{From: f.task.StartTimestamp}, 1},
+		{"to is exclusive", er.ListParams{To: f.task.StartTimestamp}, 0},
+		{"by manager without projects", er.ListParams{ManagerID: other}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, _, err := f.s.GetTasks(tt.params)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tasks) != tt.want {
+				t.Fatalf("got %d tasks, want %d", len(tasks), tt.want)
+			}
+		})
+	}
+}
+
+// TestEstimateStats закрепляет числа аналитики; postgres.Storage считает их
+// тем же набором агрегатов (estimateAggregates)
+func TestEstimateStats(t *testing.T) {
+	f := newFixture(t)
+	// Задача фикстуры: Alpha, план 2 ч, залогировано 0, 09:00-11:00 1 октября
+
+	bobID, err := f.s.SaveDeveloper(admin, entity.Developer{Name: "Bob", LastName: "Adams"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	beta, err := f.s.SaveProject(admin, entity.Project{Name: "Beta"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2026, 10, 2, 0, 0, 0, 0, time.UTC)
+	_, _, err = f.s.SaveReportWithTasks(admin, entity.Report{DeveloperID: f.developer.ID}, []entity.Task{
+		{ProjectID: f.project.ID, Name: "overrun", EstimatePlaned: 3, EstimateProgress: 5,
+			StartTimestamp: day.Add(9 * time.Hour), EndTimestamp: day.Add(10*time.Hour + 30*time.Minute)},
+		{ProjectID: beta.ID, Name: "beta", EstimatePlaned: 4, EstimateProgress: 1,
+			StartTimestamp: day.Add(12 * time.Hour), EndTimestamp: day.Add(12*time.Hour + 15*time.Minute)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, err = f.s.SaveReportWithTasks(admin, entity.Report{DeveloperID: bobID}, []entity.Task{
+		{ProjectID: beta.ID, Name: "bob", EstimatePlaned: 2, EstimateProgress: 2,
+			StartTimestamp: day.Add(14 * time.Hour), EndTimestamp: day.Add(17 * time.Hour)},
+		// Начинается ровно в конце периода и в статистику не входит
+		{ProjectID: beta.ID, Name: "later", EstimatePlaned: 8, EstimateProgress: 8,
+			StartTimestamp: day.AddDate(0, 0, 1), EndTimestamp: day.AddDate(0, 0, 1).Add(time.Hour)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	from, to := time.Date(2026, 10, 1, 9, 0, 0, 0, time.UTC), day.AddDate(0, 0, 1)
+
+	projects, err := f.s.GetProjectStats(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantProjects := []entity.ProjectStats{
+		{ProjectID: f.project.ID, ProjectName: "Alpha", EstimateStats: entity.EstimateStats{
+			TaskCount: 2, PlannedHours: 5, LoggedHours: 5, ActualHours: 3.5, OverrunRatio: 1,
+		}},
+		{ProjectID: beta.ID, ProjectName: "Beta", EstimateStats: entity.EstimateStats{
+			TaskCount: 2, PlannedHours: 6, LoggedHours: 3, ActualHours: 3.25, OverrunRatio: 0.5,
+		}},
+	}
+	if len(projects) != len(wantProjects) {
+		t.Fatalf("got %d projects, want %d: %+v", len(projects), len(wantProjects), projects)
+	}
+	for i, want := range wantProjects {
+		if projects[i] != want {
+			t.Errorf("project %d: %+v, want %+v", i, projects[i], want)
+		}
+	}
+
+	developers, err := f.s.GetDeveloperStats(from, to)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Сортировка по фамилии: Adams, затем Lee
+	wantDevelopers := []entity.DeveloperStats{
+		{DeveloperID: bobID, Name: "Bob", LastName: "Adams", EstimateStats: entity.EstimateStats{
+			TaskCount: 1, PlannedHours: 2, LoggedHours: 2, ActualHours: 3, OverrunRatio: 1,
+		}},
+		{DeveloperID: f.developer.ID, Name: "Ann", LastName: "Lee", EstimateStats: entity.EstimateStats{
+			TaskCount: 3, PlannedHours: 9, LoggedHours: 6, ActualHours: 3.75, OverrunRatio: 6.0 / 9,
+		}},
+	}
+	if len(developers) != len(wantDevelopers) {
+		t.Fatalf("got %d developers, want %d: %+v", len(developers), len(wantDevelopers), developers)
+	}
+	for i, want := range wantDevelopers {
+		if developers[i] != want {
+			t.Errorf("developer %d: %+v, want %+v", i, developers[i], want)
+		}
+	}
+
+	// Пустой период - пустой список
+	if stats, err := f.s.GetProjectStats(to, to.AddDate(0, 0, -1)); err != nil || len(stats) != 0 {
+		t.Fatalf("empty period: %+v, %v", stats, err)
+	}
+}
+
+func TestFinishStatsWithoutPlan(t *testing.T) {
+	// При пустом плане отношение равно 0, как NULLIF в postgres, а не Inf или NaN
+	st := entity.EstimateStats{TaskCount: 1, LoggedHours: 4}
+	finishStats(&st)
+	if st.OverrunRatio != 0 {
+		t.Fatalf("OverrunRatio = %v, want 0", st.OverrunRatio)
+	}
+}
@@ -0,0 +1,158 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"goproject/internal/metrics"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// inTx выполняет fn в транзакции и фиксирует ее, если fn не вернула ошибку
+func (s *Storage) inTx(op string, fn func(tx *sql.Tx) error) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return fmt.Errorf("%s: begin transaction: %w", op, err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("%s: commit transaction: %w", op, err)
+	}
+
+	return nil
+}
+
+// writeAudit добавляет запись в журнал аудита в транзакции изменения.
+// before равен nil при создании записи, after - при удалении. Если запись
+// не изменилась, журнал не пополняется.
+func writeAudit(tx *sql.Tx, actor entity.Actor, entityType string, entityID interface{}, action string, before, after interface{}) error {
+	diff, err := er.Diff(before, after)
+	if err != nil {
+		return fmt.Errorf("audit diff: %w", err)
+	}
+	if er.IsEmptyDiff(diff) {
+		return nil
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO audit_log(actor_id, actor_role, entity_type, entity_id, action, diff)
+		VALUES ($1, $2, $3, $4, $5, $6)`,
+		er.ActorID(actor), actor.Role, entityType, fmt.Sprint(entityID), action, []byte(diff))
+	if err != nil {
+		return fmt.Errorf("insert audit entry: %w", err)
+	}
+
+	return nil
+}
+
+// writeTasksAudit записывает в журнал создание задач
+func writeTasksAudit(tx *sql.Tx, actor entity.Actor, tasks []entity.Task) error {
+	for _, task := range tasks {
+		if err := writeAudit(tx, actor, entity.AuditTask, task.ID, entity.ActionCreate, nil, task); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+var auditSorts = map[string]sortColumn{
+	"created_at": {"a.created_at", kindTime},
+	"id":         {"a.id", kindInt},
+}
+
+func auditSortValue(entry entity.AuditEntry, field string) interface{} {
+	if field == "created_at" {
+		return entry.CreatedAt
+	}
+	return entry.ID
+}
+
+// GetAudit возвращает страницу журнала аудита. Фильтры: тип и ID сущности,
+// автор (DeveloperID), интервал по created_at. Сортировка: created_at
+// (по умолчанию по убыванию), id.
+func (s *Storage) GetAudit(params er.ListParams) ([]entity.AuditEntry, string, error) {
+	const op = "storage.postgres.GetAudit"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	var q listQuery
+	if params.Entity != "" {
+		q.add("a.entity_type = " + q.arg(params.Entity))
+	}
+	if params.EntityID != "" {
+		q.add("a.entity_id = " + q.arg(params.EntityID))
+	}
+	if params.DeveloperID != uuid.Nil {
+		q.add("a.actor_id = " + q.arg(params.DeveloperID))
+	}
+	if !params.From.IsZero() {
+		q.add("a.created_at >= " + q.arg(params.From))
+	}
+	if !params.To.IsZero() {
+		q.add("a.created_at < " + q.arg(params.To))
+	}
+
+	ks, err := q.page(params, auditSorts, "-created_at", sortColumn{"a.id", kindInt})
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	stmt, err := s.db.Prepare(`
+    SELECT a.id, a.actor_id, a.actor_role, a.entity_type, a.entity_id, a.action, a.diff, a.created_at
+    FROM audit_log a
+    ` + q.whereClause() + `
+    ` + ks.tail())
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: prepare statement: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(q.args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer rows.Close()
+
+	var entries []entity.AuditEntry
+	for rows.Next() {
+		var (
+			entry entity.AuditEntry
+			diff  []byte
+		)
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ActorID,
+			&entry.ActorRole,
+			&entry.EntityType,
+			&entry.EntityID,
+			&entry.Action,
+			&diff,
+			&entry.CreatedAt,
+		)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		entry.Diff = diff
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	var next string
+	if len(entries) > ks.limit {
+		entries = entries[:ks.limit]
+		last := entries[len(entries)-1]
+		next = ks.next(auditSortValue(last, ks.name), last.ID)
+	}
+
+	return entries, next, nil
+}
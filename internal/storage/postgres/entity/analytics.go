@@ -0,0 +1,36 @@
+package entity
+
+import "github.com/google/uuid"
+
+// EstimateStats - сравнение плановых и фактических трудозатрат по набору задач
+type EstimateStats struct {
+	TaskCount    int
+	PlannedHours int
+	LoggedHours  int
+	// ActualHours - длительность задач по отметкам начала и окончания
+	ActualHours float64
+	// OverrunRatio - отношение залогированных часов к плановым, 0 если план пуст
+	OverrunRatio float64
+}
+
+type ProjectStats struct {
+	ProjectID   uint
+	ProjectName string
+	EstimateStats
+}
+
+type DeveloperStats struct {
+	DeveloperID uuid.UUID
+	Name        string
+	LastName    string
+	EstimateStats
+}
+
+// ActivityStats - активность с начала периода для метрик сервиса
+type ActivityStats struct {
+	ReportsSubmitted int
+	ReportsApproved  int
+	TasksCreated     int
+	// ActiveDevelopers - разработчики, отправившие хотя бы один отчет
+	ActiveDevelopers int
+}
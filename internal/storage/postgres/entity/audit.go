@@ -0,0 +1,48 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Типы записей журнала аудита
+const (
+	AuditProject   = "project"
+	AuditDeveloper = "developer"
+	AuditReport    = "report"
+	AuditTask      = "task"
+	AuditToken     = "token"
+)
+
+// Действия журнала аудита
+const (
+	ActionCreate  = "create"
+	ActionUpdate  = "update"
+	ActionDelete  = "delete"
+	ActionRestore = "restore"
+	ActionArchive = "archive"
+	ActionApprove = "approve"
+	ActionRevoke  = "revoke"
+)
+
+// Actor - автор изменения. DeveloperID пустой для токена администратора
+// из конфигурации.
+type Actor struct {
+	DeveloperID uuid.UUID
+	Role        string
+}
+
+// AuditEntry - запись журнала аудита. Diff - JSON-объект вида
+// {"Поле": {"old": ..., "new": ...}} только по изменившимся полям.
+type AuditEntry struct {
+	ID         uint
+	ActorID    *uuid.UUID
+	ActorRole  string
+	EntityType string
+	EntityID   string
+	Action     string
+	Diff       json.RawMessage
+	CreatedAt  time.Time
+}
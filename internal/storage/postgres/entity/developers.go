@@ -6,10 +6,30 @@ import (
 	"github.com/google/uuid"
 )
 
+// Роли разработчиков
+const (
+	// RoleDeveloper ведет свои отчеты и задачи
+	RoleDeveloper = "developer"
+	// RoleManager читает и утверждает отчеты разработчиков своих проектов
+	RoleManager = "manager"
+	// RoleAdmin управляет проектами и разработчиками
+	RoleAdmin = "admin"
+)
+
+// ValidRole сообщает, является ли строка известной ролью
+func ValidRole(role string) bool {
+	switch role {
+	case RoleDeveloper, RoleManager, RoleAdmin:
+		return true
+	}
+	return false
+}
+
 type Developer struct {
 	ID         uuid.UUID
 	Name       string
 	LastName   string
+	Role       string
 	CreatedAt  time.Time
 	ModifiedAt time.Time
 	DeletedAt  *time.Time
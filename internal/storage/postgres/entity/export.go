@@ -0,0 +1,32 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskExport - строка выгрузки задач: задача вместе с именами разработчика
+// и проекта и отметкой об утверждении отчета
+type TaskExport struct {
+	Task
+	DeveloperID       uuid.UUID
+	DeveloperName     string
+	DeveloperLastName string
+	ProjectName       string
+	ApprovedAt        *time.Time
+}
+
+// ReportExport - строка выгрузки отчетов: отчет с именем разработчика и
+// итогами по всем его задачам. У отчета без задач FirstStart и LastEnd
+// равны nil.
+type ReportExport struct {
+	Report
+	DeveloperName     string
+	DeveloperLastName string
+	TaskCount         int
+	EstimatePlaned    int
+	EstimateProgress  int
+	FirstStart        *time.Time
+	LastEnd           *time.Time
+}
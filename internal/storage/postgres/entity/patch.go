@@ -0,0 +1,90 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Field - поле частичного обновления. Set отличает поле, которое нужно
+// изменить (в том числе на nil или пустое значение), от поля, которое не
+// меняется.
+type Field[T any] struct {
+	Value T
+	Set   bool
+}
+
+// Change возвращает поле с новым значением v
+func Change[T any](v T) Field[T] {
+	return Field[T]{Value: v, Set: true}
+}
+
+func (f Field[T]) apply(dst *T) {
+	if f.Set {
+		*dst = f.Value
+	}
+}
+
+// ProjectPatch - изменяемые поля проекта
+type ProjectPatch struct {
+	Name        Field[string]
+	Description Field[string]
+	ManagerID   Field[*uuid.UUID]
+}
+
+// Empty сообщает, что патч ничего не меняет
+func (p ProjectPatch) Empty() bool {
+	return !p.Name.Set && !p.Description.Set && !p.ManagerID.Set
+}
+
+// Apply переносит измененные поля в project
+func (p ProjectPatch) Apply(project *Project) {
+	p.Name.apply(&project.Name)
+	p.Description.apply(&project.Description)
+	p.ManagerID.apply(&project.ManagerID)
+}
+
+// DeveloperPatch - изменяемые поля разработчика
+type DeveloperPatch struct {
+	Name     Field[string]
+	LastName Field[string]
+	Role     Field[string]
+}
+
+func (p DeveloperPatch) Empty() bool {
+	return !p.Name.Set && !p.LastName.Set && !p.Role.Set
+}
+
+func (p DeveloperPatch) Apply(developer *Developer) {
+	p.Name.apply(&developer.Name)
+	p.LastName.apply(&developer.LastName)
+	p.Role.apply(&developer.Role)
+}
+
+// TaskPatch - изменяемые поля задачи
+type TaskPatch struct {
+	ReportID         Field[uint]
+	ProjectID        Field[uint]
+	Name             Field[string]
+	DeveloperNote    Field[string]
+	EstimatePlaned   Field[int]
+	EstimateProgress Field[int]
+	StartTimestamp   Field[time.Time]
+	EndTimestamp     Field[time.Time]
+}
+
+func (p TaskPatch) Empty() bool {
+	return !p.ReportID.Set && !p.ProjectID.Set && !p.Name.Set && !p.DeveloperNote.Set &&
+		!p.EstimatePlaned.Set && !p.EstimateProgress.Set && !p.StartTimestamp.Set && !p.EndTimestamp.Set
+}
+
+func (p TaskPatch) Apply(task *Task) {
+	p.ReportID.apply(&task.ReportID)
+	p.ProjectID.apply(&task.ProjectID)
+	p.Name.apply(&task.Name)
+	p.DeveloperNote.apply(&task.DeveloperNote)
+	p.EstimatePlaned.apply(&task.EstimatePlaned)
+	p.EstimateProgress.apply(&task.EstimateProgress)
+	p.StartTimestamp.apply(&task.StartTimestamp)
+	p.EndTimestamp.apply(&task.EndTimestamp)
+}
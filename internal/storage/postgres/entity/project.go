@@ -1,6 +1,10 @@
 package entity
 
-import "time"
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type Project struct {
 	ID          uint
@@ -8,5 +12,9 @@ type Project struct {
 	Description string
 	CreatedAt   time.Time
 	ModifiedAt  time.Time
-	DeletedAt   *time.Time
+	// ManagerID - менеджер проекта, утверждающий отчеты по его задачам
+	ManagerID *uuid.UUID
+	// ArchivedAt - проект в архиве: виден в истории, но новые задачи в нем не создаются
+	ArchivedAt *time.Time
+	DeletedAt  *time.Time
 }
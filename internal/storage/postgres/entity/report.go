@@ -10,4 +10,7 @@ type Report struct {
 	ID          uint
 	DeveloperID uuid.UUID
 	CreatedAt   time.Time
+	// ApprovedAt и ApprovedBy заполняются, когда менеджер утверждает отчет
+	ApprovedAt *time.Time
+	ApprovedBy *uuid.UUID
 }
@@ -15,4 +15,7 @@ type Task struct {
 	StartTimestamp   time.Time
 	EndTimestamp     time.Time
 	CreatedAt        time.Time
+	ModifiedAt       time.Time
+	// Sequence - номер ревизии, растет с каждым изменением задачи
+	Sequence int
 }
@@ -0,0 +1,16 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// APIToken - токен доступа разработчика. Сам токен не хранится, только его хэш.
+type APIToken struct {
+	ID          uint
+	DeveloperID uuid.UUID
+	Name        string
+	CreatedAt   time.Time
+	RevokedAt   *time.Time
+}
@@ -0,0 +1,39 @@
+package postgres
+
+import (
+	"errors"
+	er "goproject/internal/storage"
+
+	"github.com/lib/pq"
+)
+
+// Коды ошибок PostgreSQL класса 23 (integrity constraint violation)
+const (
+	pqNotNullViolation    = "23502"
+	pqForeignKeyViolation = "23503"
+	pqUniqueViolation     = "23505"
+	pqCheckViolation      = "23514"
+)
+
+// mapError переводит нарушения ограничений из pq.Error в ошибки пакета storage.
+// Остальные ошибки возвращаются без изменений.
+func mapError(err error) error {
+	var pqErr *pq.Error
+	if !errors.As(err, &pqErr) {
+		return err
+	}
+
+	var sentinel error
+	switch pqErr.Code {
+	case pqUniqueViolation:
+		sentinel = er.ErrUniqueViolation
+	case pqForeignKeyViolation:
+		sentinel = er.ErrForeignKeyViolation
+	case pqCheckViolation, pqNotNullViolation:
+		sentinel = er.ErrCheckViolation
+	default:
+		return err
+	}
+
+	return &er.ConstraintError{Constraint: pqErr.Constraint, Err: sentinel}
+}
@@ -0,0 +1,163 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"goproject/internal/metrics"
+	er "goproject/internal/storage"
+	"goproject/internal/storage/postgres/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ExportTasks читает задачи курсором sql.Rows и передает их в fn по мере
+// чтения. Соединение с базой занято, пока fn пишет выгрузку клиенту; отмена
+// ctx прерывает запрос.
+func (s *Storage) ExportTasks(ctx context.Context, params er.ListParams, fn func(entity.TaskExport) error) error {
+	const op = "storage.postgres.ExportTasks"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	var q listQuery
+	if params.DeveloperID != uuid.Nil {
+		q.add("r.developer_id = " + q.arg(params.DeveloperID))
+	}
+	if params.ProjectID != 0 {
+		q.add("t.project_id = " + q.arg(params.ProjectID))
+	}
+	if params.ManagerID != uuid.Nil {
+		q.add("p.manager_id = " + q.arg(params.ManagerID))
+	}
+	if !params.From.IsZero() {
+		q.add("t.start_timestamp >= " + q.arg(params.From))
+	}
+	if !params.To.IsZero() {
+		q.add("t.start_timestamp < " + q.arg(params.To))
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT t.id, t.report_id, t.project_id, t.name, t.developer_note,
+               t.estimate_planed, t.estimate_progress,
+               t.start_timestamp, t.end_timestamp, t.created_at, t.modified_at, t.sequence,
+               r.developer_id, d.name, d.last_name, p.name, r.approved_at
+        FROM tasks t
+        JOIN reports r ON r.id = t.report_id
+        JOIN developers d ON d.id = r.developer_id
+        JOIN projects p ON p.id = t.project_id
+        `+q.whereClause()+`
+        ORDER BY t.start_timestamp, t.id`, q.args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row entity.TaskExport
+		err := rows.Scan(
+			&row.ID,
+			&row.ReportID,
+			&row.ProjectID,
+			&row.Name,
+			&row.DeveloperNote,
+			&row.EstimatePlaned,
+			&row.EstimateProgress,
+			&row.StartTimestamp,
+			&row.EndTimestamp,
+			&row.CreatedAt,
+			&row.ModifiedAt,
+			&row.Sequence,
+			&row.DeveloperID,
+			&row.DeveloperName,
+			&row.DeveloperLastName,
+			&row.ProjectName,
+			&row.ApprovedAt,
+		)
+		if err != nil {
+			return fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		if err := fn(row); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return nil
+}
+
+// ExportReports читает отчеты с итогами по задачам курсором sql.Rows, как
+// ExportTasks
+func (s *Storage) ExportReports(ctx context.Context, params er.ListParams, fn func(entity.ReportExport) error) error {
+	const op = "storage.postgres.ExportReports"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	var q listQuery
+	if params.DeveloperID != uuid.Nil {
+		q.add("r.developer_id = " + q.arg(params.DeveloperID))
+	}
+	if params.ManagerID != uuid.Nil {
+		q.add(`EXISTS (
+			SELECT 1 FROM tasks mt
+			JOIN projects mp ON mp.id = mt.project_id
+			WHERE mt.report_id = r.id AND mp.manager_id = ` + q.arg(params.ManagerID) + `)`)
+	}
+	if params.ProjectID != 0 {
+		q.add(`EXISTS (
+			SELECT 1 FROM tasks pt
+			WHERE pt.report_id = r.id AND pt.project_id = ` + q.arg(params.ProjectID) + `)`)
+	}
+	if !params.From.IsZero() {
+		q.add("r.created_at >= " + q.arg(params.From))
+	}
+	if !params.To.IsZero() {
+		q.add("r.created_at < " + q.arg(params.To))
+	}
+
+	rows, err := s.db.QueryContext(ctx, `
+        SELECT r.id, r.developer_id, r.created_at, r.approved_at, r.approved_by,
+               d.name, d.last_name,
+               COUNT(t.id), COALESCE(SUM(t.estimate_planed), 0), COALESCE(SUM(t.estimate_progress), 0),
+               MIN(t.start_timestamp), MAX(t.end_timestamp)
+        FROM reports r
+        JOIN developers d ON d.id = r.developer_id
+        LEFT JOIN tasks t ON t.report_id = r.id
+        `+q.whereClause()+`
+        GROUP BY r.id, d.id
+        ORDER BY r.created_at, r.id`, q.args...)
+	if err != nil {
+		return fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row entity.ReportExport
+		err := rows.Scan(
+			&row.ID,
+			&row.DeveloperID,
+			&row.CreatedAt,
+			&row.ApprovedAt,
+			&row.ApprovedBy,
+			&row.DeveloperName,
+			&row.DeveloperLastName,
+			&row.TaskCount,
+			&row.EstimatePlaned,
+			&row.EstimateProgress,
+			&row.FirstStart,
+			&row.LastEnd,
+		)
+		if err != nil {
+			return fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		if err := fn(row); err != nil {
+			return fmt.Errorf("%s: %w", op, err)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return nil
+}
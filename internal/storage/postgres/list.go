@@ -0,0 +1,157 @@
+package postgres
+
+import (
+	"fmt"
+	er "goproject/internal/storage"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type columnKind int
+
+const (
+	kindTime columnKind = iota
+	kindText
+	kindInt
+	kindUUID
+)
+
+// sortColumn - колонка, по которой разрешена сортировка списка
+type sortColumn struct {
+	column string
+	kind   columnKind
+}
+
+// listQuery собирает условия WHERE и аргументы параметризованного запроса списка
+type listQuery struct {
+	where []string
+	args  []interface{}
+}
+
+// arg добавляет аргумент и возвращает его плейсхолдер
+func (q *listQuery) arg(v interface{}) string {
+	q.args = append(q.args, v)
+	return fmt.Sprintf("$%d", len(q.args))
+}
+
+func (q *listQuery) add(cond string) {
+	q.where = append(q.where, cond)
+}
+
+func (q *listQuery) whereClause() string {
+	if len(q.where) == 0 {
+		return ""
+	}
+	return "WHERE " + strings.Join(q.where, " AND ")
+}
+
+// keyset - порядок сортировки страницы, построенный по ListParams
+type keyset struct {
+	sort string
+	// name - поле сортировки без направления
+	name  string
+	field sortColumn
+	id    sortColumn
+	desc  bool
+	limit int
+}
+
+// page добавляет в запрос условие keyset-пагинации и возвращает порядок
+// сортировки. Записи упорядочиваются по полю сортировки, затем по id.
+func (q *listQuery) page(params er.ListParams, columns map[string]sortColumn, defaultSort string, id sortColumn) (keyset, error) {
+	name, desc := params.SortField(defaultSort)
+	field, ok := columns[name]
+	if !ok {
+		return keyset{}, er.ErrInvalidSort
+	}
+
+	ks := keyset{
+		sort:  name,
+		name:  name,
+		field: field,
+		id:    id,
+		desc:  desc,
+		limit: params.PageLimit(),
+	}
+	if desc {
+		ks.sort = "-" + name
+	}
+
+	if params.Cursor != "" {
+		cursor, err := er.DecodeCursor(params.Cursor, ks.sort)
+		if err != nil {
+			return keyset{}, err
+		}
+		value, err := parseValue(field.kind, cursor.Value)
+		if err != nil {
+			return keyset{}, er.ErrInvalidCursor
+		}
+		idValue, err := parseValue(id.kind, cursor.ID)
+		if err != nil {
+			return keyset{}, er.ErrInvalidCursor
+		}
+
+		op := ">"
+		if desc {
+			op = "<"
+		}
+		q.add(fmt.Sprintf("(%s, %s) %s (%s%s, %s%s)",
+			field.column, id.column, op,
+			q.arg(value), field.kind.cast(), q.arg(idValue), id.kind.cast()))
+	}
+
+	return ks, nil
+}
+
+// tail возвращает ORDER BY и LIMIT; выбирается на одну запись больше
+// страницы, чтобы понять, есть ли следующая
+func (ks keyset) tail() string {
+	dir := "ASC"
+	if ks.desc {
+		dir = "DESC"
+	}
+	return fmt.Sprintf("ORDER BY %s %s, %s %s LIMIT %d", ks.field.column, dir, ks.id.column, dir, ks.limit+1)
+}
+
+// next возвращает курсор следующей страницы по последней записи текущей
+func (ks keyset) next(value, id interface{}) string {
+	return er.EncodeCursor(er.Cursor{
+		Sort:  ks.sort,
+		Value: formatValue(value),
+		ID:    formatValue(id),
+	})
+}
+
+func (k columnKind) cast() string {
+	switch k {
+	case kindTime:
+		return "::timestamptz"
+	case kindInt:
+		return "::bigint"
+	case kindUUID:
+		return "::uuid"
+	}
+	return "::text"
+}
+
+func parseValue(kind columnKind, s string) (interface{}, error) {
+	switch kind {
+	case kindTime:
+		return time.Parse(time.RFC3339Nano, s)
+	case kindInt:
+		return strconv.ParseInt(s, 10, 64)
+	case kindUUID:
+		return uuid.Parse(s)
+	}
+	return s, nil
+}
+
+func formatValue(v interface{}) string {
+	if t, ok := v.(time.Time); ok {
+		return t.UTC().Format(time.RFC3339Nano)
+	}
+	return fmt.Sprint(v)
+}
@@ -0,0 +1,261 @@
+package postgres
+
+import (
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed migrations/*.sql
+var migrationsFS embed.FS
+
+// migrationLockID - ключ advisory-блокировки, не дающий двум экземплярам
+// приложения применять миграции одновременно
+const migrationLockID = 7357001
+
+// ErrNoMigrations returns when there is nothing to roll back
+var ErrNoMigrations = errors.New("no applied migrations")
+
+// Migration - версия схемы и время ее применения (nil, если не применена)
+type Migration struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+
+	up   string
+	down string
+}
+
+// loadMigrations читает встроенные файлы вида NNNN_name.up.sql и NNNN_name.down.sql
+func loadMigrations() ([]Migration, error) {
+	const op = "storage.postgres.loadMigrations"
+
+	files, err := fs.Glob(migrationsFS, "migrations/*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", op, err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, file := range files {
+		base := path.Base(file)
+
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("%s: unexpected migration file %q", op, base)
+		}
+
+		name := strings.TrimSuffix(base, "."+direction+".sql")
+		versionStr, name, ok := strings.Cut(name, "_")
+		if !ok {
+			return nil, fmt.Errorf("%s: invalid migration file name %q", op, base)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil {
+			return nil, fmt.Errorf("%s: invalid migration version in %q: %w", op, base, err)
+		}
+
+		content, err := migrationsFS.ReadFile(file)
+		if err != nil {
+			return nil, fmt.Errorf("%s: read %q: %w", op, base, err)
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		}
+		if direction == "up" {
+			m.up = string(content)
+		} else {
+			m.down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" || m.down == "" {
+			return nil, fmt.Errorf("%s: migration %04d_%s must have up and down files", op, m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return migrations, nil
+}
+
+func (s *Storage) ensureMigrationsTable() error {
+	_, err := s.db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+		)`)
+	return err
+}
+
+func (s *Storage) appliedMigrations() (map[int]time.Time, error) {
+	rows, err := s.db.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]time.Time)
+	for rows.Next() {
+		var (
+			version   int
+			appliedAt time.Time
+		)
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+
+	return applied, rows.Err()
+}
+
+// MigrationStatus возвращает все известные миграции с отметкой о применении
+func (s *Storage) MigrationStatus() ([]Migration, error) {
+	const op = "storage.postgres.MigrationStatus"
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.ensureMigrationsTable(); err != nil {
+		return nil, fmt.Errorf("%s: create migrations table: %w", op, err)
+	}
+
+	applied, err := s.appliedMigrations()
+	if err != nil {
+		return nil, fmt.Errorf("%s: read applied migrations: %w", op, err)
+	}
+
+	for i := range migrations {
+		if at, ok := applied[migrations[i].Version]; ok {
+			at := at
+			migrations[i].AppliedAt = &at
+		}
+	}
+
+	return migrations, nil
+}
+
+// MigrateUp применяет все неприменённые миграции по возрастанию версии.
+// Каждая миграция выполняется в отдельной транзакции.
+func (s *Storage) MigrateUp() ([]Migration, error) {
+	const op = "storage.postgres.MigrateUp"
+
+	migrations, err := s.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, m := range migrations {
+		if m.AppliedAt != nil {
+			continue
+		}
+
+		applied, err := s.applyMigration(m, m.up, func(tx *sql.Tx) error {
+			_, err := tx.Exec(`INSERT INTO schema_migrations(version, name) VALUES ($1, $2)`, m.Version, m.Name)
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("%s: migration %04d_%s: %w", op, m.Version, m.Name, err)
+		}
+		if applied {
+			done = append(done, m)
+		}
+	}
+
+	return done, nil
+}
+
+// MigrateDown откатывает steps последних применённых миграций
+func (s *Storage) MigrateDown(steps int) ([]Migration, error) {
+	const op = "storage.postgres.MigrateDown"
+
+	migrations, err := s.MigrationStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		m := migrations[i]
+		if m.AppliedAt == nil {
+			continue
+		}
+
+		reverted, err := s.applyMigration(m, m.down, func(tx *sql.Tx) error {
+			_, err := tx.Exec(`DELETE FROM schema_migrations WHERE version = $1`, m.Version)
+			return err
+		})
+		if err != nil {
+			return done, fmt.Errorf("%s: migration %04d_%s: %w", op, m.Version, m.Name, err)
+		}
+		if reverted {
+			done = append(done, m)
+		}
+	}
+
+	if len(done) == 0 {
+		return nil, fmt.Errorf("%s: %w", op, ErrNoMigrations)
+	}
+
+	return done, nil
+}
+
+// applyMigration выполняет SQL миграции и запись в schema_migrations в одной
+// транзакции под advisory-блокировкой. Возвращает false, если другой экземпляр
+// уже успел применить или откатить эту версию.
+func (s *Storage) applyMigration(m Migration, script string, record func(tx *sql.Tx) error) (bool, error) {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return false, fmt.Errorf("begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, migrationLockID); err != nil {
+		return false, fmt.Errorf("acquire lock: %w", err)
+	}
+
+	var applied bool
+	err = tx.QueryRow(`SELECT EXISTS(SELECT 1 FROM schema_migrations WHERE version = $1)`, m.Version).Scan(&applied)
+	if err != nil {
+		return false, fmt.Errorf("check version: %w", err)
+	}
+	if applied != (m.AppliedAt != nil) {
+		return false, nil
+	}
+
+	if _, err := tx.Exec(script); err != nil {
+		return false, fmt.Errorf("execute script: %w", err)
+	}
+	if err := record(tx); err != nil {
+		return false, fmt.Errorf("record version: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("commit transaction: %w", err)
+	}
+
+	return true, nil
+}
@@ -1,3 +1,5 @@
+-- Исходная схема из docker/init/init.sql. Для баз, созданных этим скриптом,
+-- миграция ничего не меняет.
 CREATE TABLE IF NOT EXISTS developers (
     id SERIAL PRIMARY KEY,
     firstname VARCHAR(255) NOT NULL,
@@ -38,4 +40,5 @@ CREATE TABLE IF NOT EXISTS tasks (
     created_at TIMESTAMP NOT NULL DEFAULT NOW()
 );
 CREATE INDEX IF NOT EXISTS idx_tasks_report ON tasks(report_id);
-CREATE INDEX IF NOT EXISTS idx_tasks_project ON tasks(project_id);
\ No newline at end of file
+CREATE INDEX IF NOT EXISTS idx_tasks_project ON tasks(project_id);
+CREATE INDEX IF NOT EXISTS idx_tasks_start_end ON tasks(start_timestamp, end_timestamp);
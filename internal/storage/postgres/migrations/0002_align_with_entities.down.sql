@@ -0,0 +1,41 @@
+ALTER TABLE tasks
+    ALTER COLUMN start_timestamp TYPE TIMESTAMP USING start_timestamp AT TIME ZONE 'UTC',
+    ALTER COLUMN end_timestamp TYPE TIMESTAMP USING end_timestamp AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC';
+ALTER TABLE reports
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC';
+ALTER TABLE projects
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN modified_at TYPE TIMESTAMP USING modified_at AT TIME ZONE 'UTC';
+ALTER TABLE developers
+    ALTER COLUMN created_at TYPE TIMESTAMP USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN modified_at TYPE TIMESTAMP USING modified_at AT TIME ZONE 'UTC',
+    ALTER COLUMN deleted_at TYPE TIMESTAMP USING deleted_at AT TIME ZONE 'UTC';
+
+ALTER TABLE tasks DROP CONSTRAINT IF EXISTS tasks_timestamps_order;
+ALTER TABLE tasks ALTER COLUMN developer_note DROP NOT NULL;
+ALTER TABLE tasks ALTER COLUMN developer_note DROP DEFAULT;
+
+ALTER TABLE projects ALTER COLUMN description DROP DEFAULT;
+ALTER TABLE projects DROP COLUMN deleted_at;
+
+ALTER INDEX idx_developers_name RENAME TO idx_developers_firstname;
+ALTER TABLE developers RENAME COLUMN name TO firstname;
+
+-- developers.id: UUID -> SERIAL, вместе со ссылкой reports.developer_id
+ALTER TABLE reports DROP CONSTRAINT IF EXISTS reports_developer_id_fkey;
+CREATE SEQUENCE developers_id_seq;
+ALTER TABLE developers ADD COLUMN legacy_id INTEGER NOT NULL DEFAULT nextval('developers_id_seq');
+ALTER SEQUENCE developers_id_seq OWNED BY developers.legacy_id;
+ALTER TABLE reports ADD COLUMN developer_legacy_id INTEGER;
+UPDATE reports r SET developer_legacy_id = d.legacy_id FROM developers d WHERE d.id = r.developer_id;
+ALTER TABLE reports DROP COLUMN developer_id;
+ALTER TABLE reports RENAME COLUMN developer_legacy_id TO developer_id;
+ALTER TABLE reports ALTER COLUMN developer_id SET NOT NULL;
+ALTER TABLE developers DROP CONSTRAINT developers_pkey;
+ALTER TABLE developers DROP COLUMN id;
+ALTER TABLE developers RENAME COLUMN legacy_id TO id;
+ALTER TABLE developers ADD PRIMARY KEY (id);
+ALTER TABLE reports ADD CONSTRAINT reports_developer_id_fkey
+    FOREIGN KEY (developer_id) REFERENCES developers(id) ON DELETE CASCADE;
+CREATE INDEX idx_reports_developer ON reports(developer_id);
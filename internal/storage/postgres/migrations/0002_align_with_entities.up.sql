@@ -0,0 +1,46 @@
+-- Приводит схему к структурам entity: UUID-идентификаторы разработчиков,
+-- колонка name вместо firstname, deleted_at у проектов, время с часовым поясом.
+
+-- developers.id: SERIAL -> UUID, вместе со ссылкой reports.developer_id
+ALTER TABLE reports DROP CONSTRAINT IF EXISTS reports_developer_id_fkey;
+ALTER TABLE developers ADD COLUMN uid UUID NOT NULL DEFAULT gen_random_uuid();
+ALTER TABLE reports ADD COLUMN developer_uid UUID;
+UPDATE reports r SET developer_uid = d.uid FROM developers d WHERE d.id = r.developer_id;
+ALTER TABLE reports DROP COLUMN developer_id;
+ALTER TABLE reports RENAME COLUMN developer_uid TO developer_id;
+ALTER TABLE reports ALTER COLUMN developer_id SET NOT NULL;
+ALTER TABLE developers DROP CONSTRAINT developers_pkey;
+ALTER TABLE developers DROP COLUMN id;
+ALTER TABLE developers RENAME COLUMN uid TO id;
+ALTER TABLE developers ALTER COLUMN id DROP DEFAULT;
+ALTER TABLE developers ADD PRIMARY KEY (id);
+ALTER TABLE reports ADD CONSTRAINT reports_developer_id_fkey
+    FOREIGN KEY (developer_id) REFERENCES developers(id) ON DELETE CASCADE;
+CREATE INDEX idx_reports_developer ON reports(developer_id);
+
+ALTER TABLE developers RENAME COLUMN firstname TO name;
+ALTER INDEX idx_developers_firstname RENAME TO idx_developers_name;
+
+ALTER TABLE projects ADD COLUMN deleted_at TIMESTAMPTZ;
+ALTER TABLE projects ALTER COLUMN description SET DEFAULT '';
+
+UPDATE tasks SET developer_note = '' WHERE developer_note IS NULL;
+ALTER TABLE tasks ALTER COLUMN developer_note SET DEFAULT '';
+ALTER TABLE tasks ALTER COLUMN developer_note SET NOT NULL;
+
+ALTER TABLE developers
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN modified_at TYPE TIMESTAMPTZ USING modified_at AT TIME ZONE 'UTC',
+    ALTER COLUMN deleted_at TYPE TIMESTAMPTZ USING deleted_at AT TIME ZONE 'UTC';
+ALTER TABLE projects
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC',
+    ALTER COLUMN modified_at TYPE TIMESTAMPTZ USING modified_at AT TIME ZONE 'UTC';
+ALTER TABLE reports
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC';
+ALTER TABLE tasks
+    ALTER COLUMN start_timestamp TYPE TIMESTAMPTZ USING start_timestamp AT TIME ZONE 'UTC',
+    ALTER COLUMN end_timestamp TYPE TIMESTAMPTZ USING end_timestamp AT TIME ZONE 'UTC',
+    ALTER COLUMN created_at TYPE TIMESTAMPTZ USING created_at AT TIME ZONE 'UTC';
+
+-- NOT VALID: ограничение действует для новых строк, не блокируя миграцию из-за старых данных
+ALTER TABLE tasks ADD CONSTRAINT tasks_timestamps_order CHECK (end_timestamp > start_timestamp) NOT VALID;
@@ -0,0 +1,10 @@
+CREATE TABLE api_tokens (
+    id SERIAL PRIMARY KEY,
+    developer_id UUID NOT NULL REFERENCES developers(id) ON DELETE CASCADE,
+    name TEXT NOT NULL DEFAULT '',
+    token_hash TEXT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ,
+    CONSTRAINT api_tokens_token_hash_key UNIQUE (token_hash)
+);
+CREATE INDEX idx_api_tokens_developer ON api_tokens(developer_id);
@@ -0,0 +1,4 @@
+ALTER TABLE reports DROP COLUMN approved_by, DROP COLUMN approved_at;
+DROP INDEX idx_projects_manager;
+ALTER TABLE projects DROP COLUMN manager_id;
+ALTER TABLE developers DROP COLUMN role;
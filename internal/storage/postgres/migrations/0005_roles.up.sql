@@ -0,0 +1,10 @@
+ALTER TABLE developers ADD COLUMN role TEXT NOT NULL DEFAULT 'developer'
+    CONSTRAINT developers_role_check CHECK (role IN ('developer', 'manager', 'admin'));
+
+ALTER TABLE projects ADD COLUMN manager_id UUID
+    CONSTRAINT projects_manager_id_fkey REFERENCES developers(id) ON DELETE SET NULL;
+CREATE INDEX idx_projects_manager ON projects(manager_id);
+
+ALTER TABLE reports
+    ADD COLUMN approved_at TIMESTAMPTZ,
+    ADD COLUMN approved_by UUID CONSTRAINT reports_approved_by_fkey REFERENCES developers(id) ON DELETE SET NULL;
@@ -0,0 +1,5 @@
+-- Версия задачи для условного обновления по If-Match
+ALTER TABLE tasks ADD COLUMN modified_at TIMESTAMPTZ;
+UPDATE tasks SET modified_at = created_at;
+ALTER TABLE tasks ALTER COLUMN modified_at SET NOT NULL;
+ALTER TABLE tasks ALTER COLUMN modified_at SET DEFAULT NOW();
@@ -0,0 +1,3 @@
+-- Номер ревизии задачи: растет с каждым изменением и выгружается в
+-- календарь как SEQUENCE
+ALTER TABLE tasks ADD COLUMN sequence INTEGER NOT NULL DEFAULT 0;
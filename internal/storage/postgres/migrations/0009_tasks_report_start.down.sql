@@ -0,0 +1,2 @@
+CREATE INDEX idx_tasks_report ON tasks(report_id);
+DROP INDEX idx_tasks_report_start;
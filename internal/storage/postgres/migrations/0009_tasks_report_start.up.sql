@@ -0,0 +1,5 @@
+-- Календарь разработчика ищет задачи по его отчетам и началу задачи;
+-- idx_tasks_start_end остается для выборок по периоду без разработчика
+-- (аналитика, выгрузка). Индекс по report_id покрывается новым.
+CREATE INDEX idx_tasks_report_start ON tasks(report_id, start_timestamp);
+DROP INDEX idx_tasks_report;
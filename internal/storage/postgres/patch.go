@@ -0,0 +1,41 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// updateQuery собирает UPDATE только по измененным колонкам. Плейсхолдер $1
+// зарезервирован под ID записи, как в setProjectState и setDeveloper.
+type updateQuery struct {
+	sets []string
+	args []interface{}
+}
+
+// set добавляет колонку, если поле патча задано (ok)
+func (q *updateQuery) set(column string, value interface{}, ok bool) {
+	if !ok {
+		return
+	}
+	q.args = append(q.args, value)
+	q.sets = append(q.sets, fmt.Sprintf("%s = $%d", column, len(q.args)+1))
+}
+
+// expr добавляет присваивание без аргументов, например счетчик ревизий
+func (q *updateQuery) expr(set string) {
+	q.sets = append(q.sets, set)
+}
+
+// build возвращает запрос и аргументы после ID. Запрос обновляет modified_at
+// и выполняется, только если версия записи совпадает с version; where -
+// дополнительное условие или пустая строка.
+func (q *updateQuery) build(table, where string, version time.Time) (string, []interface{}) {
+	args := append(q.args, version)
+	cond := fmt.Sprintf("id = $1 AND modified_at = $%d", len(args)+1)
+	if where != "" {
+		cond += " AND " + where
+	}
+	return fmt.Sprintf("UPDATE %s SET %s, modified_at = NOW() WHERE %s",
+		table, strings.Join(q.sets, ", "), cond), args
+}
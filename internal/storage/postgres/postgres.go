@@ -1,21 +1,25 @@
 package postgres
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"goproject/internal/metrics"
 	er "goproject/internal/storage"
 	"goproject/internal/storage/postgres/entity"
 	"time"
 
 	"github.com/google/uuid"
-	_ "github.com/lib/pq"
+	"github.com/lib/pq"
 )
 
 type Storage struct {
 	db *sql.DB
 }
 
+var _ er.Repository = (*Storage)(nil)
+
 func New(url string) (*Storage, error) {
 	const op = "storage.postgres.New"
 
@@ -29,14 +33,120 @@ func New(url string) (*Storage, error) {
 
 /////TASKS//////
 
-func (s *Storage) SaveTask(task entity.Task) (int, error) {
+// validateTask - общие правила проверки задачи перед записью
+func validateTask(task entity.Task) error {
+	if task.Name == "" || task.EstimatePlaned <= 0 {
+		return er.ErrInvalidTaskData
+	}
+	if !task.EndTimestamp.After(task.StartTimestamp) {
+		return er.ErrInvalidTaskData
+	}
+	return nil
+}
+
+// checkProjects проверяет, что проекты задач не удалены и не в архиве.
+// Строки проектов блокируются до конца транзакции, чтобы проект нельзя было
+// заархивировать параллельно с записью задач. Несуществующие проекты
+// отсекает внешний ключ.
+func checkProjects(tx *sql.Tx, tasks []entity.Task) error {
+	ids := make([]int64, 0, len(tasks))
+	for _, task := range tasks {
+		ids = append(ids, int64(task.ProjectID))
+	}
+
+	rows, err := tx.Query(`
+		SELECT id, archived_at IS NOT NULL, deleted_at IS NOT NULL
+		FROM projects
+		WHERE id = ANY($1)
+		FOR SHARE`, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("check projects: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var (
+			id                uint
+			archived, deleted bool
+		)
+		if err := rows.Scan(&id, &archived, &deleted); err != nil {
+			return fmt.Errorf("check projects: scan row: %w", err)
+		}
+		if deleted {
+			return fmt.Errorf("project %d: %w", id, er.ErrProjectNotFound)
+		}
+		if archived {
+			return fmt.Errorf("project %d: %w", id, er.ErrProjectArchived)
+		}
+	}
+
+	return rows.Err()
+}
+
+// SaveTask сохраняет задачу. Задачи в архивных и удаленных проектах не создаются.
+func (s *Storage) SaveTask(actor entity.Actor, task entity.Task) (int, error) {
 	const op = "storage.postgres.SaveTask"
+	defer metrics.ObserveQuery(op, time.Now())
 
-	if task.Name == "" || task.EstimatePlaned <= 0 {
-		return 0, fmt.Errorf("%s: %w", op, er.ErrInvalidTaskData)
+	if err := validateTask(task); err != nil {
+		return 0, fmt.Errorf("%s: %w", op, err)
+	}
+
+	tasks := []entity.Task{task}
+	err := s.inTx(op, func(tx *sql.Tx) error {
+		if err := checkProjects(tx, tasks); err != nil {
+			return err
+		}
+		if err := insertTasks(tx, tasks); err != nil {
+			return err
+		}
+		return writeTasksAudit(tx, actor, tasks)
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return int(tasks[0].ID), nil
+}
+
+// SaveTasks сохраняет пакет задач в одной транзакции. Если хотя бы одна
+// задача не проходит проверку или не записывается, пакет откатывается.
+func (s *Storage) SaveTasks(actor entity.Actor, tasks []entity.Task) ([]int, error) {
+	const op = "storage.postgres.SaveTasks"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	for i, task := range tasks {
+		if err := validateTask(task); err != nil {
+			return nil, fmt.Errorf("%s: task %d: %w", op, i, err)
+		}
+	}
+
+	saved := make([]entity.Task, len(tasks))
+	copy(saved, tasks)
+	err := s.inTx(op, func(tx *sql.Tx) error {
+		if err := checkProjects(tx, saved); err != nil {
+			return err
+		}
+		if err := insertTasks(tx, saved); err != nil {
+			return err
+		}
+		return writeTasksAudit(tx, actor, saved)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, len(saved))
+	for i, task := range saved {
+		ids[i] = int(task.ID)
 	}
 
-	stmt, err := s.db.Prepare(
+	return ids, nil
+}
+
+// insertTasks записывает задачи в рамках транзакции tx и заполняет их ID и CreatedAt
+func insertTasks(tx *sql.Tx, tasks []entity.Task) error {
+	stmt, err := tx.Prepare(
 		`INSERT INTO tasks(
             report_id,
             project_id,
@@ -47,37 +157,40 @@ func (s *Storage) SaveTask(task entity.Task) (int, error) {
             start_timestamp,
             end_timestamp
         ) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
-        RETURNING id, created_at`) // Changed to return both id and created_at
+        RETURNING id, created_at, modified_at`)
 	if err != nil {
-		return 0, fmt.Errorf("%s: prepare statement: %w", op, err)
+		return fmt.Errorf("prepare statement: %w", err)
 	}
 	defer stmt.Close()
 
-	var id int
-	err = stmt.QueryRow(
-		task.ReportID,
-		task.ProjectID,
-		task.Name,
-		task.DeveloperNote,
-		task.EstimatePlaned,
-		task.EstimateProgress,
-		task.StartTimestamp,
-		task.EndTimestamp,
-	).Scan(&id, &task.CreatedAt) // Scan both id and created_at
-	if err != nil {
-		return 0, fmt.Errorf("%s: execute statement: %w", op, err)
+	for i := range tasks {
+		task := &tasks[i]
+		err = stmt.QueryRow(
+			task.ReportID,
+			task.ProjectID,
+			task.Name,
+			task.DeveloperNote,
+			task.EstimatePlaned,
+			task.EstimateProgress,
+			task.StartTimestamp,
+			task.EndTimestamp,
+		).Scan(&task.ID, &task.CreatedAt, &task.ModifiedAt)
+		if err != nil {
+			return fmt.Errorf("execute statement for task %d: %w", i, mapError(err))
+		}
 	}
 
-	return id, nil
+	return nil
 }
 
 func (s *Storage) GetTaskByID(ID uint) (entity.Task, error) {
 	const op = "storage.postgres.GetTask"
+	defer metrics.ObserveQuery(op, time.Now())
 
 	stmt, err := s.db.Prepare(`
 		SELECT id, report_id, project_id, name, developer_note, 
 			   estimate_planed, estimate_progress, 
-			   start_timestamp, end_timestamp, created_at
+			   start_timestamp, end_timestamp, created_at, modified_at, sequence
 		FROM tasks 
 		WHERE id = $1`)
 	if err != nil {
@@ -97,31 +210,112 @@ func (s *Storage) GetTaskByID(ID uint) (entity.Task, error) {
 		&task.StartTimestamp,
 		&task.EndTimestamp,
 		&task.CreatedAt,
+		&task.ModifiedAt,
+		&task.Sequence,
 	)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Task{}, fmt.Errorf("%s: %w", op, er.ErrTaskNotFound)
+		}
 		return entity.Task{}, fmt.Errorf("%s: execute statement: %w", op, err)
 	}
 
 	return task, nil
 }
 
-func (s *Storage) GetTasks() ([]entity.Task, error) {
+// lockTask читает задачу в транзакции tx и блокирует строку до ее конца
+func lockTask(tx *sql.Tx, ID uint) (entity.Task, error) {
+	var task entity.Task
+	err := tx.QueryRow(`
+		SELECT id, report_id, project_id, name, developer_note,
+			   estimate_planed, estimate_progress,
+			   start_timestamp, end_timestamp, created_at, modified_at, sequence
+		FROM tasks
+		WHERE id = $1
+		FOR UPDATE`, ID).Scan(
+		&task.ID,
+		&task.ReportID,
+		&task.ProjectID,
+		&task.Name,
+		&task.DeveloperNote,
+		&task.EstimatePlaned,
+		&task.EstimateProgress,
+		&task.StartTimestamp,
+		&task.EndTimestamp,
+		&task.CreatedAt,
+		&task.ModifiedAt,
+		&task.Sequence,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Task{}, er.ErrTaskNotFound
+		}
+		return entity.Task{}, fmt.Errorf("lock task: %w", err)
+	}
+	return task, nil
+}
+
+var taskSorts = map[string]sortColumn{
+	"start_timestamp": {"t.start_timestamp", kindTime},
+	"created_at":      {"t.created_at", kindTime},
+	"id":              {"t.id", kindInt},
+}
+
+func taskSortValue(task entity.Task, field string) interface{} {
+	switch field {
+	case "start_timestamp":
+		return task.StartTimestamp
+	case "created_at":
+		return task.CreatedAt
+	}
+	return task.ID
+}
+
+// GetTasks возвращает страницу задач и курсор следующей страницы (пустой,
+// если страница последняя). Фильтры: разработчик, проект, интервал по
+// start_timestamp. Сортировка: start_timestamp, created_at, id.
+func (s *Storage) GetTasks(params er.ListParams) ([]entity.Task, string, error) {
 	const op = "storage.postgres.GetTasks"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	var q listQuery
+	if params.DeveloperID != uuid.Nil {
+		q.add("r.developer_id = " + q.arg(params.DeveloperID))
+	}
+	if params.ProjectID != 0 {
+		q.add("t.project_id = " + q.arg(params.ProjectID))
+	}
+	if params.ManagerID != uuid.Nil {
+		q.add("t.project_id IN (SELECT id FROM projects WHERE manager_id = " + q.arg(params.ManagerID) + ")")
+	}
+	if !params.From.IsZero() {
+		q.add("t.start_timestamp >= " + q.arg(params.From))
+	}
+	if !params.To.IsZero() {
+		q.add("t.start_timestamp < " + q.arg(params.To))
+	}
+
+	ks, err := q.page(params, taskSorts, "-start_timestamp", sortColumn{"t.id", kindInt})
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
 
 	stmt, err := s.db.Prepare(`
-        SELECT id, report_id, project_id, name, developer_note, 
-               estimate_planed, estimate_progress, 
-               start_timestamp, end_timestamp, created_at
-        FROM tasks
-    `)
+        SELECT t.id, t.report_id, t.project_id, t.name, t.developer_note, 
+               t.estimate_planed, t.estimate_progress, 
+               t.start_timestamp, t.end_timestamp, t.created_at, t.modified_at, t.sequence
+        FROM tasks t
+        JOIN reports r ON r.id = t.report_id
+        ` + q.whereClause() + `
+        ` + ks.tail())
 	if err != nil {
-		return nil, fmt.Errorf("%s: prepare statement: %w", op, err)
+		return nil, "", fmt.Errorf("%s: prepare statement: %w", op, err)
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query()
+	rows, err := stmt.Query(q.args...)
 	if err != nil {
-		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+		return nil, "", fmt.Errorf("%s: execute statement: %w", op, err)
 	}
 	defer rows.Close()
 
@@ -139,27 +333,37 @@ func (s *Storage) GetTasks() ([]entity.Task, error) {
 			&task.StartTimestamp,
 			&task.EndTimestamp,
 			&task.CreatedAt,
+			&task.ModifiedAt,
+			&task.Sequence,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+			return nil, "", fmt.Errorf("%s: scan row: %w", op, err)
 		}
 		tasks = append(tasks, task)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+		return nil, "", fmt.Errorf("%s: rows error: %w", op, err)
 	}
 
-	return tasks, nil
+	var next string
+	if len(tasks) > ks.limit {
+		tasks = tasks[:ks.limit]
+		last := tasks[len(tasks)-1]
+		next = ks.next(taskSortValue(last, ks.name), last.ID)
+	}
+
+	return tasks, next, nil
 }
 
 func (s *Storage) GetTasksByReportID(ID uint) ([]entity.Task, error) {
 	const op = "storage.postgres.GetTasksByReportID"
+	defer metrics.ObserveQuery(op, time.Now())
 
 	stmt, err := s.db.Prepare(`
 		SELECT id, report_id, project_id, name, developer_note, 
                estimate_planed, estimate_progress, 
-               start_timestamp, end_timestamp, created_at
+               start_timestamp, end_timestamp, created_at, modified_at, sequence
         FROM tasks
 		WHERE report_id = $1
 	`)
@@ -168,7 +372,7 @@ func (s *Storage) GetTasksByReportID(ID uint) ([]entity.Task, error) {
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query()
+	rows, err := stmt.Query(ID)
 	if err != nil {
 		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
 	}
@@ -188,6 +392,8 @@ func (s *Storage) GetTasksByReportID(ID uint) ([]entity.Task, error) {
 			&task.StartTimestamp,
 			&task.EndTimestamp,
 			&task.CreatedAt,
+			&task.ModifiedAt,
+			&task.Sequence,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: scan row: %w", op, err)
@@ -195,417 +401,1631 @@ func (s *Storage) GetTasksByReportID(ID uint) ([]entity.Task, error) {
 		tasks = append(tasks, task)
 	}
 
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
 	return tasks, nil
 }
 
-/////////DEVELOPERS/////////////
-
-func (s *Storage) SaveDeveloper(developer entity.Developer) (uuid.UUID, error) {
-	const op = "storage.postgres.SaveDeveloper"
-
-	if developer.Name == "" || developer.LastName == "" {
-		return uuid.Nil, fmt.Errorf("%s: %w", op, er.ErrInvalidDeveloperData)
-	}
-
-	uid := uuid.New()
+func (s *Storage) GetTasksByDeveloperID(developerID uuid.UUID) ([]entity.Task, error) {
+	const op = "storage.postgres.GetTasksByDeveloperID"
+	defer metrics.ObserveQuery(op, time.Now())
 
-	stmt, err := s.db.Prepare(
-		`INSERT INTO developers(
-			id,
-			name,
-			last_name,
-			created_at,			
-			modified_at,
-			deleted_at
-		) VALUES ($1, $2, $3, $4, $5, $6)`)
+	stmt, err := s.db.Prepare(`
+		SELECT t.id, t.report_id, t.project_id, t.name, t.developer_note,
+			   t.estimate_planed, t.estimate_progress,
+			   t.start_timestamp, t.end_timestamp, t.created_at, t.modified_at, t.sequence
+		FROM tasks t
+		JOIN reports r ON r.id = t.report_id
+		WHERE r.developer_id = $1
+		ORDER BY t.start_timestamp`)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("%s: prepare statement: %w", op, err)
+		return nil, fmt.Errorf("%s: prepare statement: %w", op, err)
 	}
 	defer stmt.Close()
 
-	err = stmt.QueryRow(
-		uid,
-		developer.Name,
-		developer.LastName,
-		time.Now(),
-	).Scan(&developer.CreatedAt)
+	rows, err := stmt.Query(developerID)
 	if err != nil {
-		return uuid.Nil, fmt.Errorf("%s: execute statement: %w", op, err)
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
 	}
+	defer rows.Close()
 
-	return uid, nil
-}
-
-func (s *Storage) GetDeveloperByID(uid uuid.UUID) (entity.Developer, error) {
-	const op = "storage.postgres.GetDeveloper"
-
-	stmt, err := s.db.Prepare(`
-		SELECT id, name, last_name, created_at
-		FROM developers
-		WHERE id = $1`)
-	if err != nil {
-		return entity.Developer{}, fmt.Errorf("%s: prepare statement: %w", op, err)
+	var tasks []entity.Task
+	for rows.Next() {
+		var task entity.Task
+		err := rows.Scan(
+			&task.ID,
+			&task.ReportID,
+			&task.ProjectID,
+			&task.Name,
+			&task.DeveloperNote,
+			&task.EstimatePlaned,
+			&task.EstimateProgress,
+			&task.StartTimestamp,
+			&task.EndTimestamp,
+			&task.CreatedAt,
+			&task.ModifiedAt,
+			&task.Sequence,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		tasks = append(tasks, task)
 	}
-	defer stmt.Close()
 
-	var developer entity.Developer
-	err = stmt.QueryRow(uid).Scan(
-		&developer.ID,
-		&developer.Name,
-		&developer.LastName,
-		&developer.CreatedAt,
-	)
-	if err != nil {
-		return entity.Developer{}, fmt.Errorf("%s: execute statement: %w", op, err)
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
 	}
 
-	return developer, nil
+	return tasks, nil
 }
 
-func (s *Storage) GetDevelopers() ([]entity.Developer, error) {
-	const op = "storage.postgres.GetDevelopers"
+// GetTasksByDeveloperInRange возвращает задачи разработчика, пересекающиеся с интервалом [from, to)
+func (s *Storage) GetTasksByDeveloperInRange(developerID uuid.UUID, from, to time.Time) ([]entity.Task, error) {
+	const op = "storage.postgres.GetTasksByDeveloperInRange"
+	defer metrics.ObserveQuery(op, time.Now())
 
 	stmt, err := s.db.Prepare(`
-		SELECT id, name, last_name, created_at
-		FROM developers`)
+		SELECT t.id, t.report_id, t.project_id, t.name, t.developer_note,
+			   t.estimate_planed, t.estimate_progress,
+			   t.start_timestamp, t.end_timestamp, t.created_at, t.modified_at, t.sequence
+		FROM tasks t
+		JOIN reports r ON r.id = t.report_id
+		WHERE r.developer_id = $1
+		  AND t.start_timestamp < $3
+		  AND t.end_timestamp > $2
+		ORDER BY t.start_timestamp`)
 	if err != nil {
 		return nil, fmt.Errorf("%s: prepare statement: %w", op, err)
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query()
+	rows, err := stmt.Query(developerID, from, to)
 	if err != nil {
 		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
 	}
 	defer rows.Close()
 
-	var developers []entity.Developer
+	var tasks []entity.Task
 	for rows.Next() {
-		var developer entity.Developer
+		var task entity.Task
 		err := rows.Scan(
-			&developer.ID,
-			&developer.Name,
-			&developer.LastName,
-			&developer.CreatedAt,
+			&task.ID,
+			&task.ReportID,
+			&task.ProjectID,
+			&task.Name,
+			&task.DeveloperNote,
+			&task.EstimatePlaned,
+			&task.EstimateProgress,
+			&task.StartTimestamp,
+			&task.EndTimestamp,
+			&task.CreatedAt,
+			&task.ModifiedAt,
+			&task.Sequence,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: scan row: %w", op, err)
 		}
-		developers = append(developers, developer)
+		tasks = append(tasks, task)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("%s: rows error: %w", op, err)
 	}
 
-	return developers, nil
+	return tasks, nil
 }
 
-func (s *Storage) UpdateDeveloper(uid uuid.UUID, developer entity.Developer) error {
-	const op = "storage.postgres.UpdateDeveloper"
+// UpdateTask обновляет задачу, если ее ModifiedAt совпадает с version, иначе
+// возвращает ErrVersionMismatch
+func (s *Storage) UpdateTask(actor entity.Actor, ID uint, task entity.Task, version time.Time) error {
+	const op = "storage.postgres.UpdateTask"
+	defer metrics.ObserveQuery(op, time.Now())
 
-	stmt, err := s.db.Prepare(
-		`UPDATE developers SET 
-		name = $1,
-		last_name = $2
-		WHERE id = $3`)
-	if err != nil {
-		return fmt.Errorf("%s: prepare statement: %w", op, err)
+	if err := validateTask(task); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
-	defer stmt.Close()
 
-	_, err = stmt.Exec(
-		developer.Name,
-		developer.LastName,
-		uid,
-	)
-	if err != nil {
-		return fmt.Errorf("%s: execute statement: %w", op, err)
-	}
+	return s.inTx(op, func(tx *sql.Tx) error {
+		before, err := lockTask(tx, ID)
+		if err != nil {
+			return err
+		}
+		// Перенести задачу, как и создать, можно только в действующий проект
+		if task.ProjectID != before.ProjectID {
+			if err := checkProjects(tx, []entity.Task{task}); err != nil {
+				return err
+			}
+		}
 
-	return nil
-}
+		res, err := tx.Exec(`
+			UPDATE tasks
+			SET report_id = $1,
+				project_id = $2,
+				name = $3,
+				developer_note = $4,
+				estimate_planed = $5,
+				estimate_progress = $6,
+				start_timestamp = $7,
+				end_timestamp = $8,
+				sequence = sequence + 1,
+				modified_at = NOW()
+			WHERE id = $9 AND modified_at = $10`,
+			task.ReportID,
+			task.ProjectID,
+			task.Name,
+			task.DeveloperNote,
+			task.EstimatePlaned,
+			task.EstimateProgress,
+			task.StartTimestamp,
+			task.EndTimestamp,
+			ID,
+			version,
+		)
+		if err != nil {
+			return fmt.Errorf("execute statement: %w", mapError(err))
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("rows affected: %w", err)
+		}
+		if affected == 0 {
+			return er.ErrVersionMismatch
+		}
 
-func (s *Storage) SoftDeleteDeveloper(uid uuid.UUID) error {
-	const op = "storage.postgres.SoftDeleteDeveloper"
+		after, err := lockTask(tx, ID)
+		if err != nil {
+			return err
+		}
 
-	currentTime := time.Now()
+		return writeAudit(tx, actor, entity.AuditTask, ID, entity.ActionUpdate, before, after)
+	})
+}
 
-	stmt, err := s.db.Prepare(`
-        UPDATE developers 
-        SET 
-            deleted_at = $1,
-            modified_at = $2 
-        WHERE id = $3
-        RETURNING modified_at`)
+// PatchTask изменяет только заданные в patch поля задачи, если ее ModifiedAt
+// совпадает с version. Пустой патч ничего не меняет.
+func (s *Storage) PatchTask(actor entity.Actor, ID uint, patch entity.TaskPatch, version time.Time) error {
+	const op = "storage.postgres.PatchTask"
+	defer metrics.ObserveQuery(op, time.Now())
 
-	if err != nil {
-		return fmt.Errorf("%s: prepare statement: %w", op, err)
+	if patch.Empty() {
+		return nil
 	}
-	defer stmt.Close()
 
-	var updatedAt time.Time
-	err = stmt.QueryRow("Удалён", currentTime, uid).Scan(&updatedAt)
+	return s.inTx(op, func(tx *sql.Tx) error {
+		before, err := lockTask(tx, ID)
+		if err != nil {
+			return err
+		}
+
+		// Поля проверяются в сочетании с неизмененными, например окончание
+		// задачи с прежним началом
+		merged := before
+		patch.Apply(&merged)
+		if err := validateTask(merged); err != nil {
+			return err
+		}
+		if merged.ProjectID != before.ProjectID {
+			if err := checkProjects(tx, []entity.Task{merged}); err != nil {
+				return err
+			}
+		}
 
-	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return fmt.Errorf("%s: developer not found: %w", op, err)
+		var q updateQuery
+		q.set("report_id", patch.ReportID.Value, patch.ReportID.Set)
+		q.set("project_id", patch.ProjectID.Value, patch.ProjectID.Set)
+		q.set("name", patch.Name.Value, patch.Name.Set)
+		q.set("developer_note", patch.DeveloperNote.Value, patch.DeveloperNote.Set)
+		q.set("estimate_planed", patch.EstimatePlaned.Value, patch.EstimatePlaned.Set)
+		q.set("estimate_progress", patch.EstimateProgress.Value, patch.EstimateProgress.Set)
+		q.set("start_timestamp", patch.StartTimestamp.Value, patch.StartTimestamp.Set)
+		q.set("end_timestamp", patch.EndTimestamp.Value, patch.EndTimestamp.Set)
+		q.expr("sequence = sequence + 1")
+		query, args := q.build("tasks", "", version)
+
+		res, err := tx.Exec(query, append([]interface{}{ID}, args...)...)
+		if err != nil {
+			return fmt.Errorf("execute statement: %w", mapError(err))
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("rows affected: %w", err)
+		}
+		if affected == 0 {
+			return er.ErrVersionMismatch
 		}
-		return fmt.Errorf("%s: execute statement: %w", op, err)
-	}
 
-	return nil
+		after, err := lockTask(tx, ID)
+		if err != nil {
+			return err
+		}
+
+		return writeAudit(tx, actor, entity.AuditTask, ID, entity.ActionUpdate, before, after)
+	})
 }
 
-/////////////////////////////////REPORTS//////////////////////////////
+func (s *Storage) DeleteTask(actor entity.Actor, ID uint) error {
+	const op = "storage.postgres.DeleteTask"
+	defer metrics.ObserveQuery(op, time.Now())
 
-func (s *Storage) SaveReport(report entity.Report) error {
-	const op = "storage.postgres.SaveReport"
+	return s.inTx(op, func(tx *sql.Tx) error {
+		before, err := lockTask(tx, ID)
+		if err != nil {
+			return err
+		}
 
-	stmt, err := s.db.Prepare(
-		`INSERT INTO reports(
-    		developer_id,
-    	c	reated_at
-    	) VALUES ($1, $2)
-    	RETURNING id, created_at`)
-	if err != nil {
-		return fmt.Errorf("%s: prepare statement: %w", op, err)
-	}
-	defer stmt.Close()
+		if _, err := tx.Exec(`DELETE FROM tasks WHERE id = $1`, ID); err != nil {
+			return fmt.Errorf("execute statement: %w", mapError(err))
+		}
 
-	err = stmt.QueryRow(
-		report.DeveloperID,
-		time.Now(),
-	).Scan(&report.ID, &report.CreatedAt)
-	if err != nil {
-		return fmt.Errorf("%s: execute statement: %w", op, err)
-	}
-	return nil
+		return writeAudit(tx, actor, entity.AuditTask, ID, entity.ActionDelete, before, nil)
+	})
 }
 
-func (s *Storage) GetReport() ([]entity.Report, error) {
-	const op = "storage.postgres.GetReport"
+/////////DEVELOPERS/////////////
 
-	stmt, err := s.db.Prepare(`
-    SELECT id, developer_id, created_at
-    FROM reports
-    ORDER BY created_at DESC`)
-	if err != nil {
-		return nil, fmt.Errorf("%s: prepare statement: %w", op, err)
-	}
-	defer stmt.Close()
+func (s *Storage) SaveDeveloper(actor entity.Actor, developer entity.Developer) (uuid.UUID, error) {
+	const op = "storage.postgres.SaveDeveloper"
+	defer metrics.ObserveQuery(op, time.Now())
 
-	rows, err := stmt.Query()
-	if err != nil {
-		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	if developer.Name == "" || developer.LastName == "" {
+		return uuid.Nil, fmt.Errorf("%s: %w", op, er.ErrInvalidDeveloperData)
+	}
+	if developer.Role != "" && !entity.ValidRole(developer.Role) {
+		return uuid.Nil, fmt.Errorf("%s: %w", op, er.ErrInvalidDeveloperData)
+	}
+	if developer.Role == "" {
+		developer.Role = entity.RoleDeveloper
 	}
-	defer rows.Close()
+
+	uid := uuid.New()
+
+	err := s.inTx(op, func(tx *sql.Tx) error {
+		_, err := tx.Exec(
+			`INSERT INTO developers(
+				id,
+				name,
+				last_name,
+				role,
+				created_at,
+				modified_at
+			) VALUES ($1, $2, $3, $4, $5, $5)`,
+			uid,
+			developer.Name,
+			developer.LastName,
+			developer.Role,
+			time.Now(),
+		)
+		if err != nil {
+			return fmt.Errorf("execute statement: %w", mapError(err))
+		}
+
+		after, err := lockDeveloper(tx, uid)
+		if err != nil {
+			return err
+		}
+
+		return writeAudit(tx, actor, entity.AuditDeveloper, uid, entity.ActionCreate, nil, after)
+	})
+	if err != nil {
+		return uuid.Nil, err
+	}
+
+	return uid, nil
+}
+
+func (s *Storage) GetDeveloperByID(uid uuid.UUID) (entity.Developer, error) {
+	const op = "storage.postgres.GetDeveloper"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	stmt, err := s.db.Prepare(`
+		SELECT id, name, last_name, role, created_at, modified_at, deleted_at
+		FROM developers
+		WHERE id = $1`)
+	if err != nil {
+		return entity.Developer{}, fmt.Errorf("%s: prepare statement: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var developer entity.Developer
+	err = stmt.QueryRow(uid).Scan(
+		&developer.ID,
+		&developer.Name,
+		&developer.LastName,
+		&developer.Role,
+		&developer.CreatedAt,
+		&developer.ModifiedAt,
+		&developer.DeletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Developer{}, fmt.Errorf("%s: %w", op, er.ErrDeveloperNotFound)
+		}
+		return entity.Developer{}, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return developer, nil
+}
+
+// lockDeveloper читает разработчика, в том числе удаленного, в транзакции tx
+// и блокирует строку до ее конца
+func lockDeveloper(tx *sql.Tx, uid uuid.UUID) (entity.Developer, error) {
+	var developer entity.Developer
+	err := tx.QueryRow(`
+		SELECT id, name, last_name, role, created_at, modified_at, deleted_at
+		FROM developers
+		WHERE id = $1
+		FOR UPDATE`, uid).Scan(
+		&developer.ID,
+		&developer.Name,
+		&developer.LastName,
+		&developer.Role,
+		&developer.CreatedAt,
+		&developer.ModifiedAt,
+		&developer.DeletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Developer{}, er.ErrDeveloperNotFound
+		}
+		return entity.Developer{}, fmt.Errorf("lock developer: %w", err)
+	}
+	return developer, nil
+}
+
+var developerSorts = map[string]sortColumn{
+	"created_at": {"d.created_at", kindTime},
+	"name":       {"d.name", kindText},
+	"last_name":  {"d.last_name", kindText},
+}
+
+func developerSortValue(developer entity.Developer, field string) interface{} {
+	switch field {
+	case "name":
+		return developer.Name
+	case "last_name":
+		return developer.LastName
+	}
+	return developer.CreatedAt
+}
+
+// GetDevelopers возвращает страницу разработчиков и курсор следующей страницы.
+// Удаленные разработчики возвращаются только с IncludeDeleted. Фильтры:
+// проект (разработчики с задачами в проекте), интервал по created_at.
+// Сортировка: created_at, name, last_name.
+func (s *Storage) GetDevelopers(params er.ListParams) ([]entity.Developer, string, error) {
+	const op = "storage.postgres.GetDevelopers"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	var q listQuery
+	if !params.IncludeDeleted {
+		q.add("d.deleted_at IS NULL")
+	}
+	if params.ManagerID != uuid.Nil {
+		q.add(`EXISTS (
+			SELECT 1 FROM tasks t
+			JOIN reports r ON r.id = t.report_id
+			JOIN projects p ON p.id = t.project_id
+			WHERE r.developer_id = d.id AND p.manager_id = ` + q.arg(params.ManagerID) + `)`)
+	}
+	if params.ProjectID != 0 {
+		q.add(`EXISTS (
+			SELECT 1 FROM tasks t
+			JOIN reports r ON r.id = t.report_id
+			WHERE r.developer_id = d.id AND t.project_id = ` + q.arg(params.ProjectID) + `)`)
+	}
+	if !params.From.IsZero() {
+		q.add("d.created_at >= " + q.arg(params.From))
+	}
+	if !params.To.IsZero() {
+		q.add("d.created_at < " + q.arg(params.To))
+	}
+
+	ks, err := q.page(params, developerSorts, "created_at", sortColumn{"d.id", kindUUID})
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	stmt, err := s.db.Prepare(`
+		SELECT d.id, d.name, d.last_name, d.role, d.created_at, d.modified_at, d.deleted_at
+		FROM developers d
+		` + q.whereClause() + `
+		` + ks.tail())
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: prepare statement: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(q.args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer rows.Close()
+
+	var developers []entity.Developer
+	for rows.Next() {
+		var developer entity.Developer
+		err := rows.Scan(
+			&developer.ID,
+			&developer.Name,
+			&developer.LastName,
+			&developer.Role,
+			&developer.CreatedAt,
+			&developer.ModifiedAt,
+			&developer.DeletedAt,
+		)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		developers = append(developers, developer)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	var next string
+	if len(developers) > ks.limit {
+		developers = developers[:ks.limit]
+		last := developers[len(developers)-1]
+		next = ks.next(developerSortValue(last, ks.name), last.ID)
+	}
+
+	return developers, next, nil
+}
+
+// UpdateDeveloper обновляет имя, фамилию и роль; пустая роль не меняется.
+// Обновление выполняется, только если ModifiedAt совпадает с version.
+func (s *Storage) UpdateDeveloper(actor entity.Actor, uid uuid.UUID, developer entity.Developer, version time.Time) error {
+	const op = "storage.postgres.UpdateDeveloper"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	if developer.Name == "" || developer.LastName == "" {
+		return fmt.Errorf("%s: %w", op, er.ErrInvalidDeveloperData)
+	}
+	if developer.Role != "" && !entity.ValidRole(developer.Role) {
+		return fmt.Errorf("%s: %w", op, er.ErrInvalidDeveloperData)
+	}
+
+	return s.setDeveloper(op, actor, uid, entity.ActionUpdate,
+		`UPDATE developers SET 
+		name = $2,
+		last_name = $3,
+		role = COALESCE(NULLIF($4, ''), role),
+		modified_at = NOW()
+		WHERE id = $1 AND deleted_at IS NULL AND modified_at = $5`,
+		developer.Name,
+		developer.LastName,
+		developer.Role,
+		version,
+	)
+}
+
+// PatchDeveloper изменяет только заданные в patch поля разработчика, если его
+// ModifiedAt совпадает с version. Пустой патч ничего не меняет.
+func (s *Storage) PatchDeveloper(actor entity.Actor, uid uuid.UUID, patch entity.DeveloperPatch, version time.Time) error {
+	const op = "storage.postgres.PatchDeveloper"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	if (patch.Name.Set && patch.Name.Value == "") || (patch.LastName.Set && patch.LastName.Value == "") {
+		return fmt.Errorf("%s: %w", op, er.ErrInvalidDeveloperData)
+	}
+	if patch.Role.Set && !entity.ValidRole(patch.Role.Value) {
+		return fmt.Errorf("%s: %w", op, er.ErrInvalidDeveloperData)
+	}
+	if patch.Empty() {
+		return nil
+	}
+
+	var q updateQuery
+	q.set("name", patch.Name.Value, patch.Name.Set)
+	q.set("last_name", patch.LastName.Value, patch.LastName.Set)
+	q.set("role", patch.Role.Value, patch.Role.Set)
+	query, args := q.build("developers", "deleted_at IS NULL", version)
+
+	return s.setDeveloper(op, actor, uid, entity.ActionUpdate, query, args...)
+}
+
+// SoftDeleteDeveloper помечает разработчика удаленным. Повторное удаление
+// ничего не меняет и не пишется в журнал аудита.
+func (s *Storage) SoftDeleteDeveloper(actor entity.Actor, uid uuid.UUID) error {
+	const op = "storage.postgres.SoftDeleteDeveloper"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	return s.setDeveloper(op, actor, uid, entity.ActionDelete, `
+        UPDATE developers 
+        SET 
+            deleted_at = NOW(),
+            modified_at = NOW()
+        WHERE id = $1 AND deleted_at IS NULL`)
+}
+
+// RestoreDeveloper снимает пометку об удалении; для неудаленного
+// разработчика ничего не делает
+func (s *Storage) RestoreDeveloper(actor entity.Actor, uid uuid.UUID) error {
+	const op = "storage.postgres.RestoreDeveloper"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	return s.setDeveloper(op, actor, uid, entity.ActionRestore, `
+        UPDATE developers 
+        SET 
+            deleted_at = NULL,
+            modified_at = NOW()
+        WHERE id = $1 AND deleted_at IS NOT NULL`)
+}
+
+// setDeveloper выполняет запрос изменения разработчика ($1 - ID) и пишет
+// изменение в журнал аудита. Если ни одна строка не изменилась, разработчик
+// считается ненайденным, а при обновлении существующего - измененным после
+// переданной версии. Удаление удаленного и восстановление неудаленного
+// разработчика - пустые операции без записи в журнал.
+func (s *Storage) setDeveloper(op string, actor entity.Actor, uid uuid.UUID, action, query string, args ...interface{}) error {
+	return s.inTx(op, func(tx *sql.Tx) error {
+		before, err := lockDeveloper(tx, uid)
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.Exec(query, append([]interface{}{uid}, args...)...)
+		if err != nil {
+			return fmt.Errorf("execute statement: %w", mapError(err))
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("rows affected: %w", err)
+		}
+		if affected == 0 {
+			switch {
+			case action == entity.ActionDelete && before.DeletedAt != nil,
+				action == entity.ActionRestore && before.DeletedAt == nil:
+				return nil
+			case action == entity.ActionUpdate && before.DeletedAt == nil:
+				return er.ErrVersionMismatch
+			}
+			return er.ErrDeveloperNotFound
+		}
+
+		after, err := lockDeveloper(tx, uid)
+		if err != nil {
+			return err
+		}
+
+		return writeAudit(tx, actor, entity.AuditDeveloper, uid, action, before, after)
+	})
+}
+
+/////////////////////////////////REPORTS//////////////////////////////
+
+func (s *Storage) SaveReport(actor entity.Actor, report entity.Report) (entity.Report, error) {
+	const op = "storage.postgres.SaveReport"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	err := s.inTx(op, func(tx *sql.Tx) error {
+		return insertReport(tx, actor, &report)
+	})
+	if err != nil {
+		return entity.Report{}, err
+	}
+	return report, nil
+}
+
+// insertReport записывает отчет и его создание в журнал в транзакции tx;
+// заполняет ID и CreatedAt
+func insertReport(tx *sql.Tx, actor entity.Actor, report *entity.Report) error {
+	err := tx.QueryRow(
+		`INSERT INTO reports(
+    		developer_id,
+    		created_at
+    	) VALUES ($1, $2)
+    	RETURNING id, created_at`,
+		report.DeveloperID,
+		time.Now(),
+	).Scan(&report.ID, &report.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("insert report: %w", mapError(err))
+	}
+
+	return writeAudit(tx, actor, entity.AuditReport, report.ID, entity.ActionCreate, nil, *report)
+}
+
+// SaveReportWithTasks сохраняет отчет вместе с задачами в одной транзакции.
+// При ошибке любой задачи отчет не создается.
+func (s *Storage) SaveReportWithTasks(actor entity.Actor, report entity.Report, tasks []entity.Task) (entity.Report, []entity.Task, error) {
+	const op = "storage.postgres.SaveReportWithTasks"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	if report.DeveloperID == uuid.Nil {
+		return entity.Report{}, nil, fmt.Errorf("%s: %w", op, er.ErrInvalidReportData)
+	}
+	for i, task := range tasks {
+		if err := validateTask(task); err != nil {
+			return entity.Report{}, nil, fmt.Errorf("%s: task %d: %w", op, i, err)
+		}
+	}
+
+	saved := make([]entity.Task, len(tasks))
+	copy(saved, tasks)
+	err := s.inTx(op, func(tx *sql.Tx) error {
+		if err := insertReport(tx, actor, &report); err != nil {
+			return err
+		}
+
+		for i := range saved {
+			saved[i].ReportID = report.ID
+		}
+
+		if err := checkProjects(tx, saved); err != nil {
+			return err
+		}
+		if err := insertTasks(tx, saved); err != nil {
+			return err
+		}
+		return writeTasksAudit(tx, actor, saved)
+	})
+	if err != nil {
+		return entity.Report{}, nil, err
+	}
+
+	return report, saved, nil
+}
+
+// lockReport читает отчет в транзакции tx и блокирует строку до ее конца
+func lockReport(tx *sql.Tx, ID uint) (entity.Report, error) {
+	var report entity.Report
+	err := tx.QueryRow(`
+		SELECT id, developer_id, created_at, approved_at, approved_by
+		FROM reports
+		WHERE id = $1
+		FOR UPDATE`, ID).Scan(
+		&report.ID,
+		&report.DeveloperID,
+		&report.CreatedAt,
+		&report.ApprovedAt,
+		&report.ApprovedBy,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Report{}, er.ErrReportNotFound
+		}
+		return entity.Report{}, fmt.Errorf("lock report: %w", err)
+	}
+	return report, nil
+}
+
+var reportSorts = map[string]sortColumn{
+	"created_at": {"r.created_at", kindTime},
+	"id":         {"r.id", kindInt},
+}
+
+func reportSortValue(report entity.Report, field string) interface{} {
+	if field == "created_at" {
+		return report.CreatedAt
+	}
+	return report.ID
+}
+
+// GetReport возвращает страницу отчетов и курсор следующей страницы.
+// Фильтры: разработчик, проект (отчеты с задачами в проекте), интервал по
+// created_at. Сортировка: created_at (по умолчанию по убыванию), id.
+func (s *Storage) GetReport(params er.ListParams) ([]entity.Report, string, error) {
+	const op = "storage.postgres.GetReport"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	var q listQuery
+	if params.DeveloperID != uuid.Nil {
+		q.add("r.developer_id = " + q.arg(params.DeveloperID))
+	}
+	if params.ManagerID != uuid.Nil {
+		q.add(`EXISTS (
+			SELECT 1 FROM tasks t
+			JOIN projects p ON p.id = t.project_id
+			WHERE t.report_id = r.id AND p.manager_id = ` + q.arg(params.ManagerID) + `)`)
+	}
+	if params.ProjectID != 0 {
+		q.add(`EXISTS (
+			SELECT 1 FROM tasks t
+			WHERE t.report_id = r.id AND t.project_id = ` + q.arg(params.ProjectID) + `)`)
+	}
+	if !params.From.IsZero() {
+		q.add("r.created_at >= " + q.arg(params.From))
+	}
+	if !params.To.IsZero() {
+		q.add("r.created_at < " + q.arg(params.To))
+	}
+
+	ks, err := q.page(params, reportSorts, "-created_at", sortColumn{"r.id", kindInt})
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	stmt, err := s.db.Prepare(`
+    SELECT r.id, r.developer_id, r.created_at, r.approved_at, r.approved_by
+    FROM reports r
+    ` + q.whereClause() + `
+    ` + ks.tail())
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: prepare statement: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(q.args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer rows.Close()
+
+	var reports []entity.Report
+	for rows.Next() {
+		var report entity.Report
+		err := rows.Scan(
+			&report.ID,
+			&report.DeveloperID,
+			&report.CreatedAt,
+			&report.ApprovedAt,
+			&report.ApprovedBy,
+		)
+		if err != nil {
+			return nil, "", fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		reports = append(reports, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	var next string
+	if len(reports) > ks.limit {
+		reports = reports[:ks.limit]
+		last := reports[len(reports)-1]
+		next = ks.next(reportSortValue(last, ks.name), last.ID)
+	}
+
+	return reports, next, nil
+}
+
+func (s *Storage) GetReportById(id uint) (entity.Report, error) {
+	const op = "storage.postgres.GetReportById"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	stmt, err := s.db.Prepare(`
+    SELECT id, developer_id, created_at, approved_at, approved_by
+    FROM reports  
+    WHERE id = $1`)
+	if err != nil {
+		return entity.Report{}, fmt.Errorf("%s: prepare statement: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var report entity.Report
+	err = stmt.QueryRow(id).Scan(
+		&report.ID,
+		&report.DeveloperID,
+		&report.CreatedAt,
+		&report.ApprovedAt,
+		&report.ApprovedBy,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Report{}, fmt.Errorf("%s: %w", op, er.ErrReportNotFound)
+		}
+		return entity.Report{}, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return report, nil
+}
+
+func (s *Storage) GetReportsByDeveloperID(developerID uuid.UUID) ([]entity.Report, error) {
+	const op = "storage.postgres.GetReportsByDeveloperID"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	stmt, err := s.db.Prepare(`
+        SELECT id, developer_id, created_at, approved_at, approved_by
+        FROM reports
+        WHERE developer_id = $1
+        ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("%s: prepare statement: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(developerID)
+	if err != nil {
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer rows.Close()
 
 	var reports []entity.Report
 	for rows.Next() {
-		var report entity.Report
+		var report entity.Report
+		err := rows.Scan(
+			&report.ID,
+			&report.DeveloperID,
+			&report.CreatedAt,
+			&report.ApprovedAt,
+			&report.ApprovedBy,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		reports = append(reports, report)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return reports, nil
+}
+
+// ApproveReport отмечает отчет утвержденным от имени actor. Повторное
+// утверждение сохраняет первого утвердившего.
+func (s *Storage) ApproveReport(actor entity.Actor, ID uint) error {
+	const op = "storage.postgres.ApproveReport"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	return s.inTx(op, func(tx *sql.Tx) error {
+		before, err := lockReport(tx, ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE reports
+			SET approved_at = COALESCE(approved_at, NOW()),
+				approved_by = CASE WHEN approved_at IS NULL THEN $2::uuid ELSE approved_by END
+			WHERE id = $1`, ID, er.ActorID(actor))
+		if err != nil {
+			return fmt.Errorf("execute statement: %w", mapError(err))
+		}
+
+		after, err := lockReport(tx, ID)
+		if err != nil {
+			return err
+		}
+
+		return writeAudit(tx, actor, entity.AuditReport, ID, entity.ActionApprove, before, after)
+	})
+}
+
+/////////////////////////////////PROJECTS//////////////////////////////
+
+func (s *Storage) SaveProject(actor entity.Actor, project entity.Project) (entity.Project, error) {
+	const op = "storage.postgres.SaveProject"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	if project.Name == "" {
+		return entity.Project{}, fmt.Errorf("%s: %w", op, er.ErrInvalidProjectData)
+	}
+
+	err := s.inTx(op, func(tx *sql.Tx) error {
+		err := tx.QueryRow(`
+			INSERT INTO projects(
+			  name,
+			  description,
+			  manager_id,
+			  created_at,
+			  modified_at
+			) VALUES ($1, $2, $3, $4, $4)
+			RETURNING id, created_at, modified_at`,
+			project.Name,
+			project.Description,
+			project.ManagerID,
+			time.Now(),
+		).Scan(&project.ID, &project.CreatedAt, &project.ModifiedAt)
+		if err != nil {
+			return fmt.Errorf("execute statement: %w", mapError(err))
+		}
+
+		return writeAudit(tx, actor, entity.AuditProject, project.ID, entity.ActionCreate, nil, project)
+	})
+	if err != nil {
+		return entity.Project{}, err
+	}
+	return project, nil
+}
+
+// projectColumns - колонки проекта в порядке полей entity.Project
+const projectColumns = "p.id, p.name, p.description, p.created_at, p.modified_at, p.manager_id, p.archived_at, p.deleted_at"
+
+// lockProject читает проект, в том числе удаленный, в транзакции tx и
+// блокирует строку до ее конца
+func lockProject(tx *sql.Tx, ID uint) (entity.Project, error) {
+	var project entity.Project
+	err := tx.QueryRow(`
+		SELECT `+projectColumns+`
+		FROM projects p
+		WHERE p.id = $1
+		FOR UPDATE`, ID).Scan(
+		&project.ID,
+		&project.Name,
+		&project.Description,
+		&project.CreatedAt,
+		&project.ModifiedAt,
+		&project.ManagerID,
+		&project.ArchivedAt,
+		&project.DeletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Project{}, er.ErrProjectNotFound
+		}
+		return entity.Project{}, fmt.Errorf("lock project: %w", err)
+	}
+	return project, nil
+}
+
+var projectSorts = map[string]sortColumn{
+	"created_at": {"p.created_at", kindTime},
+	"name":       {"p.name", kindText},
+	"id":         {"p.id", kindInt},
+}
+
+func projectSortValue(project entity.Project, field string) interface{} {
+	switch field {
+	case "created_at":
+		return project.CreatedAt
+	case "name":
+		return project.Name
+	}
+	return project.ID
+}
+
+// GetProject возвращает страницу проектов и курсор следующей страницы.
+// Архивные проекты входят в список, удаленные - только с IncludeDeleted.
+// Фильтры: разработчик (проекты с его задачами), интервал по created_at.
+// Сортировка: created_at (по умолчанию по убыванию), name, id.
+func (s *Storage) GetProject(params er.ListParams) ([]entity.Project, string, error) {
+	const op = "storage.postgres.GetProject"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	var q listQuery
+	if !params.IncludeDeleted {
+		q.add("p.deleted_at IS NULL")
+	}
+	if params.DeveloperID != uuid.Nil {
+		q.add(`EXISTS (
+			SELECT 1 FROM tasks t
+			JOIN reports r ON r.id = t.report_id
+			WHERE t.project_id = p.id AND r.developer_id = ` + q.arg(params.DeveloperID) + `)`)
+	}
+	if !params.From.IsZero() {
+		q.add("p.created_at >= " + q.arg(params.From))
+	}
+	if !params.To.IsZero() {
+		q.add("p.created_at < " + q.arg(params.To))
+	}
+
+	ks, err := q.page(params, projectSorts, "-created_at", sortColumn{"p.id", kindInt})
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", op, err)
+	}
+
+	stmt, err := s.db.Prepare(`
+    SELECT ` + projectColumns + `
+    FROM projects p
+    ` + q.whereClause() + `
+    ` + ks.tail())
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: prepare statement: %w", op, err)
+	}
+	defer stmt.Close()
+
+	rows, err := stmt.Query(q.args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer rows.Close()
+
+	var projects []entity.Project
+	for rows.Next() {
+		var project entity.Project
 		err := rows.Scan(
-			&report.ID,
-			&report.DeveloperID,
-			&report.CreatedAt,
+			&project.ID,
+			&project.Name,
+			&project.Description,
+			&project.CreatedAt,
+			&project.ModifiedAt,
+			&project.ManagerID,
+			&project.ArchivedAt,
+			&project.DeletedAt,
 		)
 		if err != nil {
-			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+			return nil, "", fmt.Errorf("%s: scan row: %w", op, err)
 		}
-		reports = append(reports, report)
+		projects = append(projects, project)
 	}
 
 	if err := rows.Err(); err != nil {
-		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+		return nil, "", fmt.Errorf("%s: rows error: %w", op, err)
 	}
 
-	return reports, nil
+	var next string
+	if len(projects) > ks.limit {
+		projects = projects[:ks.limit]
+		last := projects[len(projects)-1]
+		next = ks.next(projectSortValue(last, ks.name), last.ID)
+	}
+
+	return projects, next, nil
 }
 
-func (s *Storage) GetReportById(id uint) (entity.Report, error) {
-	const op = "storage.postgres.GetReportById"
+// GetProjectByName ищет неудаленный проект по названию без учета регистра
+func (s *Storage) GetProjectByName(name string) (entity.Project, error) {
+	const op = "storage.postgres.GetProjectByName"
+	defer metrics.ObserveQuery(op, time.Now())
 
 	stmt, err := s.db.Prepare(`
-    SELECT id, developer_id, created_at
-    FROM reports  
-    WHERE id = $1`)
+    SELECT ` + projectColumns + `
+    FROM projects p
+    WHERE lower(p.name) = lower($1) AND p.deleted_at IS NULL
+    ORDER BY p.id
+    LIMIT 1`)
 	if err != nil {
-		return entity.Report{}, fmt.Errorf("%s: prepare statement: %w", op, err)
+		return entity.Project{}, fmt.Errorf("%s: prepare statement: %w", op, err)
 	}
 	defer stmt.Close()
 
-	var report entity.Report
-	err = stmt.QueryRow(id).Scan(
-		&report.ID,
-		&report.DeveloperID,
-		&report.CreatedAt,
+	var project entity.Project
+	err = stmt.QueryRow(name).Scan(
+		&project.ID,
+		&project.Name,
+		&project.Description,
+		&project.CreatedAt,
+		&project.ModifiedAt,
+		&project.ManagerID,
+		&project.ArchivedAt,
+		&project.DeletedAt,
 	)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return entity.Report{}, fmt.Errorf("%s: %w", op, er.ErrReportNotFound)
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Project{}, fmt.Errorf("%s: %w", op, er.ErrProjectNotFound)
 		}
-		return entity.Report{}, fmt.Errorf("%s: execute statement: %w", op, err)
+		return entity.Project{}, fmt.Errorf("%s: execute statement: %w", op, err)
 	}
 
-	return report, nil
+	return project, nil
 }
 
-func (s *Storage) GetReportsByDeveloperID(developerID uuid.UUID) ([]entity.Report, error) {
-	const op = "storage.postgres.GetReportsByDeveloperID"
+// GetProjectByID возвращает проект по ID; удаленный проект не находится
+func (s *Storage) GetProjectByID(ID uint) (entity.Project, error) {
+	const op = "storage.postgres.GetProjectByID"
+	defer metrics.ObserveQuery(op, time.Now())
 
 	stmt, err := s.db.Prepare(`
-        SELECT id, developer_id, created_at
-        FROM reports
-        WHERE developer_id = $1
-        ORDER BY created_at DESC`)
+    SELECT ` + projectColumns + `
+    FROM projects p
+    WHERE p.id = $1 AND p.deleted_at IS NULL`)
 	if err != nil {
-		return nil, fmt.Errorf("%s: prepare statement: %w", op, err)
+		return entity.Project{}, fmt.Errorf("%s: prepare statement: %w", op, err)
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query(developerID)
+	var project entity.Project
+	err = stmt.QueryRow(ID).Scan(
+		&project.ID,
+		&project.Name,
+		&project.Description,
+		&project.CreatedAt,
+		&project.ModifiedAt,
+		&project.ManagerID,
+		&project.ArchivedAt,
+		&project.DeletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Project{}, fmt.Errorf("%s: %w", op, er.ErrProjectNotFound)
+		}
+		return entity.Project{}, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return project, nil
+}
+
+// GetProjectsByIDs возвращает проекты с заданными ID одним запросом;
+// удаленные и несуществующие проекты пропускаются
+func (s *Storage) GetProjectsByIDs(IDs []uint) ([]entity.Project, error) {
+	const op = "storage.postgres.GetProjectsByIDs"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	ids := make([]int64, len(IDs))
+	for i, id := range IDs {
+		ids[i] = int64(id)
+	}
+
+	rows, err := s.db.Query(`
+    SELECT `+projectColumns+`
+    FROM projects p
+    WHERE p.id = ANY($1) AND p.deleted_at IS NULL
+    ORDER BY p.id`, pq.Array(ids))
 	if err != nil {
 		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
 	}
 	defer rows.Close()
 
-	var reports []entity.Report
+	var projects []entity.Project
 	for rows.Next() {
-		var report entity.Report
+		var project entity.Project
 		err := rows.Scan(
-			&report.ID,
-			&report.DeveloperID,
-			&report.CreatedAt,
+			&project.ID,
+			&project.Name,
+			&project.Description,
+			&project.CreatedAt,
+			&project.ModifiedAt,
+			&project.ManagerID,
+			&project.ArchivedAt,
+			&project.DeletedAt,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: scan row: %w", op, err)
 		}
-		reports = append(reports, report)
+		projects = append(projects, project)
 	}
-
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("%s: rows error: %w", op, err)
 	}
 
-	return reports, nil
+	return projects, nil
 }
 
-/////////////////////////////////PROJECTS//////////////////////////////
+// UpdateProject обновляет проект, если его ModifiedAt совпадает с version,
+// иначе возвращает ErrVersionMismatch
+func (s *Storage) UpdateProject(actor entity.Actor, ID uint, project entity.Project, version time.Time) error {
+	const op = "storage.postgres.UpdateProject"
+	defer metrics.ObserveQuery(op, time.Now())
 
-func (s *Storage) SaveProject(project entity.Project) error {
-	const op = "storage.postgres.SaveProject"
+	if project.Name == "" {
+		return fmt.Errorf("%s: %w", op, er.ErrInvalidProjectData)
+	}
+
+	return s.setProjectState(op, actor, ID, entity.ActionUpdate, `
+        UPDATE projects 
+        SET name = $2, description = $3, manager_id = $4, modified_at = NOW()
+        WHERE id = $1 AND deleted_at IS NULL AND modified_at = $5`,
+		project.Name, project.Description, project.ManagerID, version)
+}
+
+// PatchProject изменяет только заданные в patch поля проекта, если его
+// ModifiedAt совпадает с version. Пустой патч ничего не меняет.
+func (s *Storage) PatchProject(actor entity.Actor, ID uint, patch entity.ProjectPatch, version time.Time) error {
+	const op = "storage.postgres.PatchProject"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	if patch.Name.Set && patch.Name.Value == "" {
+		return fmt.Errorf("%s: %w", op, er.ErrInvalidProjectData)
+	}
+	if patch.Empty() {
+		return nil
+	}
+
+	var q updateQuery
+	q.set("name", patch.Name.Value, patch.Name.Set)
+	q.set("description", patch.Description.Value, patch.Description.Set)
+	q.set("manager_id", patch.ManagerID.Value, patch.ManagerID.Set)
+	query, args := q.build("projects", "deleted_at IS NULL", version)
+
+	return s.setProjectState(op, actor, ID, entity.ActionUpdate, query, args...)
+}
+
+// DeleteProject помечает проект удаленным. Задачи проекта сохраняются.
+func (s *Storage) DeleteProject(actor entity.Actor, ID uint) error {
+	const op = "storage.postgres.DeleteProject"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	return s.setProjectState(op, actor, ID, entity.ActionDelete, `
+        UPDATE projects 
+        SET deleted_at = NOW(), modified_at = NOW()
+        WHERE id = $1 AND deleted_at IS NULL`)
+}
+
+// ArchiveProject переводит проект в архив: задачи остаются видны,
+// новые задачи в проекте не создаются
+func (s *Storage) ArchiveProject(actor entity.Actor, ID uint) error {
+	const op = "storage.postgres.ArchiveProject"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	return s.setProjectState(op, actor, ID, entity.ActionArchive, `
+        UPDATE projects 
+        SET archived_at = COALESCE(archived_at, NOW()), modified_at = NOW()
+        WHERE id = $1 AND deleted_at IS NULL`)
+}
+
+// RestoreProject возвращает удаленный или архивный проект в работу
+func (s *Storage) RestoreProject(actor entity.Actor, ID uint) error {
+	const op = "storage.postgres.RestoreProject"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	return s.setProjectState(op, actor, ID, entity.ActionRestore, `
+        UPDATE projects 
+        SET archived_at = NULL, deleted_at = NULL, modified_at = NOW()
+        WHERE id = $1`)
+}
+
+// setProjectState выполняет запрос изменения проекта ($1 - ID) и пишет
+// изменение в журнал аудита. Если ни одна строка не изменилась, проект
+// считается ненайденным, а при обновлении существующего - измененным после
+// переданной версии. Удаление удаленного и восстановление неудаленного
+// разработчика - пустые операции без записи в журнал.
+func (s *Storage) setProjectState(op string, actor entity.Actor, ID uint, action, query string, args ...interface{}) error {
+	return s.inTx(op, func(tx *sql.Tx) error {
+		before, err := lockProject(tx, ID)
+		if err != nil {
+			return err
+		}
+
+		res, err := tx.Exec(query, append([]interface{}{ID}, args...)...)
+		if err != nil {
+			return fmt.Errorf("execute statement: %w", mapError(err))
+		}
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("rows affected: %w", err)
+		}
+		if affected == 0 {
+			if action == entity.ActionUpdate && before.DeletedAt == nil {
+				return er.ErrVersionMismatch
+			}
+			return er.ErrProjectNotFound
+		}
+
+		after, err := lockProject(tx, ID)
+		if err != nil {
+			return err
+		}
+
+		return writeAudit(tx, actor, entity.AuditProject, ID, action, before, after)
+	})
+}
+
+/////////////////////////////////ANALYTICS//////////////////////////////
+
+// estimateAggregates - агрегаты трудозатрат по задачам t, общие для всех разрезов аналитики
+const estimateAggregates = `
+		COUNT(t.id),
+		COALESCE(SUM(t.estimate_planed), 0),
+		COALESCE(SUM(t.estimate_progress), 0),
+		COALESCE(SUM(EXTRACT(EPOCH FROM (t.end_timestamp - t.start_timestamp))), 0) / 3600.0,
+		COALESCE(SUM(t.estimate_progress)::float8 / NULLIF(SUM(t.estimate_planed), 0), 0)`
+
+// GetProjectStats считает плановые и фактические часы по проектам для задач,
+// начатых в интервале [from, to)
+func (s *Storage) GetProjectStats(from, to time.Time) ([]entity.ProjectStats, error) {
+	const op = "storage.postgres.GetProjectStats"
+	defer metrics.ObserveQuery(op, time.Now())
 
 	stmt, err := s.db.Prepare(`
-    INSERT INTO projects(
-      name,
-      description,
-      created_at
-    ) VALUES ($1, $2, $3)
-    RETURNING id, created_at`)
+		SELECT p.id, p.name,` + estimateAggregates + `
+		FROM tasks t
+		JOIN projects p ON p.id = t.project_id
+		WHERE t.start_timestamp >= $1 AND t.start_timestamp < $2
+		GROUP BY p.id, p.name
+		ORDER BY p.name`)
 	if err != nil {
-		return fmt.Errorf("%s: prepare statement: %w", op, err)
+		return nil, fmt.Errorf("%s: prepare statement: %w", op, err)
 	}
 	defer stmt.Close()
 
-	err = stmt.QueryRow(
-		project.Name,
-		project.Description,
-		time.Now(),
-	).Scan(&project.ID, &project.CreatedAt)
+	rows, err := stmt.Query(from, to)
 	if err != nil {
-		return fmt.Errorf("%s: execute statement: %w", op, err)
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
 	}
-	return nil
+	defer rows.Close()
+
+	var stats []entity.ProjectStats
+	for rows.Next() {
+		var st entity.ProjectStats
+		err := rows.Scan(
+			&st.ProjectID,
+			&st.ProjectName,
+			&st.TaskCount,
+			&st.PlannedHours,
+			&st.LoggedHours,
+			&st.ActualHours,
+			&st.OverrunRatio,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
+		}
+		stats = append(stats, st)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return stats, nil
 }
 
-func (s *Storage) GetProject() ([]entity.Project, error) {
-	const op = "storage.postgres.GetProject"
+// GetDeveloperStats считает плановые и фактические часы по разработчикам для
+// задач, начатых в интервале [from, to)
+func (s *Storage) GetDeveloperStats(from, to time.Time) ([]entity.DeveloperStats, error) {
+	const op = "storage.postgres.GetDeveloperStats"
+	defer metrics.ObserveQuery(op, time.Now())
 
 	stmt, err := s.db.Prepare(`
-    SELECT id, name, description, created_at
-    FROM projects
-    ORDER BY created_at DESC`)
+		SELECT d.id, d.name, d.last_name,` + estimateAggregates + `
+		FROM tasks t
+		JOIN reports r ON r.id = t.report_id
+		JOIN developers d ON d.id = r.developer_id
+		WHERE t.start_timestamp >= $1 AND t.start_timestamp < $2
+		GROUP BY d.id, d.name, d.last_name
+		ORDER BY d.last_name, d.name`)
 	if err != nil {
 		return nil, fmt.Errorf("%s: prepare statement: %w", op, err)
 	}
 	defer stmt.Close()
 
-	rows, err := stmt.Query()
+	rows, err := stmt.Query(from, to)
 	if err != nil {
 		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
 	}
 	defer rows.Close()
 
-	var projects []entity.Project
+	var stats []entity.DeveloperStats
 	for rows.Next() {
-		var project entity.Project
+		var st entity.DeveloperStats
 		err := rows.Scan(
-			&project.ID,
-			&project.Name,
-			&project.Description,
-			&project.CreatedAt,
+			&st.DeveloperID,
+			&st.Name,
+			&st.LastName,
+			&st.TaskCount,
+			&st.PlannedHours,
+			&st.LoggedHours,
+			&st.ActualHours,
+			&st.OverrunRatio,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("%s: scan row: %w", op, err)
 		}
-		projects = append(projects, project)
+		stats = append(stats, st)
 	}
 
 	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("%s: rows error: %w", op, err)
 	}
 
-	return projects, nil
+	return stats, nil
 }
 
-func (s *Storage) GetProjectByID(ID uint) (entity.Project, error) {
-	const op = "storage.postgres.GetProjectByID"
+// GetActivityStats считает отчеты, утверждения и задачи, созданные начиная с from
+func (s *Storage) GetActivityStats(from time.Time) (entity.ActivityStats, error) {
+	const op = "storage.postgres.GetActivityStats"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	var stats entity.ActivityStats
+	err := s.db.QueryRow(`
+		SELECT
+			(SELECT COUNT(*) FROM reports WHERE created_at >= $1),
+			(SELECT COUNT(*) FROM reports WHERE approved_at >= $1),
+			(SELECT COUNT(*) FROM tasks WHERE created_at >= $1),
+			(SELECT COUNT(DISTINCT developer_id) FROM reports WHERE created_at >= $1)`,
+		from,
+	).Scan(&stats.ReportsSubmitted, &stats.ReportsApproved, &stats.TasksCreated, &stats.ActiveDevelopers)
+	if err != nil {
+		return entity.ActivityStats{}, fmt.Errorf("%s: %w", op, err)
+	}
+
+	return stats, nil
+}
+
+/////////////////////////////////ACCESS//////////////////////////////
+
+// ManagesReport сообщает, есть ли в отчете задачи по проектам менеджера
+func (s *Storage) ManagesReport(managerID uuid.UUID, reportID uint) (bool, error) {
+	const op = "storage.postgres.ManagesReport"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	return s.exists(op, `
+		SELECT EXISTS (
+			SELECT 1 FROM tasks t
+			JOIN projects p ON p.id = t.project_id
+			WHERE t.report_id = $2 AND p.manager_id = $1)`, managerID, reportID)
+}
+
+// ManagesDeveloper сообщает, есть ли у разработчика задачи по проектам менеджера
+func (s *Storage) ManagesDeveloper(managerID, developerID uuid.UUID) (bool, error) {
+	const op = "storage.postgres.ManagesDeveloper"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	return s.exists(op, `
+		SELECT EXISTS (
+			SELECT 1 FROM tasks t
+			JOIN reports r ON r.id = t.report_id
+			JOIN projects p ON p.id = t.project_id
+			WHERE r.developer_id = $2 AND p.manager_id = $1)`, managerID, developerID)
+}
+
+func (s *Storage) exists(op, query string, args ...interface{}) (bool, error) {
+	stmt, err := s.db.Prepare(query)
+	if err != nil {
+		return false, fmt.Errorf("%s: prepare statement: %w", op, err)
+	}
+	defer stmt.Close()
+
+	var ok bool
+	if err := stmt.QueryRow(args...).Scan(&ok); err != nil {
+		return false, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return ok, nil
+}
+
+/////////////////////////////////TOKENS//////////////////////////////
+
+func (s *Storage) SaveToken(actor entity.Actor, token entity.APIToken, hash string) (entity.APIToken, error) {
+	const op = "storage.postgres.SaveToken"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	err := s.inTx(op, func(tx *sql.Tx) error {
+		err := tx.QueryRow(`
+			INSERT INTO api_tokens(
+			  developer_id,
+			  name,
+			  token_hash
+			) VALUES ($1, $2, $3)
+			RETURNING id, created_at`,
+			token.DeveloperID, token.Name, hash,
+		).Scan(&token.ID, &token.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("execute statement: %w", mapError(err))
+		}
+
+		return writeAudit(tx, actor, entity.AuditToken, token.ID, entity.ActionCreate, nil, token)
+	})
+	if err != nil {
+		return entity.APIToken{}, err
+	}
+
+	return token, nil
+}
+
+// GetTokens возвращает токены разработчика, включая отозванные
+func (s *Storage) GetTokens(developerID uuid.UUID) ([]entity.APIToken, error) {
+	const op = "storage.postgres.GetTokens"
+	defer metrics.ObserveQuery(op, time.Now())
 
 	stmt, err := s.db.Prepare(`
-    SELECT id, name, description, created_at
-    FROM projects
-    WHERE id = $1`)
+    SELECT id, developer_id, name, created_at, revoked_at
+    FROM api_tokens
+    WHERE developer_id = $1
+    ORDER BY id`)
 	if err != nil {
-		return entity.Project{}, fmt.Errorf("%s: prepare statement: %w", op, err)
+		return nil, fmt.Errorf("%s: prepare statement: %w", op, err)
 	}
 	defer stmt.Close()
 
-	var project entity.Project
-	err = stmt.QueryRow(ID).Scan(
-		&project.ID,
-		&project.Name,
-		&project.Description,
-		&project.CreatedAt,
-	)
+	rows, err := stmt.Query(developerID)
 	if err != nil {
-		if err == sql.ErrNoRows {
-			return entity.Project{}, fmt.Errorf("%s: %w", op, er.ErrProjectNotFound)
+		return nil, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+	defer rows.Close()
+
+	var tokens []entity.APIToken
+	for rows.Next() {
+		var token entity.APIToken
+		err := rows.Scan(
+			&token.ID,
+			&token.DeveloperID,
+			&token.Name,
+			&token.CreatedAt,
+			&token.RevokedAt,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("%s: scan row: %w", op, err)
 		}
-		return entity.Project{}, fmt.Errorf("%s: execute statement: %w", op, err)
+		tokens = append(tokens, token)
 	}
 
-	return project, nil
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("%s: rows error: %w", op, err)
+	}
+
+	return tokens, nil
 }
 
-func (s *Storage) UpdateProject(ID uint, project entity.Project) error {
-	const op = "storage.postgres.UpdateProject"
+// GetDeveloperByToken возвращает владельца действующего токена. Отозванные
+// токены и токены удаленных разработчиков не находятся.
+func (s *Storage) GetDeveloperByToken(hash string) (entity.Developer, error) {
+	const op = "storage.postgres.GetDeveloperByToken"
+	defer metrics.ObserveQuery(op, time.Now())
 
 	stmt, err := s.db.Prepare(`
-        UPDATE projects 
-        SET name = $1, description = $2, modified_at = NOW()
-        WHERE id = $3`)
+		SELECT d.id, d.name, d.last_name, d.role, d.created_at, d.modified_at, d.deleted_at
+		FROM api_tokens t
+		JOIN developers d ON d.id = t.developer_id
+		WHERE t.token_hash = $1 AND t.revoked_at IS NULL AND d.deleted_at IS NULL`)
 	if err != nil {
-		return fmt.Errorf("%s: prepare statement: %w", op, err)
+		return entity.Developer{}, fmt.Errorf("%s: prepare statement: %w", op, err)
 	}
 	defer stmt.Close()
 
-	_, err = stmt.Exec(project.Name, project.Description, ID)
+	var developer entity.Developer
+	err = stmt.QueryRow(hash).Scan(
+		&developer.ID,
+		&developer.Name,
+		&developer.LastName,
+		&developer.Role,
+		&developer.CreatedAt,
+		&developer.ModifiedAt,
+		&developer.DeletedAt,
+	)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.Developer{}, fmt.Errorf("%s: %w", op, er.ErrTokenNotFound)
+		}
+		return entity.Developer{}, fmt.Errorf("%s: execute statement: %w", op, err)
+	}
+
+	return developer, nil
+}
+
+// RevokeToken отзывает токен разработчика. Повторный отзыв не меняет revoked_at.
+func (s *Storage) RevokeToken(actor entity.Actor, developerID uuid.UUID, ID uint) error {
+	const op = "storage.postgres.RevokeToken"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	return s.inTx(op, func(tx *sql.Tx) error {
+		before, err := lockToken(tx, developerID, ID)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE api_tokens
+			SET revoked_at = COALESCE(revoked_at, NOW())
+			WHERE id = $1`, ID)
+		if err != nil {
+			return fmt.Errorf("execute statement: %w", err)
+		}
+
+		after, err := lockToken(tx, developerID, ID)
+		if err != nil {
+			return err
+		}
+
+		return writeAudit(tx, actor, entity.AuditToken, ID, entity.ActionRevoke, before, after)
+	})
+}
+
+// lockToken читает токен разработчика в транзакции tx и блокирует строку до ее конца
+func lockToken(tx *sql.Tx, developerID uuid.UUID, ID uint) (entity.APIToken, error) {
+	var token entity.APIToken
+	err := tx.QueryRow(`
+		SELECT id, developer_id, name, created_at, revoked_at
+		FROM api_tokens
+		WHERE id = $1 AND developer_id = $2
+		FOR UPDATE`, ID, developerID).Scan(
+		&token.ID,
+		&token.DeveloperID,
+		&token.Name,
+		&token.CreatedAt,
+		&token.RevokedAt,
+	)
 	if err != nil {
-		return fmt.Errorf("%s: execute statement: %w", op, err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return entity.APIToken{}, er.ErrTokenNotFound
+		}
+		return entity.APIToken{}, fmt.Errorf("lock token: %w", err)
+	}
+	return token, nil
+}
+
+func (s *Storage) Ping(ctx context.Context) error {
+	const op = "storage.postgres.Ping"
+	defer metrics.ObserveQuery(op, time.Now())
+
+	if err := s.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("%s: %w", op, err)
 	}
 	return nil
 }
 
+// Stats - статистика пула соединений для метрик
+func (s *Storage) Stats() sql.DBStats {
+	return s.db.Stats()
+}
+
 func (s *Storage) Close() error {
 	return s.db.Close()
 }
@@ -0,0 +1,115 @@
+package storage
+
+import (
+	"context"
+	"goproject/internal/storage/postgres/entity"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// DeveloperRepository - хранилище разработчиков
+type DeveloperRepository interface {
+	SaveDeveloper(actor entity.Actor, developer entity.Developer) (uuid.UUID, error)
+	GetDeveloperByID(uid uuid.UUID) (entity.Developer, error)
+	GetDevelopers(params ListParams) ([]entity.Developer, string, error)
+	UpdateDeveloper(actor entity.Actor, uid uuid.UUID, developer entity.Developer, version time.Time) error
+	PatchDeveloper(actor entity.Actor, uid uuid.UUID, patch entity.DeveloperPatch, version time.Time) error
+	SoftDeleteDeveloper(actor entity.Actor, uid uuid.UUID) error
+	RestoreDeveloper(actor entity.Actor, uid uuid.UUID) error
+}
+
+// ProjectRepository - хранилище проектов
+type ProjectRepository interface {
+	SaveProject(actor entity.Actor, project entity.Project) (entity.Project, error)
+	GetProject(params ListParams) ([]entity.Project, string, error)
+	GetProjectByName(name string) (entity.Project, error)
+	GetProjectByID(ID uint) (entity.Project, error)
+	GetProjectsByIDs(IDs []uint) ([]entity.Project, error)
+	UpdateProject(actor entity.Actor, ID uint, project entity.Project, version time.Time) error
+	PatchProject(actor entity.Actor, ID uint, patch entity.ProjectPatch, version time.Time) error
+	DeleteProject(actor entity.Actor, ID uint) error
+	ArchiveProject(actor entity.Actor, ID uint) error
+	RestoreProject(actor entity.Actor, ID uint) error
+}
+
+// ReportRepository - хранилище отчетов
+type ReportRepository interface {
+	SaveReport(actor entity.Actor, report entity.Report) (entity.Report, error)
+	SaveReportWithTasks(actor entity.Actor, report entity.Report, tasks []entity.Task) (entity.Report, []entity.Task, error)
+	GetReport(params ListParams) ([]entity.Report, string, error)
+	GetReportById(id uint) (entity.Report, error)
+	GetReportsByDeveloperID(developerID uuid.UUID) ([]entity.Report, error)
+	ApproveReport(actor entity.Actor, ID uint) error
+}
+
+// TaskRepository - хранилище задач
+type TaskRepository interface {
+	SaveTask(actor entity.Actor, task entity.Task) (int, error)
+	SaveTasks(actor entity.Actor, tasks []entity.Task) ([]int, error)
+	GetTaskByID(ID uint) (entity.Task, error)
+	GetTasks(params ListParams) ([]entity.Task, string, error)
+	GetTasksByReportID(ID uint) ([]entity.Task, error)
+	GetTasksByDeveloperID(developerID uuid.UUID) ([]entity.Task, error)
+	GetTasksByDeveloperInRange(developerID uuid.UUID, from, to time.Time) ([]entity.Task, error)
+	UpdateTask(actor entity.Actor, ID uint, task entity.Task, version time.Time) error
+	PatchTask(actor entity.Actor, ID uint, patch entity.TaskPatch, version time.Time) error
+	DeleteTask(actor entity.Actor, ID uint) error
+}
+
+// AnalyticsRepository - агрегаты план/факт по задачам и показатели активности
+type AnalyticsRepository interface {
+	GetProjectStats(from, to time.Time) ([]entity.ProjectStats, error)
+	GetDeveloperStats(from, to time.Time) ([]entity.DeveloperStats, error)
+	GetActivityStats(from time.Time) (entity.ActivityStats, error)
+}
+
+// ExportRepository - потоковая выгрузка задач и отчетов. Записи передаются в fn по
+// одной, не собираясь в срез, поэтому объем выгрузки не ограничен памятью.
+type ExportRepository interface {
+	// ExportTasks выгружает задачи по фильтрам DeveloperID, ProjectID,
+	// ManagerID, From и To в порядке начала. Ошибка fn прерывает выгрузку и
+	// возвращается обернутой.
+	ExportTasks(ctx context.Context, params ListParams, fn func(entity.TaskExport) error) error
+	// ExportReports выгружает отчеты по тем же фильтрам, что и GetReport
+	// (From и To - по времени создания), в порядке создания
+	ExportReports(ctx context.Context, params ListParams, fn func(entity.ReportExport) error) error
+}
+
+// TokenRepository - хранилище API-токенов разработчиков. Токены ищутся по хэшу.
+type TokenRepository interface {
+	SaveToken(actor entity.Actor, token entity.APIToken, hash string) (entity.APIToken, error)
+	GetTokens(developerID uuid.UUID) ([]entity.APIToken, error)
+	GetDeveloperByToken(hash string) (entity.Developer, error)
+	RevokeToken(actor entity.Actor, developerID uuid.UUID, ID uint) error
+}
+
+// AccessRepository отвечает на вопросы политики доступа о связях менеджера
+// с отчетами и разработчиками через проекты, которыми он руководит
+type AccessRepository interface {
+	ManagesReport(managerID uuid.UUID, reportID uint) (bool, error)
+	ManagesDeveloper(managerID, developerID uuid.UUID) (bool, error)
+}
+
+// AuditRepository - журнал аудита. Записи добавляют изменяющие методы
+// остальных хранилищ в той же транзакции, что и само изменение.
+type AuditRepository interface {
+	GetAudit(params ListParams) ([]entity.AuditEntry, string, error)
+}
+
+// Repository объединяет все хранилища приложения. Реализации: postgres.Storage
+// и memory.Storage; обе возвращают ошибки из этого пакета.
+type Repository interface {
+	DeveloperRepository
+	ProjectRepository
+	ReportRepository
+	TaskRepository
+	AnalyticsRepository
+	ExportRepository
+	TokenRepository
+	AccessRepository
+	AuditRepository
+	// Ping проверяет, что хранилище доступно; используется в /readyz
+	Ping(ctx context.Context) error
+	Close() error
+}
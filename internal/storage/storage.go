@@ -1,6 +1,9 @@
-package postgres
+package storage
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	// ErrDeveloperNotFound returns when developer not found in storage
@@ -15,6 +18,12 @@ var (
 	// ErrReportNotFound returns when report not found in storage
 	ErrReportNotFound = errors.New("report not found")
 
+	// ErrProjectArchived returns when a task is written to an archived project
+	ErrProjectArchived = errors.New("project is archived")
+
+	// ErrTokenNotFound returns when API token is unknown, revoked or belongs to a deleted developer
+	ErrTokenNotFound = errors.New("token not found")
+
 	// ErrInvalidDeveloperData returns when developer data is invalid
 	ErrInvalidDeveloperData = errors.New("invalid developer data")
 
@@ -26,4 +35,36 @@ var (
 
 	// ErrInvalidReportData returns when report data is invalid
 	ErrInvalidReportData = errors.New("invalid report data")
+
+	// ErrUniqueViolation returns when data conflicts with an existing record
+	ErrUniqueViolation = errors.New("unique constraint violation")
+
+	// ErrForeignKeyViolation returns when data references a missing record
+	// or a record is still referenced by others
+	ErrForeignKeyViolation = errors.New("foreign key violation")
+
+	// ErrCheckViolation returns when data violates a check or not-null constraint
+	ErrCheckViolation = errors.New("check constraint violation")
+
+	// ErrVersionMismatch returns when a record was modified after the version
+	// the update is based on
+	ErrVersionMismatch = errors.New("version mismatch")
 )
+
+// ConstraintError - нарушение ограничения базы данных. Err - одна из ошибок
+// ErrUniqueViolation, ErrForeignKeyViolation или ErrCheckViolation.
+type ConstraintError struct {
+	Constraint string
+	Err        error
+}
+
+func (e *ConstraintError) Error() string {
+	if e.Constraint == "" {
+		return e.Err.Error()
+	}
+	return fmt.Sprintf("%s: %s", e.Err, e.Constraint)
+}
+
+func (e *ConstraintError) Unwrap() error {
+	return e.Err
+}